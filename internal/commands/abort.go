@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/imemir/gitext/pkg/journal"
+	"github.com/imemir/gitext/pkg/operation"
+	"github.com/imemir/gitext/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+func NewAbortCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "abort",
+		Short: "Abort a rebase, merge or cherry-pick stopped on conflicts",
+		Long: `Abort the rebase or merge an update or retarget stopped on, or the cherry-pick
+of a promote, and restore the state from before the command, including
+branches it moved or created (see gitext undo) and changes it stashed with
+--autostash. Also aborts a rebase, merge or cherry-pick started with git.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := opts.Context()
+			output, g := ctx.Output, ctx.Git
+
+			if _, err := ctx.RequireConfig(); err != nil {
+				return err
+			}
+
+			path, err := operationPath(g)
+			if err != nil {
+				return err
+			}
+			state, err := operation.Load(path)
+			if err != nil {
+				return err
+			}
+			inProgress, err := g.GetInProgressOperation()
+			if err != nil {
+				return fmt.Errorf("failed to detect in-progress operation: %w", err)
+			}
+
+			if state == nil && inProgress == "" {
+				return ui.NewError("no operation in progress", "run: gitext status")
+			}
+
+			if inProgress != "" {
+				output.Doing("Aborting %s", inProgress)
+				if _, err := g.AbortOperation(inProgress); err != nil {
+					return fmt.Errorf("failed to abort %s: %w", inProgress, err)
+				}
+				output.Did("Aborted %s", inProgress)
+			}
+
+			if state != nil {
+				// Restore branches the command moved before it stopped
+				if state.JournalID != "" {
+					if err := abortJournalEntry(ctx, state, commandLine(cmd, args)); err != nil {
+						return err
+					}
+				}
+				if !ctx.DryRun {
+					if err := operation.Clear(path); err != nil {
+						return err
+					}
+				}
+				output.Success("Restored the state before %s", state.Command)
+				output.Set("operation", state)
+				if err := restoreAutostash(ctx, state.Stash); err != nil {
+					return err
+				}
+			}
+
+			output.Next("run: gitext status")
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// abortJournalEntry undoes the journal entry recorded before the paused
+// command, unless it was already undone
+func abortJournalEntry(ctx *Context, state *operation.State, command string) error {
+	j, err := openJournal(ctx.Git)
+	if err != nil {
+		return err
+	}
+	entry, err := j.Get(state.JournalID)
+	if errors.Is(err, journal.ErrNotFound) {
+		ctx.Output.Warning("Journal entry %s not found, branches other than %s were not restored", state.JournalID, state.Branch)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if entry.Undone() {
+		return nil
+	}
+	return undoEntry(ctx, j, entry, command)
+}
@@ -10,7 +10,8 @@ func NewAICmd(opts *Options) *cobra.Command {
 		Use:   "ai",
 		Short: "AI-related commands for commit message generation",
 		Long: `Commands for configuring and managing AI providers for automatic
-commit message generation. Supports OpenAI and OpenRouter.`,
+commit message generation. Supports OpenAI, OpenRouter, Anthropic, a local
+Ollama server and OpenAI-compatible endpoints.`,
 	}
 
 	// Add subcommands
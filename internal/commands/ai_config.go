@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 
+	"github.com/imemir/gitext/pkg/ai"
 	"github.com/imemir/gitext/pkg/aiconfig"
 	"github.com/imemir/gitext/pkg/ui"
 	"github.com/spf13/cobra"
@@ -17,8 +18,8 @@ func NewAIConfigCmd(opts *Options) *cobra.Command {
 		Long: `Display current AI configuration or test the connection.
 Use 'gitext ai setup' to reconfigure.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output := ui.NewOutput(opts.Verbose)
-			aiOutput := ui.NewAIOutput(opts.Verbose)
+			output := opts.Output()
+			aiOutput := ui.NewAIOutput(output)
 
 			manager, err := aiconfig.NewManager()
 			if err != nil {
@@ -38,28 +39,36 @@ Use 'gitext ai setup' to reconfigure.`,
 
 			// Display configuration
 			output.Info("Current AI Configuration:")
-			fmt.Println()
-			fmt.Printf("  Provider: %s\n", cfg.Provider)
+			output.Print("")
+			output.Print("  Provider: %s", cfg.Provider)
 
-			if cfg.Provider == "openai" {
-				fmt.Printf("  API Key: %s\n", aiconfig.MaskAPIKey(cfg.OpenAI.APIKey))
-				fmt.Printf("  Model: %s\n", cfg.OpenAI.Model)
-			} else {
-				fmt.Printf("  API Key: %s\n", aiconfig.MaskAPIKey(cfg.OpenRouter.APIKey))
-				fmt.Printf("  Model: %s\n", cfg.OpenRouter.Model)
-				if cfg.OpenRouter.UseFreeModel {
-					fmt.Printf("  Using free model: Yes\n")
-				} else {
-					fmt.Printf("  Using free model: No\n")
+			settings := cfg.Settings()
+			if info, ok := ai.LookupProvider(cfg.Provider); ok {
+				for _, field := range info.Fields {
+					value := settings[field.Name]
+					switch {
+					case value == "":
+						continue
+					case field.Secret:
+						value = aiconfig.MaskAPIKey(value)
+					case field.Bool && settings.Bool(field.Name):
+						value = "Yes"
+					case field.Bool:
+						value = "No"
+					}
+					output.Print("  %s: %s", field.Label, value)
 				}
 			}
+			output.Set("model", settings[ai.ModelField])
 
 			configPath, err := aiconfig.GetConfigPath()
 			if err != nil {
 				return err
 			}
-			fmt.Printf("  Config file: %s\n", configPath)
-			fmt.Println()
+			output.Print("  Config file: %s", configPath)
+			output.Set("provider", cfg.Provider)
+			output.Set("configFile", configPath)
+			output.Print("")
 
 			// Test connection if requested
 			if test {
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/imemir/gitext/pkg/ai"
 	"github.com/imemir/gitext/pkg/aiconfig"
@@ -13,12 +15,18 @@ func NewAISetupCmd(opts *Options) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "setup",
 		Short: "Setup AI provider for commit message generation",
-		Long: `Interactive setup for configuring AI provider (OpenAI or OpenRouter) 
-for automatic commit message generation. This will create a configuration file
-at ~/.gitext/config.yaml with your API keys and model preferences.`,
+		Long: `Interactive setup for configuring AI provider (OpenAI, OpenRouter, Anthropic,
+a local Ollama server or any OpenAI-compatible endpoint) for automatic commit
+message generation. This will create a configuration file at
+~/.gitext/config.yaml with your API keys and model preferences.
+Running it again changes the selected provider and its settings, keeping
+those of the other providers.
+
+Ollama and self-hosted OpenAI-compatible servers keep your code on machines
+you control.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output := ui.NewOutput(opts.Verbose)
-			aiOutput := ui.NewAIOutput(opts.Verbose)
+			output := opts.Output()
+			aiOutput := ui.NewAIOutput(output)
 
 			// Check if config already exists
 			manager, err := aiconfig.NewManager()
@@ -28,7 +36,7 @@ at ~/.gitext/config.yaml with your API keys and model preferences.`,
 
 			if manager.Exists() {
 				output.Warning("AI configuration already exists")
-				confirmed, err := ui.PromptConfirm("Do you want to overwrite it?", false)
+				confirmed, err := ui.PromptConfirm("Do you want to change it?", false)
 				if err != nil {
 					return err
 				}
@@ -38,119 +46,45 @@ at ~/.gitext/config.yaml with your API keys and model preferences.`,
 				}
 			}
 
-			cfg := aiconfig.DefaultConfig()
+			// Only the selected provider and its settings change
+			cfg, err := manager.LoadOrDefault()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
 
 			// Select provider
 			output.Info("Select AI Provider:")
-			providerOptions := []struct {
+			providers := ai.Providers()
+			providerOptions := make([]struct {
 				Label       string
 				Description string
-			}{
-				{"OpenAI", "Official OpenAI API (requires API key)"},
-				{"OpenRouter", "OpenRouter API (supports free models)"},
+			}, len(providers))
+			for i, info := range providers {
+				providerOptions[i].Label = info.Label
+				providerOptions[i].Description = info.Description
 			}
 
 			providerIdx, err := ui.PromptSelectWithDescriptions("", providerOptions)
 			if err != nil {
 				return fmt.Errorf("failed to select provider: %w", err)
 			}
-
-			if providerIdx == 0 {
-				cfg.Provider = "openai"
-			} else {
-				cfg.Provider = "openrouter"
-			}
-
-			// Get API key
-			apiKeyPrompt := fmt.Sprintf("Enter your %s API key: ", cfg.Provider)
-			apiKey, err := ui.PromptPassword(apiKeyPrompt)
-			if err != nil {
-				return fmt.Errorf("failed to read API key: %w", err)
-			}
-			if apiKey == "" {
-				return fmt.Errorf("API key cannot be empty")
-			}
-
-			// Configure provider-specific settings
-			if cfg.Provider == "openai" {
-				cfg.OpenAI.APIKey = apiKey
-
-				// Select model
-				output.Info("Select OpenAI model:")
-				modelOptions := []string{
-					"gpt-4o (default - recommended)",
-					"gpt-4o-mini (faster, cheaper)",
-					"gpt-4-turbo",
-					"gpt-3.5-turbo (cheapest)",
-					"Custom model",
-				}
-
-				modelIdx, err := ui.PromptSelect("", modelOptions)
-				if err != nil {
-					return fmt.Errorf("failed to select model: %w", err)
-				}
-
-				switch modelIdx {
-				case 0:
-					cfg.OpenAI.Model = "gpt-4o"
-				case 1:
-					cfg.OpenAI.Model = "gpt-4o-mini"
-				case 2:
-					cfg.OpenAI.Model = "gpt-4-turbo"
-				case 3:
-					cfg.OpenAI.Model = "gpt-3.5-turbo"
-				case 4:
-					customModel, err := ui.PromptInput("Enter custom model name: ")
-					if err != nil {
-						return fmt.Errorf("failed to read model name: %w", err)
-					}
-					if customModel == "" {
-						return fmt.Errorf("model name cannot be empty")
-					}
-					cfg.OpenAI.Model = customModel
+			info := providers[providerIdx]
+			cfg.Provider = info.Name
+
+			// Ask for the provider's settings
+			settings := cfg.Settings()
+			for _, field := range info.Fields {
+				if field.Advanced {
+					continue
 				}
-			} else {
-				cfg.OpenRouter.APIKey = apiKey
-
-				// Select model type
-				output.Info("Select OpenRouter model option:")
-				modelTypeOptions := []struct {
-					Label       string
-					Description string
-				}{
-					{"Use free model", "Select from free models (recommended)"},
-					{"Use custom model", "Enter your own model name"},
-				}
-
-				modelTypeIdx, err := ui.PromptSelectWithDescriptions("", modelTypeOptions)
+				value, err := promptField(output, info, field, settings)
 				if err != nil {
-					return fmt.Errorf("failed to select model type: %w", err)
-				}
-
-				if modelTypeIdx == 0 {
-					cfg.OpenRouter.UseFreeModel = true
-					output.Info("Select free model:")
-					freeModelOptions := make([]string, len(ai.FreeModels))
-					for i, model := range ai.FreeModels {
-						freeModelOptions[i] = fmt.Sprintf("%s - %s", model.Name, model.Description)
-					}
-
-					freeModelIdx, err := ui.PromptSelect("", freeModelOptions)
-					if err != nil {
-						return fmt.Errorf("failed to select free model: %w", err)
-					}
-					cfg.OpenRouter.Model = ai.FreeModels[freeModelIdx].ID
-				} else {
-					cfg.OpenRouter.UseFreeModel = false
-					customModel, err := ui.PromptInput("Enter custom model name (e.g., anthropic/claude-3-opus): ")
-					if err != nil {
-						return fmt.Errorf("failed to read model name: %w", err)
-					}
-					if customModel == "" {
-						return fmt.Errorf("model name cannot be empty")
-					}
-					cfg.OpenRouter.Model = customModel
+					return err
 				}
+				settings[field.Name] = value
+			}
+			if err := cfg.Validate(); err != nil {
+				return fmt.Errorf("invalid configuration: %w", err)
 			}
 
 			// Test connection
@@ -188,29 +122,92 @@ at ~/.gitext/config.yaml with your API keys and model preferences.`,
 	return cmd
 }
 
-// testAIConnection tests the AI connection with a simple prompt
-func testAIConnection(cfg *aiconfig.Config, aiOutput *ui.AIOutput) error {
-	aiOutput.TestingConnection(cfg.Provider)
+// promptField asks for one setting of a provider: a yes/no question, a
+// choice from the provider's models, a hidden secret or text. The current
+// value, if any, is the default.
+func promptField(output *ui.Output, info *ai.ProviderInfo, field aiconfig.Field, settings aiconfig.Settings) (string, error) {
+	prompt := field.Prompt
+	if prompt == "" {
+		prompt = "Enter " + strings.ToLower(field.Label)
+	}
+	defaultValue := field.Default
+	if current := settings[field.Name]; current != "" {
+		defaultValue = current
+	}
 
-	service, err := ai.NewService(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to create AI service: %w", err)
+	if field.Bool {
+		confirmed, err := ui.PromptConfirm(prompt, defaultValue == "true")
+		return strconv.FormatBool(confirmed), err
 	}
 
-	// Test with a simple diff
-	testDiff := `diff --git a/test.go b/test.go
-index 1234567..abcdefg 100644
---- a/test.go
-+++ b/test.go
-@@ -1,3 +1,5 @@
- package main
+	if field.Name == ai.ModelField && info.Models != nil {
+		models, err := info.Models(settings)
+		if err != nil {
+			output.Warning("Could not list models: %v", err)
+		}
+		if len(models) > 0 {
+			output.Info("Select %s model:", info.Label)
+			options := make([]string, len(models)+1)
+			for i, model := range models {
+				options[i] = modelLabel(model)
+			}
+			options[len(models)] = "Custom model"
 
-+func newFunction() {
-+}
-`
+			modelIdx, err := ui.PromptSelect("", options)
+			if err != nil {
+				return "", fmt.Errorf("failed to select model: %w", err)
+			}
+			if modelIdx < len(models) {
+				return models[modelIdx].ID, nil
+			}
+			prompt = "Enter custom model name"
+		}
+	}
 
-	_, err = service.GenerateCommitMessage(testDiff)
+	if field.Secret && defaultValue != "" {
+		prompt += " (leave empty to keep the current one)"
+	} else if defaultValue != "" {
+		prompt += fmt.Sprintf(" (default: %s)", defaultValue)
+	}
+	prompt += ": "
+
+	var value string
+	var err error
+	if field.Secret {
+		value, err = ui.PromptPassword(prompt)
+	} else {
+		value, err = ui.PromptInput(prompt)
+	}
 	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", strings.ToLower(field.Label), err)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = defaultValue
+	}
+	if value == "" && field.Required {
+		return "", fmt.Errorf("%s cannot be empty", field.Label)
+	}
+	return value, nil
+}
+
+// modelLabel describes a model in the setup choices
+func modelLabel(model ai.Model) string {
+	label := model.ID
+	if model.Name != "" {
+		label = model.Name
+	}
+	if model.Description != "" {
+		label += " - " + model.Description
+	}
+	return label
+}
+
+// testAIConnection tests the AI connection with the provider's test
+func testAIConnection(cfg *aiconfig.Config, aiOutput *ui.AIOutput) error {
+	aiOutput.TestingConnection(cfg.Provider)
+
+	if err := ai.TestConnection(cfg); err != nil {
 		return err
 	}
 
@@ -0,0 +1,20 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCICmd creates the 'ci' command group
+func NewCICmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Run the CI checks configured for an environment",
+		Long: `Commands for running the CI steps configured per environment in .gitext
+outside of 'gitext prepare pr'.`,
+	}
+
+	// Add subcommands
+	cmd.AddCommand(NewCIRunCmd(opts))
+
+	return cmd
+}
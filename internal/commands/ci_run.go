@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/imemir/gitext/pkg/ci"
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+func NewCIRunCmd(opts *Options) *cobra.Command {
+	var target string
+	var noCache bool
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the CI steps of an environment",
+		Long: `Run the CI steps configured for the target environment (e.g., stage or production),
+the same checks 'gitext prepare pr --to <environment>' runs before generating PR text.
+Steps that already passed for the current tree are skipped unless --no-cache is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := opts.Context()
+			cfg, err := ctx.RequireConfig()
+			if err != nil {
+				return err
+			}
+
+			env, err := resolveEnvironment(cfg, target, "--target")
+			if err != nil {
+				return err
+			}
+
+			return runCIChecks(ctx, env, noCache)
+		},
+	}
+
+	cmd.Flags().StringVar(&target, "target", "", "Target environment whose CI steps to run (e.g., stage or production)")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Rerun steps that already passed for the current tree")
+
+	return cmd
+}
+
+// runCIChecks runs the CI steps configured for an environment and prints a summary.
+// Unless noCache is set, steps that already passed for the HEAD tree are skipped.
+func runCIChecks(ctx *Context, env *config.Environment, noCache bool) error {
+	output := ctx.Output
+	if len(env.CI) == 0 {
+		output.Info("No CI commands configured for %s", env.Name)
+		return nil
+	}
+
+	if ctx.DryRun {
+		for _, step := range env.CI {
+			ctx.Simulate("run CI step %s: %s", step.DisplayName(), step.Command)
+		}
+		return nil
+	}
+
+	runner := ci.NewRunner(ctx.RepoRoot)
+	runner.Stdout = output.Log()
+	if !noCache {
+		if err := useCICache(ctx.Git, runner); err != nil {
+			output.Verbose("CI cache disabled: %v", err)
+		}
+	}
+
+	output.Doing("Running CI checks for %s", env.Name)
+	results, runErr := runner.Run(env.CI)
+
+	output.Print("\n%s\n", ci.Summary(results))
+	output.Set("ci", results)
+
+	var failed []string
+	for _, result := range results {
+		if result.Failed() {
+			failed = append(failed, result.Step.DisplayName())
+		}
+	}
+
+	if runErr != nil {
+		output.Error("CI check failed: %s", strings.Join(failed, ", "))
+		return fmt.Errorf("CI check failed: %w", runErr)
+	}
+	if len(failed) > 0 {
+		output.Warning("Ignored failing CI steps: %s", strings.Join(failed, ", "))
+		return nil
+	}
+	output.Did("All CI checks passed")
+	return nil
+}
+
+// useCICache enables the CI cache for the HEAD tree. The tree hash only
+// describes committed content, so the cache is not used on a dirty tree.
+func useCICache(g *git.Git, runner *ci.Runner) error {
+	isClean, err := g.IsWorkingTreeClean()
+	if err != nil {
+		return err
+	}
+	if !isClean {
+		return fmt.Errorf("working tree has uncommitted changes")
+	}
+
+	tree, err := g.GetTreeHash()
+	if err != nil {
+		return err
+	}
+	gitextDir, err := g.GetGitextDir()
+	if err != nil {
+		return err
+	}
+
+	runner.Cache = ci.NewCache(filepath.Join(gitextDir, "ci-cache"))
+	runner.Tree = tree
+	return nil
+}
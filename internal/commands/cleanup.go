@@ -3,9 +3,8 @@ package commands
 import (
 	"fmt"
 
-	"github.com/imemir/gitext/pkg/config"
 	"github.com/imemir/gitext/pkg/git"
-	"github.com/imemir/gitext/pkg/ui"
+	"github.com/imemir/gitext/pkg/journal"
 	"github.com/spf13/cobra"
 )
 
@@ -15,53 +14,65 @@ func NewCleanupCmd(opts *Options) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "cleanup",
 		Short: "Clean up merged local branches",
-		Long: `List and optionally delete local branches that have been merged.
-By default, shows what would be deleted. Use --hard to actually delete branches.`,
+		Long: `List and optionally delete local branches that have been merged into any environment.
+By default, shows what would be deleted. Use --hard to actually delete branches.
+Worktrees of merged branches are removed with them, unless they have
+uncommitted changes.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output := ui.NewOutput(opts.Verbose)
-			g := git.NewGit(opts.DryRun, opts.Verbose)
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
 
-			if err := g.ValidateGitRepo(); err != nil {
-				return ui.NewError("not in a git repository", "run this command from within a git repository")
-			}
-
-			cfg, err := config.Load()
+			cfg, err := ctx.RequireConfig()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return err
 			}
 
 			// Get current branch
-			currentBranch, err := g.GetCurrentBranch()
+			currentBranch, err := repo.GetCurrentBranch()
 			if err != nil {
 				return fmt.Errorf("failed to get current branch: %w", err)
 			}
 
-			// Get merged branches from both stage and production
+			// Get branches merged into any environment
 			var allMergedBranches []string
 			mergedMap := make(map[string]bool)
 
-			// Check merged into stage
-			stageMerged, err := g.GetMergedBranches(cfg.Branch.Stage)
-			if err == nil {
-				for _, branch := range stageMerged {
-					if !mergedMap[branch] && branch != currentBranch {
+			for _, env := range cfg.Environments {
+				merged, err := repo.GetMergedBranches(env.Branch)
+				if err != nil {
+					continue
+				}
+				for _, branch := range merged {
+					// Never clean up environment branches
+					if !mergedMap[branch] && branch != currentBranch && !cfg.IsEnvironmentBranch(branch) {
 						mergedMap[branch] = true
 						allMergedBranches = append(allMergedBranches, branch)
 					}
 				}
 			}
 
-			// Check merged into production
-			prodMerged, err := g.GetMergedBranches(cfg.Branch.Production)
-			if err == nil {
-				for _, branch := range prodMerged {
-					if !mergedMap[branch] && branch != currentBranch {
-						mergedMap[branch] = true
-						allMergedBranches = append(allMergedBranches, branch)
+			// Merged branches checked out in a linked worktree go with it;
+			// the main worktree and the one we are in are never removed
+			worktrees, err := repo.ListWorktrees()
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+			mergedWorktrees := make(map[string]*git.Worktree)
+			var branches, worktreePaths []string
+			for _, branch := range allMergedBranches {
+				if wt := git.WorktreeForBranch(worktrees, branch); wt != nil {
+					if wt.Main || samePath(wt.Path, ctx.RepoRoot) {
+						continue
 					}
+					mergedWorktrees[branch] = wt
+					worktreePaths = append(worktreePaths, wt.Path)
 				}
+				branches = append(branches, branch)
 			}
+			allMergedBranches = branches
 
+			output.Set("branches", append([]string{}, allMergedBranches...))
+			output.Set("worktrees", append([]string{}, worktreePaths...))
 			if len(allMergedBranches) == 0 {
 				output.Info("No merged branches to clean up")
 				return nil
@@ -69,7 +80,11 @@ By default, shows what would be deleted. Use --hard to actually delete branches.
 
 			output.Info("Found %d merged branch(es):", len(allMergedBranches))
 			for _, branch := range allMergedBranches {
-				output.Print("  - %s", branch)
+				if wt := mergedWorktrees[branch]; wt != nil {
+					output.Print("  - %s (worktree %s)", branch, wt.Path)
+				} else {
+					output.Print("  - %s", branch)
+				}
 			}
 
 			if !hard {
@@ -78,16 +93,46 @@ By default, shows what would be deleted. Use --hard to actually delete branches.
 				return nil
 			}
 
+			// Record the tips before deleting anything, so even force-deleted
+			// branches can be restored with gitext undo if cleanup stops halfway
+			tips := make(map[string]string)
+			for _, branch := range allMergedBranches {
+				tip, err := g.ResolveRef("refs/heads/" + branch)
+				if err != nil {
+					output.Error("Failed to resolve %s: %v", branch, err)
+					continue
+				}
+				tips[branch] = tip
+			}
+			var j *journal.Journal
+			var entry *journal.Entry
+			if !ctx.DryRun {
+				if j, err = openJournal(g); err == nil {
+					if entry, err = snapshot(g, commandLine(cmd, args)); err == nil {
+						entry.Deleted = tips
+						err = saveJournal(ctx, entry)
+					}
+				}
+				if err != nil {
+					return fmt.Errorf("failed to record journal entry: %w", err)
+				}
+			}
+
 			// Delete branches
 			output.Doing("Deleting merged branches")
-			deleted := 0
+			deleted := make(map[string]string)
 			for _, branch := range allMergedBranches {
-				// Skip protected branches
-				if branch == cfg.Branch.Stage || branch == cfg.Branch.Production {
-					output.Warning("Skipping protected branch: %s", branch)
+				tip, ok := tips[branch]
+				if !ok {
 					continue
 				}
-
+				if wt := mergedWorktrees[branch]; wt != nil {
+					if err := removeWorktree(g, wt, false); err != nil {
+						output.Warning("Kept %s: failed to remove its worktree %s (uncommitted changes?): %v", branch, wt.Path, err)
+						continue
+					}
+					output.Verbose("Removed worktree %s", wt.Path)
+				}
 				if _, err := g.RunWithTimeout("branch", "-d", branch); err != nil {
 					output.Warning("Failed to delete %s: %v", branch, err)
 					// Try force delete if regular delete fails (for unmerged branches)
@@ -95,15 +140,30 @@ By default, shows what would be deleted. Use --hard to actually delete branches.
 						output.Error("Failed to force delete %s: %v", branch, err)
 					} else {
 						output.Verbose("Force deleted %s", branch)
-						deleted++
+						deleted[branch] = tip
 					}
 				} else {
 					output.Verbose("Deleted %s", branch)
-					deleted++
+					deleted[branch] = tip
+				}
+			}
+
+			// Trim the entry to the branches actually deleted
+			if entry != nil {
+				if len(deleted) == 0 {
+					err = j.Remove(entry.ID)
+				} else {
+					entry.Deleted = deleted
+					err = j.Save(entry)
+				}
+				if err != nil {
+					output.Warning("Failed to update journal entry %s: %v", entry.ID, err)
+				} else if len(deleted) > 0 {
+					output.Info("Restore deleted branches with: gitext undo %s", entry.ID)
 				}
 			}
 
-			output.Did("Deleted %d branch(es)", deleted)
+			output.Did("Deleted %d branch(es)", len(deleted))
 			output.Next("run: gitext status")
 
 			return nil
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/ui"
+)
+
+// newFakeContext returns options whose context answers repository queries
+// from repo, with the default environments and JSON output written to doc.
+// It runs offline and in dry-run mode, and git finds no repository, so a
+// query the fake does not answer fails instead of reading a real one.
+func newFakeContext(t *testing.T, repo *git.FakeRepository, doc io.Writer) *Options {
+	t.Setenv("GIT_DIR", filepath.Join(t.TempDir(), "no-repository"))
+
+	cfg := &config.Config{
+		Environments: []config.Environment{
+			{Name: config.DefaultStageEnvironment, Branch: config.DefaultStageBranch},
+			{Name: config.DefaultProductionEnvironment, Branch: config.DefaultProductionBranch},
+		},
+	}
+	cfg.Remote.Name = config.DefaultRemoteName
+
+	opts := &Options{DryRun: true, Offline: true}
+	opts.ctx = &Context{
+		Options:  opts,
+		Output:   ui.NewJSONOutput(false, doc),
+		Git:      git.NewGit(true, false),
+		Repo:     repo,
+		RepoRoot: "/repo",
+		config:   cfg,
+	}
+	return opts
+}
+
+func TestCleanupListsMergedBranches(t *testing.T) {
+	repo := &git.FakeRepository{
+		Branch: "feature/KWS-1-login",
+		Merged: map[string][]string{
+			"stage":      {"feature/KWS-1-login", "feature/KWS-2-done", "production"},
+			"production": {"feature/KWS-2-done", "hotfix/KWS-3-fix", "stage"},
+		},
+	}
+	var doc bytes.Buffer
+	opts := newFakeContext(t, repo, &doc)
+
+	cmd := NewCleanupCmd(opts)
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	if err := opts.Context().Finish(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct {
+		Result struct {
+			Branches []string `json:"branches"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(doc.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON document: %v\n%s", err, doc.String())
+	}
+	// The current branch and environment branches are never cleaned up
+	want := []string{"feature/KWS-2-done", "hotfix/KWS-3-fix"}
+	if !reflect.DeepEqual(result.Result.Branches, want) {
+		t.Errorf("expected %v, got %v", want, result.Result.Branches)
+	}
+}
@@ -12,9 +12,18 @@ func AddCommands(rootCmd *cobra.Command, opts *Options) {
 	rootCmd.AddCommand(NewStartCmd(opts))
 	rootCmd.AddCommand(NewUpdateCmd(opts))
 	rootCmd.AddCommand(NewRetargetCmd(opts))
+	rootCmd.AddCommand(NewPushCmd(opts))
 	rootCmd.AddCommand(NewPrepareCmd(opts))
+	rootCmd.AddCommand(NewFinishCmd(opts))
+	rootCmd.AddCommand(NewPromoteCmd(opts))
+	rootCmd.AddCommand(NewCICmd(opts))
 	rootCmd.AddCommand(NewCleanupCmd(opts))
+	rootCmd.AddCommand(NewWorktreeCmd(opts))
+	rootCmd.AddCommand(NewContinueCmd(opts))
+	rootCmd.AddCommand(NewAbortCmd(opts))
+	rootCmd.AddCommand(NewUndoCmd(opts))
 	rootCmd.AddCommand(NewCommitCmd(opts))
+	rootCmd.AddCommand(NewLintCommitsCmd(opts))
 	rootCmd.AddCommand(NewAICmd(opts))
 	rootCmd.AddCommand(NewSelfUpdateCmd(opts))
 	rootCmd.AddCommand(NewCompletionCmd())
@@ -1,10 +1,14 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/imemir/gitext/pkg/ai"
 	"github.com/imemir/gitext/pkg/aiconfig"
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/conventional"
 	"github.com/imemir/gitext/pkg/git"
 	"github.com/imemir/gitext/pkg/ui"
 	"github.com/spf13/cobra"
@@ -17,21 +21,30 @@ func NewCommitCmd(opts *Options) *cobra.Command {
 		Use:   "commit",
 		Short: "Generate and create a commit with AI",
 		Long: `Generate a commit message using AI based on staged changes and create the commit.
-The message follows Conventional Commits specification.
-
-If --message is provided, it will be used instead of generating one.`,
+The message follows Conventional Commits specification: a type(scope): subject
+header, a body explaining the change, a BREAKING CHANGE footer when the change
+breaks existing users, and a Refs footer with the ticket of the current branch.
+Generated messages are checked with the commits rules in .gitext (see gitext
+lint-commits) and generated again when they break them.
+
+Lockfiles, vendored and generated code and binaries are not sent to the AI
+(see commits.diff in .gitext). Diffs over the token budget are condensed to
+file stats, or summarized in chunks before the message is written.
+
+If --message is provided, it will be used instead of generating one. It must
+pass the same rules.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output := ui.NewOutput(opts.Verbose)
-			aiOutput := ui.NewAIOutput(opts.Verbose)
-			g := git.NewGit(opts.DryRun, opts.Verbose)
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
+			aiOutput := ui.NewAIOutput(output)
 
-			// Validate git repo
-			if err := g.ValidateGitRepo(); err != nil {
-				return ui.NewError("not in a git repository", "run this command from within a git repository")
+			cfg, err := ctx.RequireConfig()
+			if err != nil {
+				return err
 			}
 
 			// Check for staged changes
-			hasStaged, err := g.HasStagedChanges()
+			hasStaged, err := repo.HasStagedChanges()
 			if err != nil {
 				return fmt.Errorf("failed to check staged changes: %w", err)
 			}
@@ -44,6 +57,11 @@ If --message is provided, it will be used instead of generating one.`,
 			if message != "" {
 				commitMessage = message
 				output.Info("Using provided commit message")
+				output.Set("commitMessage", commitMessage)
+				if problems := conventional.Lint(conventional.Clean(commitMessage), cfg.CommitRules()); len(problems) > 0 {
+					reportProblems(output, problems)
+					return ui.NewError("commit message does not follow Conventional Commits", `fix it, e.g.: gitext commit -m "feat(scope): add something"`)
+				}
 			} else {
 				// Load AI configuration
 				manager, err := aiconfig.NewManager()
@@ -58,20 +76,20 @@ If --message is provided, it will be used instead of generating one.`,
 					)
 				}
 
-				cfg, err := manager.Load()
+				aiCfg, err := manager.Load()
 				if err != nil {
 					return fmt.Errorf("failed to load AI configuration: %w", err)
 				}
 
 				// Create AI service
-				service, err := ai.NewService(cfg)
+				service, err := ai.NewService(aiCfg)
 				if err != nil {
 					return fmt.Errorf("failed to create AI service: %w", err)
 				}
 
 				// Get staged diff
 				output.Doing("Getting staged changes")
-				diff, err := g.GetStagedDiff()
+				diff, err := repo.GetStagedDiff()
 				if err != nil {
 					return fmt.Errorf("failed to get staged diff: %w", err)
 				}
@@ -80,23 +98,50 @@ If --message is provided, it will be used instead of generating one.`,
 					return ui.NewError("no changes in diff", "ensure you have staged changes")
 				}
 
+				// Leave out lockfiles and generated code, and fit the rest
+				// into the token budget
+				prepared, err := service.PrepareDiff(diff, ai.DiffOptions{
+					Exclude:      cfg.Commits.Diff.Exclude,
+					MaxHunkLines: cfg.Commits.Diff.MaxHunkLines,
+					TokenBudget:  cfg.Commits.Diff.TokenBudget,
+				})
+				if err != nil {
+					return err
+				}
+				reportPreparedDiff(output, prepared)
+				if len(prepared.Files) == 0 {
+					return ui.NewError("only excluded files are staged", "write the message yourself: gitext commit -m \"chore: update dependencies\"")
+				}
+
 				// Generate commit message
 				aiOutput.GeneratingCommitMessage()
-				commitMessage, err = service.GenerateCommitMessage(diff)
+				generated, err := service.GenerateCommitMessage(ai.CommitRequest{
+					Diff:   prepared.Text,
+					Rules:  cfg.CommitRules(),
+					Ticket: branchTicket(cfg, repo),
+				})
+				var invalid *ai.InvalidMessageError
+				if errors.As(err, &invalid) {
+					output.Print("\n  %s\n", strings.ReplaceAll(invalid.Message.String(), "\n", "\n  "))
+					reportProblems(output, invalid.Problems)
+					return ui.NewError("AI could not generate a commit message following the rules", `write one yourself: gitext commit -m "feat(scope): add something"`)
+				}
 				if err != nil {
 					return fmt.Errorf("failed to generate commit message: %w", err)
 				}
+				commitMessage = generated.String()
 
 				aiOutput.CommitMessageGenerated(commitMessage)
+				output.Set("commit", generated)
 			}
 
 			// Show commit message
-			if opts.Verbose {
+			if ctx.Verbose {
 				output.Verbose("Commit message: %s", commitMessage)
 			}
 
 			// Confirm commit
-			if !opts.DryRun {
+			if !ctx.DryRun {
 				confirmed, err := ui.PromptConfirm("Create commit with this message?", true)
 				if err != nil {
 					return err
@@ -110,10 +155,13 @@ If --message is provided, it will be used instead of generating one.`,
 			// Create commit
 			output.Doing("Creating commit")
 			if _, err := g.RunWithTimeout("commit", "-m", commitMessage); err != nil {
-				return fmt.Errorf("failed to create commit: %w", err)
+				return gitFailure("failed to create commit", err, "", nil)
 			}
 
-			output.Success("Commit created successfully")
+			// The simulated commit is in the dry-run plan; nothing was created
+			if !ctx.DryRun {
+				output.Success("Commit created successfully")
+			}
 			return nil
 		},
 	}
@@ -122,3 +170,33 @@ If --message is provided, it will be used instead of generating one.`,
 
 	return cmd
 }
+
+// branchTicket returns the ticket in the current branch name, if any
+func branchTicket(cfg *config.Config, repo git.Repository) string {
+	branch, err := repo.GetCurrentBranch()
+	if err != nil {
+		return ""
+	}
+	return extractTicketFromBranch(cfg, branch)
+}
+
+// reportPreparedDiff tells what of the staged diff the AI sees
+func reportPreparedDiff(output *ui.Output, prepared *ai.PreparedDiff) {
+	if len(prepared.Excluded) > 0 {
+		output.Info("Not sent to AI: %s", strings.Join(prepared.Excluded, ", "))
+	}
+	if len(prepared.Truncated) > 0 {
+		output.Verbose("Long hunks cut in: %s", strings.Join(prepared.Truncated, ", "))
+	}
+	switch prepared.Mode {
+	case ai.DiffCondensed:
+		output.Info("Diff exceeds the token budget (commits.diff.tokenBudget); sending file stats and changed sections only")
+	case ai.DiffSummarized:
+		output.Info("Diff exceeds the token budget (commits.diff.tokenBudget); summarized %d group(s) of files first", len(prepared.Chunks))
+	}
+	output.Set("diff", map[string]interface{}{
+		"mode":      prepared.Mode,
+		"excluded":  append([]string{}, prepared.Excluded...),
+		"truncated": append([]string{}, prepared.Truncated...),
+	})
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/git"
+)
+
+func TestCommitRequiresStagedChanges(t *testing.T) {
+	var doc bytes.Buffer
+	opts := newFakeContext(t, &git.FakeRepository{Branch: "feature/KWS-1-login"}, &doc)
+
+	cmd := NewCommitCmd(opts)
+	cmd.SetArgs([]string{"-m", "feat: add login"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "no staged changes") {
+		t.Fatalf("expected no staged changes, got %v", err)
+	}
+}
+
+func TestCommitWithStagedChanges(t *testing.T) {
+	var doc bytes.Buffer
+	repo := &git.FakeRepository{
+		Branch:     "feature/KWS-1-login",
+		StagedDiff: "diff --git a/login.go b/login.go\n",
+	}
+	opts := newFakeContext(t, repo, &doc)
+
+	cmd := NewCommitCmd(opts)
+	cmd.SetArgs([]string{"-m", "feat: add login"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	if plan := opts.Context().Git.Plan().Steps(); len(plan) != 1 || plan[0] != "git commit -m feat: add login" {
+		t.Errorf("expected the commit to be planned, got %v", plan)
+	}
+
+	// A dry run only plans the commit, so it must not report one
+	if err := opts.Context().Output.Flush("commit", nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(doc.String(), "Commit created") {
+		t.Errorf("dry run reported a created commit:\n%s", doc.String())
+	}
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// Context carries everything a command needs once flags are parsed
+type Context struct {
+	*Options
+
+	Output   *ui.Output
+	Git      *git.Git
+	Repo     git.Repository // read-only queries, answered by the configured git.backend
+	RepoRoot string         // empty outside a git repository
+
+	config    *config.Config
+	configErr error
+	command   string
+	stash     string // changes stashed by --autostash and not reapplied yet
+
+	// prompts is where prompts went before JSON mode sent them to stderr
+	prompts io.Writer
+}
+
+// newContext builds a text-mode context from the parsed flags
+func newContext(opts *Options) *Context {
+	ctx := &Context{
+		Options: opts,
+		Output:  ui.NewOutput(opts.Verbose),
+		Git:     git.NewGit(opts.DryRun, opts.Verbose),
+	}
+	ctx.Git.SetOffline(opts.Offline)
+	ctx.Repo = ctx.Git
+
+	if root, err := config.GetGitRoot(); err == nil {
+		ctx.RepoRoot = root
+		ctx.config, ctx.configErr = config.Load()
+		if ctx.configErr == nil {
+			if repo, err := git.OpenRepository(ctx.config.Git.Backend, root, ctx.Git); err != nil {
+				ctx.configErr = err
+			} else {
+				ctx.Repo = repo
+			}
+		}
+	}
+
+	return ctx
+}
+
+// setupContext builds the context for cmd; it runs as the root command's
+// PersistentPreRunE, after flags are parsed
+func setupContext(opts *Options, cmd *cobra.Command) error {
+	format, err := ui.ParseFormat(opts.Format)
+	if err != nil {
+		return err
+	}
+
+	ctx := newContext(opts)
+	ctx.command = cmd.CommandPath()
+
+	if format == ui.FormatJSON {
+		// Keep stdout for the JSON document; anything else printed while the
+		// command runs (git, CI steps, prompts) goes to stderr
+		ctx.Output = ui.NewJSONOutput(opts.Verbose, os.Stdout)
+		ctx.Git.SetLog(ctx.Output.Log())
+		ctx.prompts = ui.SetPromptOutput(ctx.Output.Log())
+
+		// Errors are reported in the document instead
+		cmd.Root().SilenceErrors = true
+		cmd.Root().SilenceUsage = true
+	}
+
+	opts.ctx = ctx
+	return nil
+}
+
+// RequireConfig returns the repository configuration, failing outside a git
+// repository or when .gitext cannot be loaded
+func (c *Context) RequireConfig() (*config.Config, error) {
+	if c.RepoRoot == "" {
+		return nil, ui.NewError("not in a git repository", "run this command from within a git repository")
+	}
+	if c.configErr != nil {
+		return nil, fmt.Errorf("failed to load config: %w", c.configErr)
+	}
+	return c.config, nil
+}
+
+// Simulate reports a non-git step skipped in dry-run mode and adds it to the plan
+func (c *Context) Simulate(format string, args ...interface{}) {
+	step := fmt.Sprintf(format, args...)
+	c.Output.Info("[DRY RUN] would %s", step)
+	c.Git.Plan().Add(step)
+}
+
+// Finish reports changes left stashed by a failed command and the dry-run
+// plan, and writes the JSON document for the command that ran, if any
+func (c *Context) Finish(err error) error {
+	if c.prompts != nil {
+		ui.SetPromptOutput(c.prompts)
+		c.prompts = nil
+	}
+	c.reportAutostash(err)
+	if c.DryRun {
+		c.reportPlan()
+	}
+	return c.Output.Flush(c.command, err)
+}
+
+// reportPlan lists the mutating steps a dry run skipped, in order
+func (c *Context) reportPlan() {
+	steps := c.Git.Plan().Steps()
+	c.Output.Set("plan", append([]string{}, steps...))
+
+	if len(steps) == 0 {
+		c.Output.Info("Dry run: no changes would be made")
+		return
+	}
+	c.Output.Info("Dry run plan (%d step(s)):", len(steps))
+	for i, step := range steps {
+		c.Output.Print("  %d. %s", i+1, step)
+	}
+}
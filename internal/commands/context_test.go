@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONOutputKeepsStdoutForTheDocument(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+
+	doc, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+	stdout := os.Stdout
+	os.Stdout = doc
+	defer func() { os.Stdout = stdout }()
+
+	// The dry-run steps and verbose git calls go to stderr
+	_, err = runGitext("sync", "stage", "--dry-run", "--verbose", "--output", "json")
+	if os.Stdout != doc {
+		t.Fatal("os.Stdout was replaced")
+	}
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	data, err := os.ReadFile(doc.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		OK     bool `json:"ok"`
+		Result struct {
+			Plan []string `json:"plan"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("stdout is not a single JSON document: %v\n%s", err, data)
+	}
+	if !result.OK || len(result.Result.Plan) == 0 {
+		t.Errorf("expected a successful dry run with a plan, got:\n%s", data)
+	}
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/operation"
+	"github.com/imemir/gitext/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+func NewContinueCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "continue",
+		Short: "Continue a rebase, merge or cherry-pick stopped on conflicts",
+		Long: `Resume the rebase or merge an update or retarget stopped on, or the cherry-pick
+of a promote, once the conflicts are resolved and staged, then finish the
+remaining steps of the command (force-push advice and next steps, or the CI
+checks and PR text of a promotion).
+Also works if the operation was already completed with git. If it was
+aborted with git instead, the saved state is cleared and stashed changes are
+reapplied, without reporting the command as done.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := opts.Context()
+			output, g := ctx.Output, ctx.Git
+
+			cfg, err := ctx.RequireConfig()
+			if err != nil {
+				return err
+			}
+
+			path, err := operationPath(g)
+			if err != nil {
+				return err
+			}
+			state, err := operation.Load(path)
+			if err != nil {
+				return err
+			}
+			inProgress, err := g.GetInProgressOperation()
+			if err != nil {
+				return fmt.Errorf("failed to detect in-progress operation: %w", err)
+			}
+
+			if state == nil && inProgress == "" {
+				return ui.NewError("no operation in progress", "run: gitext status")
+			}
+
+			if inProgress != "" {
+				files, err := g.GetConflictedFiles()
+				if err != nil {
+					return fmt.Errorf("failed to list conflicted files: %w", err)
+				}
+				if len(files) > 0 {
+					output.Error("%d file(s) still have conflicts:", len(files))
+					for _, file := range files {
+						output.Print("  - %s", file)
+					}
+					return ui.NewError("unresolved conflicts", "resolve them and stage with git add, then run: gitext continue")
+				}
+
+				output.Doing("Continuing %s", inProgress)
+				if _, err := g.ContinueOperation(inProgress); err != nil {
+					// A rebase stops again at the next conflicting commit
+					if still, _ := g.GetInProgressOperation(); still != "" {
+						if state == nil {
+							return ui.NewError(fmt.Sprintf("%s stopped on conflicts again", inProgress), "resolve conflicts and stage them with git add, then run: gitext continue")
+						}
+						return pauseOperation(ctx, state, err)
+					}
+					return fmt.Errorf("failed to continue %s: %w", inProgress, err)
+				}
+			}
+
+			if state == nil {
+				// Started outside gitext: nothing more to finish
+				output.Did("Completed %s", inProgress)
+				output.Next("run: gitext status")
+				return nil
+			}
+
+			// An operation aborted with git leaves the state behind without
+			// having moved the branch: there is nothing to finish
+			if inProgress == "" && !operationLanded(g, state) {
+				return abortedOutside(ctx, path, state)
+			}
+
+			// The operation is done even if reapplying stashed changes is not
+			if !ctx.DryRun {
+				if err := operation.Clear(path); err != nil {
+					return err
+				}
+			}
+			output.Set("operation", state)
+
+			if state.Promotion != nil {
+				toEnv, err := resolveEnvironment(cfg, state.Promotion.Environment, "target environment")
+				if err != nil {
+					return err
+				}
+				output.Did("%s", state.Summary)
+				return finishPromotion(ctx, toEnv, state)
+			}
+			return finishOperation(ctx, state)
+		},
+	}
+
+	return cmd
+}
+
+// operationLanded reports whether the operation of a state git no longer has
+// in progress completed: a rebase or merge leaves the target in the branch,
+// a cherry-pick adds commits on top of it. When unsure it reports true.
+func operationLanded(g *git.Git, state *operation.State) bool {
+	landed, err := g.IsAncestor(state.Target, state.Branch)
+	if err != nil || !landed {
+		return err != nil
+	}
+	if state.Operation == git.OperationCherryPick {
+		unchanged, err := g.IsAncestor(state.Branch, state.Target)
+		return err != nil || !unchanged
+	}
+	return true
+}
+
+// abortedOutside clears the state of a command whose rebase or merge was
+// aborted with git, and reapplies the changes it stashed
+func abortedOutside(ctx *Context, path string, state *operation.State) error {
+	output := ctx.Output
+
+	if !ctx.DryRun {
+		if err := operation.Clear(path); err != nil {
+			return err
+		}
+	}
+	output.Warning("The %s of %s onto %s was aborted outside gitext; %s did not complete", state.Operation, state.Branch, state.Target, state.Command)
+	output.Set("operation", state)
+	if err := restoreAutostash(ctx, state.Stash); err != nil {
+		return err
+	}
+
+	return ui.NewError(
+		fmt.Sprintf("%s was aborted outside gitext", state.Operation),
+		"branches the command moved before it stopped are restored by: gitext undo (gitext abort only handles operations still in progress)",
+	)
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/ui"
+)
+
+// resolveEnvironment looks up the environment named by a flag or argument
+func resolveEnvironment(cfg *config.Config, name, flag string) (*config.Environment, error) {
+	names := strings.Join(cfg.EnvironmentNames(), ", ")
+	if name == "" {
+		return nil, fmt.Errorf("%s is required (%s)", flag, names)
+	}
+	env, err := cfg.Environment(name)
+	if err != nil {
+		return nil, ui.NewError(fmt.Sprintf("invalid %s '%s'", flag, name), "use one of: "+names)
+	}
+	return env, nil
+}
+
+// environmentChoices describes the valid environment names for help text
+func environmentChoices(cfg *config.Config) string {
+	return strings.Join(cfg.EnvironmentNames(), ", ")
+}
+
+// backmergeEnvironment returns the environment hotfixes are back-merged into:
+// the one directly below production
+func backmergeEnvironment(cfg *config.Config) *config.Environment {
+	return cfg.PreviousEnvironment(cfg.ProductionEnvironment().Name)
+}
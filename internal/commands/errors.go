@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/ui"
+)
+
+// gitFailure reports a failed git step with a suggestion matching why git
+// failed. hints override the default suggestion for a kind of failure;
+// failures git's output does not explain are wrapped unchanged.
+func gitFailure(step string, err error, remote string, hints map[git.ErrorKind]string) error {
+	var gitErr *git.Error
+	if !errors.As(err, &gitErr) {
+		return fmt.Errorf("%s: %w", step, err)
+	}
+
+	suggestion, ok := hints[gitErr.Kind]
+	if !ok {
+		suggestion = gitSuggestion(gitErr.Kind, remote)
+	}
+	if suggestion == "" {
+		return fmt.Errorf("%s: %w", step, err)
+	}
+	return ui.NewError(fmt.Sprintf("%s: %s", step, gitErr.Summary()), suggestion)
+}
+
+// gitSuggestion returns the usual fix for a kind of git failure
+func gitSuggestion(kind git.ErrorKind, remote string) string {
+	switch kind {
+	case git.ErrorAuth:
+		return fmt.Sprintf("check your credentials for '%s' (SSH key or access token), then retry", remote)
+	case git.ErrorNetwork:
+		return fmt.Sprintf("check your connection to '%s', or rerun with --offline to use the last fetch", remote)
+	case git.ErrorLockHeld:
+		return "another git process is running; wait for it to finish, or remove the stale .lock file git names"
+	case git.ErrorNotFound:
+		return fmt.Sprintf("check the name, or fetch it first: git fetch %s", remote)
+	case git.ErrorNonFastForward:
+		return fmt.Sprintf("the branch has diverged from '%s'; update it before retrying", remote)
+	case git.ErrorConflict:
+		return "resolve conflicts and stage them with git add, then retry"
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/ui"
+)
+
+func TestSyncSuggestsRebaseWhenDiverged(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+
+	// Commit to stage locally while someone else pushes another commit
+	tree := strings.TrimSpace(repo.git(repo.dir, "rev-parse", "stage~1^{tree}"))
+	other := strings.TrimSpace(repo.git(repo.dir, "commit-tree", "-p", "stage~1", "-m", "KWS-6 other", tree))
+	repo.git(repo.dir, "push", "-q", "-f", "origin", other+":refs/heads/stage")
+	repo.git(repo.dir, "checkout", "-q", "stage")
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "KWS-5 local")
+
+	_, err := runGitext("sync", "stage")
+	var uiErr *ui.ErrorWithSuggestion
+	if !errors.As(err, &uiErr) {
+		t.Fatalf("expected an error with a suggestion, got %v", err)
+	}
+	if !strings.Contains(uiErr.Suggestion, "git pull --rebase origin stage") {
+		t.Errorf("expected a rebase suggestion, got %q", uiErr.Suggestion)
+	}
+}
+
+func TestUpdateDoesNotPauseWithoutConflicts(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-1 add staged file")
+
+	// Another git process holds the index
+	lock := filepath.Join(repo.dir, ".git", "index.lock")
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := runGitext("update", "feature", "--with", "stage")
+	var uiErr *ui.ErrorWithSuggestion
+	if !errors.As(err, &uiErr) {
+		t.Fatalf("expected an error with a suggestion, got %v", err)
+	}
+	if !strings.Contains(uiErr.Suggestion, ".lock") {
+		t.Errorf("expected a lock suggestion, got %q", uiErr.Suggestion)
+	}
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git", "gitext", "operation.json")); !os.IsNotExist(err) {
+		t.Error("expected no paused operation to be saved")
+	}
+}
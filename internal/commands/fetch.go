@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/git"
+)
+
+// fetchRemote refreshes the remote-tracking refs according to --offline and
+// fetch.policy, and reports whether it fetched. Commands that skip the fetch
+// work from the refs of the last fetch. When working from a fork, both the
+// integration and the push remote are fetched.
+func fetchRemote(ctx *Context, cfg *config.Config) (bool, error) {
+	output, g := ctx.Output, ctx.Git
+	remote := cfg.IntegrationRemote()
+
+	if ctx.Offline {
+		output.Verbose("Offline: using remote-tracking refs of %s from the last fetch", remote)
+		return false, nil
+	}
+
+	switch cfg.Fetch.Policy {
+	case config.FetchNever:
+		output.Verbose("fetch.policy is never: using remote-tracking refs of %s", remote)
+		return false, nil
+	case config.FetchStale:
+		maxAge, err := cfg.FetchMaxAge()
+		if err != nil {
+			return false, err
+		}
+		if last, err := g.GetLastFetch(); err == nil && !last.IsZero() && time.Since(last) < maxAge {
+			output.Verbose("Last fetched %s ago, not fetching %s", time.Since(last).Round(time.Second), remote)
+			return false, nil
+		}
+	}
+
+	remotes := []string{remote}
+	if cfg.IsFork() {
+		remotes = append(remotes, cfg.PushRemote())
+	}
+	for _, remote := range remotes {
+		output.Doing("Fetching latest from %s", remote)
+		if _, err := g.RunWithTimeout("fetch", remote); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// checkoutBranch checks out branch, creating it from remote/branch when it
+// does not exist locally. Unlike git's own guess, this works when several
+// remotes (e.g., a fork and upstream) have a branch of that name.
+func checkoutBranch(g *git.Git, remote, branch string) error {
+	exists, err := g.BranchExists(branch)
+	if err != nil {
+		return err
+	}
+	if exists {
+		_, err = g.RunWithTimeout("checkout", branch)
+	} else {
+		_, err = g.RunWithTimeout("checkout", "-b", branch, "--track", fmt.Sprintf("%s/%s", remote, branch))
+	}
+	return err
+}
+
+// fastForward brings the checked-out branch up to date with remote/branch:
+// with git pull when the remote was just fetched, otherwise by merging the
+// remote-tracking branch without contacting the remote
+func fastForward(g *git.Git, fetched bool, remote, branch string) (string, error) {
+	if fetched {
+		return g.RunWithTimeout("pull", "--ff-only", remote, branch)
+	}
+	return g.RunWithTimeout("merge", "--ff-only", fmt.Sprintf("%s/%s", remote, branch))
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+// networkCalls returns the logged git calls that contact the remote
+func networkCalls(calls []string) []string {
+	var network []string
+	for _, call := range calls {
+		if fields := strings.Fields(call); len(fields) > 0 {
+			switch fields[0] {
+			case "fetch", "pull", "push", "ls-remote":
+				network = append(network, call)
+			}
+		}
+	}
+	return network
+}
+
+func TestOfflineMakesNoNetworkCalls(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+
+	commands := [][]string{
+		{"status"},
+		{"sync", "stage"},
+		{"update", "feature", "--with", "stage"},
+		{"retarget", "feature"},
+	}
+	for _, args := range commands {
+		name := strings.Join(args, " ")
+		repo.git(repo.dir, "checkout", "-q", "feature/KWS-1-login")
+		repo.loggedCalls()
+
+		if _, err := runGitext(append(args, "--offline")...); err != nil {
+			t.Errorf("%s --offline failed: %v", name, err)
+		}
+		if calls := networkCalls(repo.loggedCalls()); len(calls) > 0 {
+			t.Errorf("%s: contacted the remote offline: %v", name, calls)
+		}
+	}
+}
+
+func TestStaleFetchPolicy(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.writeFile(".gitext", "fetch:\n  policy: stale\n  maxAge: 1h\n")
+
+	if _, err := runGitext("status"); err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if calls := networkCalls(repo.loggedCalls()); len(calls) != 1 || calls[0] != "fetch origin" {
+		t.Errorf("expected a fetch without a previous one, got %v", calls)
+	}
+
+	if _, err := runGitext("status"); err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if calls := networkCalls(repo.loggedCalls()); len(calls) > 0 {
+		t.Errorf("expected no fetch within fetch.maxAge, got %v", calls)
+	}
+}
+
+func TestFetchPolicyNeverMakesNoNetworkCalls(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+	repo.writeFile(".gitext", "fetch:\n  policy: never\n")
+	repo.git(repo.dir, "add", ".gitext")
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-1 add config")
+
+	commands := [][]string{
+		{"sync", "stage"},
+		{"update", "feature", "--with", "stage"},
+		{"retarget", "feature"},
+		{"start", "feature", "--ticket", "KWS-9", "--slug", "new thing", "--from", "stage"},
+	}
+	for _, args := range commands {
+		name := strings.Join(args, " ")
+		repo.git(repo.dir, "checkout", "-q", "feature/KWS-1-login")
+		repo.loggedCalls()
+
+		if _, err := runGitext(args...); err != nil {
+			t.Errorf("%s failed: %v", name, err)
+		}
+		if calls := networkCalls(repo.loggedCalls()); len(calls) > 0 {
+			t.Errorf("%s: contacted the remote with fetch.policy never: %v", name, calls)
+		}
+	}
+}
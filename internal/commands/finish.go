@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+func NewFinishCmd(opts *Options) *cobra.Command {
+	var branch, into string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "finish hotfix",
+		Short: "Back-merge a released hotfix into stage",
+		Long: `Finish a hotfix after its PR has been merged into production.
+Creates a back-merge branch from the environment below production (stage by
+default) and merges production into it, so it never falls behind production
+after an emergency fix.
+The branch name will be: backmerge/<ticket>-<slug>
+
+The hotfix counts as merged when production contains its commits or, after
+a squash or rebase merge, equivalent changes. Use --force when production
+holds the fix in a form git cannot match (e.g., a squash of several commits).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if args[0] != branchTypeHotfix {
+				return fmt.Errorf("only 'hotfix' is supported")
+			}
+
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
+
+			cfg, err := ctx.RequireConfig()
+			if err != nil {
+				return err
+			}
+
+			// Back-merge into the environment below production unless told otherwise
+			production := cfg.ProductionEnvironment()
+			if into == "" {
+				if backmerge := backmergeEnvironment(cfg); backmerge != nil {
+					into = backmerge.Name
+				}
+			}
+			intoEnv, err := resolveEnvironment(cfg, into, "--into")
+			if err != nil {
+				return err
+			}
+			if intoEnv.Name == production.Name {
+				return fmt.Errorf("--into must be an environment other than %s", production.Name)
+			}
+
+			// Default to the current branch
+			if branch == "" {
+				branch, err = repo.GetCurrentBranch()
+				if err != nil {
+					return fmt.Errorf("failed to get current branch: %w", err)
+				}
+			}
+
+			if !isHotfixBranch(cfg, branch) {
+				return ui.NewError(
+					fmt.Sprintf("branch '%s' does not match hotfix pattern '%s'", branch, cfg.Naming.Hotfix),
+					"checkout the hotfix branch or pass --branch hotfix/<ticket>-<slug>",
+				)
+			}
+
+			// Validate remote
+			remote := cfg.IntegrationRemote()
+			if err := g.ValidateRemote(remote); err != nil {
+				return err
+			}
+
+			// Check working tree
+			isClean, err := repo.IsWorkingTreeClean()
+			if err != nil {
+				return fmt.Errorf("failed to check working tree: %w", err)
+			}
+			if !isClean {
+				return ui.NewError("working tree has uncommitted changes", "commit or stash changes first")
+			}
+
+			// Fetch latest
+			if _, err := fetchRemote(ctx, cfg); err != nil {
+				return gitFailure("failed to fetch", err, remote, nil)
+			}
+
+			// The hotfix may already be deleted locally once its PR is merged
+			hotfixRef := branch
+			exists, err := repo.BranchExists(branch)
+			if err != nil {
+				return fmt.Errorf("failed to check if branch exists: %w", err)
+			}
+			if !exists {
+				hotfixRef = fmt.Sprintf("%s/%s", cfg.PushRemote(), branch)
+			}
+
+			productionRef := fmt.Sprintf("%s/%s", remote, production.Branch)
+			intoRef := fmt.Sprintf("%s/%s", remote, intoEnv.Branch)
+
+			// Only back-merge what actually shipped
+			merged, err := g.IsAncestor(hotfixRef, productionRef)
+			if err != nil {
+				return fmt.Errorf("failed to check if %s is merged into %s: %w", branch, productionRef, err)
+			}
+			if !merged {
+				// A squash or rebase merge leaves new commits with the same changes
+				merged, err = g.IsPatchMerged(hotfixRef, productionRef)
+				if err != nil {
+					return fmt.Errorf("failed to compare %s with %s: %w", branch, productionRef, err)
+				}
+			}
+			if !merged {
+				if !force {
+					return ui.NewError(
+						fmt.Sprintf("hotfix '%s' has not been merged into %s yet", branch, production.Branch),
+						fmt.Sprintf("merge the hotfix PR first (gitext prepare pr --to %s), then run: gitext finish hotfix (or --force if it was merged in a form git cannot match)", production.Name),
+					)
+				}
+				output.Warning("%s does not contain the changes of %s; back-merging anyway (--force)", productionRef, branch)
+			}
+
+			backmergeBranch := backmergeBranchName(cfg, branch)
+			exists, err = repo.BranchExists(backmergeBranch)
+			if err != nil {
+				return fmt.Errorf("failed to check if branch exists: %w", err)
+			}
+			if exists {
+				return fmt.Errorf("branch '%s' already exists", backmergeBranch)
+			}
+
+			// Create back-merge branch from the target environment
+			output.Doing("Creating branch %s from %s", backmergeBranch, intoRef)
+			if _, err := g.RunWithTimeout("checkout", "-b", backmergeBranch, intoRef); err != nil {
+				return gitFailure("failed to create branch", err, remote, nil)
+			}
+
+			// Merge production into it
+			output.Doing("Merging %s into %s", productionRef, backmergeBranch)
+			message := fmt.Sprintf("Merge %s into %s after %s", production.Branch, intoEnv.Branch, branch)
+			if _, err := g.RunWithTimeout("merge", "--no-ff", "-m", message, productionRef); err != nil {
+				if git.KindOf(err) != git.ErrorConflict {
+					return gitFailure("merge failed", err, remote, nil)
+				}
+				output.Error("Merge encountered conflicts")
+				output.Next("resolve conflicts, then run: git commit")
+				return fmt.Errorf("merge failed: %w", err)
+			}
+			output.Did("Merged %s into %s", productionRef, backmergeBranch)
+			output.Set("branch", backmergeBranch)
+
+			output.Next("push and open a PR into %s: gitext push && gitext prepare pr --to %s", intoEnv.Branch, intoEnv.Name)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&branch, "branch", "", "Hotfix branch to finish (defaults to the current branch)")
+	cmd.Flags().StringVar(&into, "into", "", "Environment to back-merge into (default: the one below production)")
+	cmd.Flags().BoolVar(&force, "force", false, "Back-merge even if the hotfix does not appear merged into production")
+
+	return cmd
+}
+
+// backmergeBranchName derives the back-merge branch name from a hotfix branch
+// (e.g., hotfix/KWS-123-slug -> backmerge/KWS-123-slug)
+func backmergeBranchName(cfg *config.Config, hotfixBranch string) string {
+	if tmpl, err := cfg.BranchTemplate(branchTypeHotfix); err == nil {
+		if values, ok := tmpl.Extract(hotfixBranch); ok && values.Ticket != "" && values.Slug != "" {
+			return fmt.Sprintf("%s%s-%s", config.DefaultBackmergePrefix, values.Ticket, values.Slug)
+		}
+	}
+
+	name := hotfixBranch
+	if idx := strings.Index(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return config.DefaultBackmergePrefix + name
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/config"
+)
+
+// squashMergedHotfix adds a hotfix with changes and squash-merges it into
+// production on origin, leaving production checked out
+func squashMergedHotfix(t *testing.T, repo *gitTestRepo) {
+	t.Helper()
+	repo.git(repo.dir, "stash", "-q")
+	repo.git(repo.dir, "checkout", "-q", "-b", "hotfix/KWS-4-crash", "production")
+	repo.writeFile("crash.txt", "fixed\n")
+	repo.git(repo.dir, "add", "crash.txt")
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-4 fix crash")
+	repo.git(repo.dir, "checkout", "-q", "production")
+	repo.git(repo.dir, "merge", "-q", "--squash", "hotfix/KWS-4-crash")
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-4 fix crash (#12)")
+	repo.git(repo.dir, "push", "-q", "origin", "production")
+}
+
+func TestFinishAcceptsSquashMergedHotfix(t *testing.T) {
+	repo := newGitTestRepo(t)
+	squashMergedHotfix(t, repo)
+
+	if _, err := runGitext("finish", "hotfix", "--branch", "hotfix/KWS-4-crash"); err != nil {
+		t.Fatalf("finish failed: %v", err)
+	}
+	if branch := strings.TrimSpace(repo.git(repo.dir, "branch", "--show-current")); branch != "backmerge/KWS-4-crash" {
+		t.Errorf("expected backmerge/KWS-4-crash to be checked out, got %s", branch)
+	}
+}
+
+func TestFinishRefusesUnmergedHotfix(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+	repo.git(repo.dir, "checkout", "-q", "-b", "hotfix/KWS-4-crash", "production")
+	repo.writeFile("crash.txt", "fixed\n")
+	repo.git(repo.dir, "add", "crash.txt")
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-4 fix crash")
+
+	if _, err := runGitext("finish", "hotfix"); err == nil || !strings.Contains(err.Error(), "not been merged") {
+		t.Fatalf("expected an unmerged hotfix to be refused, got %v", err)
+	}
+	if _, err := runGitext("finish", "hotfix", "--force"); err != nil {
+		t.Fatalf("finish --force failed: %v", err)
+	}
+}
+
+func TestBackmergeBranchName(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Naming.Templates = map[string]string{"hotfix": "fix/{ticket}/{slug}"}
+
+	tests := map[string]string{
+		"fix/KWS-4/crash": "backmerge/KWS-4-crash",
+		// Names the template cannot parse keep everything after the prefix
+		"hotfix/odd-name": "backmerge/odd-name",
+	}
+	for branch, want := range tests {
+		if got := backmergeBranchName(cfg, branch); got != want {
+			t.Errorf("backmergeBranchName(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
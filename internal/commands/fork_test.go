@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newForkTestRepo turns the test repository into a fork: origin is the
+// contributor's fork and upstream, which has moved on, is integrated into
+func newForkTestRepo(t *testing.T) *gitTestRepo {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+
+	root := filepath.Dir(repo.dir)
+	upstream := filepath.Join(root, "upstream.git")
+	repo.git(root, "clone", "-q", "--bare", filepath.Join(root, "remote.git"), upstream)
+	repo.git(repo.dir, "remote", "add", "upstream", upstream)
+	tree := strings.TrimSpace(repo.git(repo.dir, "rev-parse", "stage^{tree}"))
+	commit := strings.TrimSpace(repo.git(repo.dir, "commit-tree", "-p", "stage", "-m", "KWS-4 upstream change", tree))
+	repo.git(repo.dir, "push", "-q", "upstream", commit+":refs/heads/stage")
+
+	repo.writeFile(".gitext", "remote:\n  name: origin\n  integrationRemote: upstream\n")
+	if err := os.WriteFile(filepath.Join(repo.dir, ".git", "info", "exclude"), []byte(".gitext\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestForkStartAndPush(t *testing.T) {
+	repo := newForkTestRepo(t)
+
+	if _, err := runGitext("start", "feature", "--ticket", "KWS-9", "--slug", "fork", "--from", "stage"); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	calls := networkCalls(repo.loggedCalls())
+	if len(calls) != 3 || calls[0] != "fetch upstream" || calls[1] != "fetch origin" || calls[2] != "pull --ff-only upstream stage" {
+		t.Errorf("expected both remotes fetched and stage pulled from upstream, got %v", calls)
+	}
+	if log := repo.git(repo.dir, "log", "--format=%s", "HEAD"); !strings.Contains(log, "KWS-4 upstream change") {
+		t.Errorf("expected the branch to start from upstream/stage, got\n%s", log)
+	}
+
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "KWS-9 fork change")
+	if _, err := runGitext("push"); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	if upstream := strings.TrimSpace(repo.git(repo.dir, "rev-parse", "--abbrev-ref", "@{upstream}")); upstream != "origin/feature/KWS-9-fork" {
+		t.Errorf("expected the branch pushed to the fork, got upstream %s", upstream)
+	}
+	if refs := repo.git(repo.dir, "ls-remote", "upstream", "refs/heads/feature/KWS-9-fork"); refs != "" {
+		t.Errorf("expected nothing pushed to upstream, got %s", refs)
+	}
+}
+
+func TestForkUpdateRebasesOntoUpstream(t *testing.T) {
+	repo := newForkTestRepo(t)
+
+	if _, err := runGitext("update", "feature", "--with", "stage"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if log := repo.git(repo.dir, "log", "--format=%s", "HEAD"); !strings.Contains(log, "KWS-4 upstream change") {
+		t.Errorf("expected the branch updated with upstream/stage, got\n%s", log)
+	}
+	// The pushed branch lives on the fork, so the lease pins origin's tip
+	lease := strings.TrimSpace(repo.git(repo.dir, "config", "--get", leaseKey("feature/KWS-1-login")))
+	if remote := strings.TrimSpace(repo.git(repo.dir, "rev-parse", "origin/feature/KWS-1-login")); lease != remote {
+		t.Errorf("expected the lease to pin %s, got %s", remote, lease)
+	}
+}
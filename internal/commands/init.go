@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/imemir/gitext/pkg/config"
 	"github.com/imemir/gitext/pkg/ui"
@@ -17,25 +18,24 @@ func NewInitCmd(opts *Options) *cobra.Command {
 		Use:   "init",
 		Short: "Initialize gitext configuration",
 		Long: `Initialize gitext by creating a .gitext configuration file in the repository root.
-Optionally install git hooks to prevent direct pushes to protected branches.`,
+Optionally install git hooks: a pre-push hook preventing direct pushes to protected
+branches and a commit-msg hook checking messages with gitext lint-commits.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output := ui.NewOutput(opts.Verbose)
-			cfg, err := config.Load()
+			ctx := opts.Context()
+			output := ctx.Output
+			cfg, err := ctx.RequireConfig()
 			if err != nil {
-				// Not in a git repo
 				return fmt.Errorf("failed to initialize: %w", err)
 			}
-
-			gitRoot, err := config.GetGitRoot()
-			if err != nil {
-				return fmt.Errorf("failed to get git root: %w", err)
-			}
+			gitRoot := ctx.RepoRoot
 
 			configPath := filepath.Join(gitRoot, ".gitext")
 			_, err = os.Stat(configPath)
 			if err == nil {
 				output.Info(".gitext already exists at %s", configPath)
 				output.Next("edit .gitext to customize configuration")
+			} else if ctx.DryRun {
+				ctx.Simulate("create .gitext at %s", configPath)
 			} else {
 				output.Doing("Creating .gitext configuration file")
 				if err := cfg.Save(); err != nil {
@@ -44,8 +44,18 @@ Optionally install git hooks to prevent direct pushes to protected branches.`,
 				output.Did("Created .gitext at %s", configPath)
 			}
 
-			if installHooks {
-				if err := installPrePushHook(gitRoot, cfg, output); err != nil {
+			// Hooks are shared by all worktrees, and .git is a file in linked ones
+			hooksDir, err := ctx.Git.GetGitPath("hooks")
+			if err != nil {
+				return fmt.Errorf("failed to locate hooks directory: %w", err)
+			}
+			if installHooks && ctx.DryRun {
+				ctx.Simulate("install the pre-push and commit-msg hooks in %s", hooksDir)
+			} else if installHooks {
+				if err := installHook(hooksDir, "pre-push", generatePrePushHook(cfg), output); err != nil {
+					return fmt.Errorf("failed to install hooks: %w", err)
+				}
+				if err := installHook(hooksDir, "commit-msg", generateCommitMsgHook(), output); err != nil {
 					return fmt.Errorf("failed to install hooks: %w", err)
 				}
 			} else {
@@ -56,18 +66,27 @@ Optionally install git hooks to prevent direct pushes to protected branches.`,
 		},
 	}
 
-	cmd.Flags().BoolVar(&installHooks, "install-hooks", false, "Install git hooks to prevent direct pushes to protected branches")
+	cmd.Flags().BoolVar(&installHooks, "install-hooks", false, "Install git hooks preventing direct pushes to protected branches and checking commit messages")
 
 	return cmd
 }
 
-func installPrePushHook(gitRoot string, cfg *config.Config, output *ui.Output) error {
-	hooksDir := filepath.Join(gitRoot, ".git", "hooks")
-	hookPath := filepath.Join(hooksDir, "pre-push")
+// installHook writes a hook, replacing it only if gitext installed it: a
+// hook of another tool or written by hand is left alone with a warning
+func installHook(hooksDir, name, hookContent string, output *ui.Output) error {
+	hookPath := filepath.Join(hooksDir, name)
 
-	output.Doing("Installing pre-push hook")
+	existing, err := os.ReadFile(hookPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read existing hook: %w", err)
+	}
+	if err == nil && !strings.Contains(string(existing), hookMarker(name)) {
+		output.Warning("%s already exists and was not installed by gitext, leaving it alone", hookPath)
+		output.Next("merge the gitext %s hook into it, or remove it and run: gitext init --install-hooks", name)
+		return nil
+	}
 
-	hookContent := generatePrePushHook(cfg)
+	output.Doing("Installing %s hook", name)
 
 	if err := os.MkdirAll(hooksDir, 0755); err != nil {
 		return fmt.Errorf("failed to create hooks directory: %w", err)
@@ -77,16 +96,21 @@ func installPrePushHook(gitRoot string, cfg *config.Config, output *ui.Output) e
 		return fmt.Errorf("failed to write hook: %w", err)
 	}
 
-	output.Did("Installed pre-push hook at %s", hookPath)
+	output.Did("Installed %s hook at %s", name, hookPath)
 	return nil
 }
 
+// hookMarker is the line identifying a hook installed by gitext
+func hookMarker(name string) string {
+	return fmt.Sprintf("# gitext %s hook", name)
+}
+
 func generatePrePushHook(cfg *config.Config) string {
 	return fmt.Sprintf(`#!/bin/sh
 # gitext pre-push hook
-# Prevents direct pushes to protected branches (stage/production)
+# Prevents direct pushes to protected environment branches
 
-protected_branches="%s %s"
+protected_branches="%s"
 remote="$1"
 url="$2"
 
@@ -109,5 +133,19 @@ do
 done
 
 exit 0
-`, cfg.Branch.Production, cfg.Branch.Stage)
+`, strings.Join(cfg.ProtectedBranches(), " "))
+}
+
+func generateCommitMsgHook() string {
+	return `#!/bin/sh
+# gitext commit-msg hook
+# Checks the commit message against Conventional Commits and the commits rules in .gitext
+
+if ! command -v gitext >/dev/null 2>&1; then
+    echo "Warning: gitext not found in PATH, commit message not checked."
+    exit 0
+fi
+
+exec gitext lint-commits --file "$1"
+`
 }
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitKeepsForeignHook(t *testing.T) {
+	repo := newGitTestRepo(t)
+	hook := filepath.Join(repo.dir, ".git", "hooks", "commit-msg")
+	foreign := "#!/bin/sh\n# installed by another tool\nexit 0\n"
+	if err := os.WriteFile(hook, []byte(foreign), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runGitext("init", "--install-hooks"); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	if data, _ := os.ReadFile(hook); string(data) != foreign {
+		t.Errorf("expected the foreign commit-msg hook to be kept, got:\n%s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo.dir, ".git", "hooks", "pre-push")); !strings.Contains(string(data), hookMarker("pre-push")) {
+		t.Error("expected the pre-push hook to be installed")
+	}
+
+	// Hooks gitext installed are replaced
+	prePush := filepath.Join(repo.dir, ".git", "hooks", "pre-push")
+	if err := os.WriteFile(prePush, []byte("#!/bin/sh\n"+hookMarker("pre-push")+"\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGitext("init", "--install-hooks"); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	if data, _ := os.ReadFile(prePush); !strings.Contains(string(data), "protected_branches") {
+		t.Errorf("expected the gitext pre-push hook to be replaced, got:\n%s", data)
+	}
+}
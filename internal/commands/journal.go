@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/journal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// openJournal returns the undo journal stored under .git/gitext/journal
+func openJournal(g *git.Git) (*journal.Journal, error) {
+	dir, err := g.GetGitextDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate journal: %w", err)
+	}
+	return journal.New(filepath.Join(dir, "journal")), nil
+}
+
+// snapshot captures HEAD and the current tips of the given branches before a
+// command moves them
+func snapshot(g *git.Git, command string, branches ...string) (*journal.Entry, error) {
+	head, err := g.ResolveRef("HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	headBranch, err := g.GetSymbolicHEAD()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	entry := &journal.Entry{
+		Command: command,
+		HEAD:    head,
+		Branch:  headBranch,
+		Refs:    make(map[string]string),
+	}
+	for _, branch := range branches {
+		tip, err := g.ResolveRef("refs/heads/" + branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", branch, err)
+		}
+		entry.Refs[branch] = tip
+	}
+	return entry, nil
+}
+
+// recordJournal snapshots the given branches and stores the entry, so the
+// command can later be undone with gitext undo. Nothing is recorded in
+// dry-run mode, where the returned entry is nil.
+func recordJournal(ctx *Context, command string, branches ...string) (*journal.Entry, error) {
+	if ctx.DryRun {
+		return nil, nil
+	}
+	entry, err := snapshot(ctx.Git, command, branches...)
+	if err != nil {
+		return nil, err
+	}
+	entry.Stash = ctx.stash
+	if err := saveJournal(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// saveJournal stores an entry in the undo journal
+func saveJournal(ctx *Context, entry *journal.Entry) error {
+	j, err := openJournal(ctx.Git)
+	if err != nil {
+		return err
+	}
+	if err := j.Record(entry); err != nil {
+		return err
+	}
+	ctx.Output.Verbose("Recorded journal entry %s (undo with: gitext undo %s)", entry.ID, entry.ID)
+	return nil
+}
+
+// commandLine renders the command as typed, for journal entries
+func commandLine(cmd *cobra.Command, args []string) string {
+	parts := append([]string{cmd.CommandPath()}, args...)
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if flag.Value.Type() == "bool" {
+			parts = append(parts, "--"+flag.Name)
+		} else {
+			parts = append(parts, fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String()))
+		}
+	})
+	return strings.Join(parts, " ")
+}
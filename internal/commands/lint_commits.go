@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/imemir/gitext/pkg/conventional"
+	"github.com/imemir/gitext/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+func NewLintCommitsCmd(opts *Options) *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "lint-commits [range]",
+		Short: "Check commit messages against Conventional Commits",
+		Long: `Check that commit messages follow the Conventional Commits specification and the
+commits rules in .gitext: allowed types and scopes, the maximum length of the
+first line and, with requireTicket, a ticket reference.
+The range defaults to the commits of the current branch that are not on the
+integration environment (e.g., origin/stage..HEAD). Merges, reverts and
+fixup! commits are skipped.
+
+With --file, the message in a file is checked instead; the commit-msg hook
+installed by 'gitext init --install-hooks' runs gitext lint-commits --file.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
+
+			cfg, err := ctx.RequireConfig()
+			if err != nil {
+				return err
+			}
+			rules := cfg.CommitRules()
+
+			if file != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("--file and a range cannot be used together")
+				}
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return fmt.Errorf("failed to read commit message: %w", err)
+				}
+				message := conventional.Clean(string(data))
+				if conventional.IsExempt(message) {
+					return nil
+				}
+				problems := conventional.Lint(message, rules)
+				output.Set("problems", problemsJSON(problems))
+				if len(problems) > 0 {
+					reportProblems(output, problems)
+					return ui.NewError("commit message does not follow Conventional Commits", "edit the message and commit again, e.g.: feat(scope): add something")
+				}
+				output.Success("Commit message follows Conventional Commits")
+				return nil
+			}
+
+			// Default: the commits of this branch not yet on the integration environment
+			var revRange string
+			if len(args) > 0 {
+				revRange = args[0]
+			} else {
+				base := cfg.IntegrationEnvironment().Branch
+				if exists, err := repo.TrackingBranchExists(cfg.IntegrationRemote(), base); err == nil && exists {
+					base = fmt.Sprintf("%s/%s", cfg.IntegrationRemote(), base)
+				}
+				revRange = base + "..HEAD"
+			}
+
+			commits, err := g.GetCommits("--reverse", revRange)
+			if err != nil {
+				return gitFailure(fmt.Sprintf("failed to list commits in %s", revRange), err, "", nil)
+			}
+
+			results := []map[string]interface{}{}
+			invalid := 0
+			for _, commit := range commits {
+				message := commit.Subject
+				if commit.Body != "" {
+					message += "\n\n" + commit.Body
+				}
+				if commit.IsMerge() || conventional.IsExempt(message) {
+					continue
+				}
+				problems := conventional.Lint(message, rules)
+				results = append(results, map[string]interface{}{
+					"sha":      commit.SHA,
+					"subject":  commit.Subject,
+					"problems": problemsJSON(problems),
+				})
+				if len(problems) == 0 {
+					output.Verbose("%s %s", shortSHA(commit.SHA), commit.Subject)
+					continue
+				}
+				invalid++
+				output.Error("%s %s", shortSHA(commit.SHA), commit.Subject)
+				for _, problem := range problems {
+					output.Print("  - %s", problem)
+				}
+			}
+			output.Set("range", revRange)
+			output.Set("commits", results)
+
+			if invalid > 0 {
+				return ui.NewError(
+					fmt.Sprintf("%d of %d commit(s) in %s do not follow Conventional Commits", invalid, len(results), revRange),
+					rewordSuggestion(revRange),
+				)
+			}
+			output.Success("%d commit(s) in %s follow Conventional Commits", len(results), revRange)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Check the commit message in this file (e.g., .git/COMMIT_EDITMSG)")
+
+	return cmd
+}
+
+// reportProblems lists what is wrong with a commit message
+func reportProblems(output *ui.Output, problems []conventional.Problem) {
+	for _, problem := range problems {
+		output.Error("%s", problem)
+	}
+}
+
+// problemsJSON returns problems as an always non-nil list for JSON output
+func problemsJSON(problems []conventional.Problem) []conventional.Problem {
+	if problems == nil {
+		return []conventional.Problem{}
+	}
+	return problems
+}
+
+// rewordSuggestion tells how to fix commits in a range such as
+// origin/stage..HEAD
+func rewordSuggestion(revRange string) string {
+	if base, _, ok := strings.Cut(revRange, ".."); ok && base != "" {
+		return "reword them with: git rebase -i " + base
+	}
+	return "reword them with: git rebase -i"
+}
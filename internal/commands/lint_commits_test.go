@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintCommitsRange(t *testing.T) {
+	repo := newGitTestRepo(t)
+
+	// The branch's only commit, "KWS-1 add login", has no type
+	_, err := runGitext("lint-commits")
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 commit(s) in origin/stage..HEAD") {
+		t.Fatalf("expected the commit to be rejected, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "git rebase -i origin/stage") {
+		t.Errorf("expected a rebase suggestion, got %v", err)
+	}
+
+	repo.git(repo.dir, "commit", "-q", "-m", "feat(auth): add remember me\n\nRefs: KWS-1")
+	repo.git(repo.dir, "checkout", "-q", "-b", "docs")
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "docs: describe login")
+	repo.git(repo.dir, "checkout", "-q", "feature/KWS-1-login")
+	repo.git(repo.dir, "merge", "-q", "--no-ff", "-m", "Merge branch 'docs'", "docs")
+	if _, err := runGitext("lint-commits", "HEAD~2..HEAD"); err != nil {
+		t.Errorf("expected conventional and merge commits to pass, got %v", err)
+	}
+}
+
+func TestLintCommitsFile(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.writeFile(".gitext", "commits:\n  scopes: [auth]\n  requireTicket: true\n")
+	repo.writeFile(".git/info/exclude", ".gitext\n")
+	path := filepath.Join(repo.dir, ".git", "COMMIT_EDITMSG")
+
+	tests := []struct {
+		message string
+		valid   bool
+	}{
+		{"feat(auth): add login\n\nRefs: KWS-1\n# Please enter the commit message for your changes.\n", true},
+		{"feat(ui): add login\n\nRefs: KWS-1\n", false},
+		{"feat(auth): add login\n", false},
+		{"Merge branch 'stage' into feature/KWS-1-login\n", true},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(path, []byte(tt.message), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := runGitext("lint-commits", "--file", path)
+		if tt.valid && err != nil {
+			t.Errorf("%q: expected the message to pass, got %v", tt.message, err)
+		}
+		if !tt.valid && (err == nil || !strings.Contains(err.Error(), "does not follow Conventional Commits")) {
+			t.Errorf("%q: expected the message to be rejected, got %v", tt.message, err)
+		}
+	}
+}
+
+func TestInitInstallsCommitMsgHook(t *testing.T) {
+	repo := newGitTestRepo(t)
+
+	if _, err := runGitext("init", "--install-hooks"); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(repo.dir, ".git", "hooks", "commit-msg"))
+	if err != nil {
+		t.Fatalf("expected a commit-msg hook: %v", err)
+	}
+	if info.Mode()&0111 == 0 {
+		t.Error("expected the commit-msg hook to be executable")
+	}
+	hook, _ := os.ReadFile(filepath.Join(repo.dir, ".git", "hooks", "commit-msg"))
+	if !strings.Contains(string(hook), `gitext lint-commits --file "$1"`) {
+		t.Errorf("expected the hook to run lint-commits, got:\n%s", hook)
+	}
+}
+
+func TestCommitRejectsInvalidMessage(t *testing.T) {
+	repo := newGitTestRepo(t)
+	before := repo.state()
+
+	_, err := runGitext("commit", "-m", "KWS-1 add staged file")
+	if err == nil || !strings.Contains(err.Error(), "does not follow Conventional Commits") {
+		t.Fatalf("expected commit to reject the message, got %v", err)
+	}
+	if after := repo.state(); after != before {
+		t.Fatalf("rejected commit changed the repository\nbefore:\n%s\nafter:\n%s", before, after)
+	}
+}
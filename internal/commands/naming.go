@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/naming"
+	"github.com/imemir/gitext/pkg/ui"
+)
+
+// Branch types understood by start, prepare and finish
+const (
+	branchTypeFeature = "feature"
+	branchTypeHotfix  = "hotfix"
+)
+
+// matchesPattern reports whether branch matches a naming pattern such as "feature/*"
+func matchesPattern(pattern, branch string) (bool, error) {
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	matched, err := regexp.MatchString("^"+expr+"$", branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to validate branch name: %w", err)
+	}
+	return matched, nil
+}
+
+// namingPattern returns the configured naming pattern for a branch type
+func namingPattern(cfg *config.Config, branchType string) string {
+	if branchType == branchTypeHotfix {
+		return cfg.Naming.Hotfix
+	}
+	return cfg.Naming.Feature
+}
+
+// isHotfixBranch reports whether branch matches the configured hotfix pattern
+func isHotfixBranch(cfg *config.Config, branch string) bool {
+	matched, err := matchesPattern(cfg.Naming.Hotfix, branch)
+	return err == nil && matched
+}
+
+// generateBranchName renders the configured naming template for a new branch
+func generateBranchName(cfg *config.Config, g *git.Git, branchType, ticket, slug string) (string, error) {
+	tmpl, err := cfg.BranchTemplate(branchType)
+	if err != nil {
+		return "", err
+	}
+
+	values := naming.Values{
+		Type:   branchType,
+		Ticket: ticket,
+		Slug:   naming.NormalizeSlug(slug, cfg.SlugOptions()),
+		Date:   time.Now(),
+	}
+	if values.Slug == "" {
+		return "", fmt.Errorf("slug '%s' is empty after normalisation", slug)
+	}
+
+	if tmpl.Uses(naming.PlaceholderAuthor) {
+		author, err := g.GetConfigValue("user.name")
+		if err != nil {
+			return "", fmt.Errorf("failed to read user.name: %w", err)
+		}
+		if author == "" {
+			return "", ui.NewError("git user.name is not set", "run: git config user.name \"Your Name\"")
+		}
+		authorOpts := cfg.SlugOptions()
+		authorOpts.MaxLength = 0
+		values.Author = naming.NormalizeSlug(author, authorOpts)
+	}
+
+	return tmpl.Render(values)
+}
+
+// extractTicketFromBranch parses the ticket ID out of a branch name using the
+// configured naming templates (e.g., feature/KWS-123-slug -> KWS-123)
+func extractTicketFromBranch(cfg *config.Config, branch string) string {
+	for _, branchType := range []string{branchTypeFeature, branchTypeHotfix} {
+		tmpl, err := cfg.BranchTemplate(branchType)
+		if err != nil {
+			continue
+		}
+		if values, ok := tmpl.Extract(branch); ok && values.Ticket != "" {
+			return values.Ticket
+		}
+	}
+	return ""
+}
+
+// mergeSubjectRegexes extract the source branch from merge commit subjects
+var mergeSubjectRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`^Merge branch '([^']+)'`),
+	regexp.MustCompile(`^Merge pull request #\d+ from [^/\s]+/(\S+)`),
+	regexp.MustCompile(`^Merge remote-tracking branch '[^/']+/([^']+)'`),
+}
+
+// extractTicketFromCommit finds the ticket a commit belongs to: merge commits
+// are parsed through the branch they merged, other commits by their message
+func extractTicketFromCommit(cfg *config.Config, commit git.Commit) string {
+	for _, re := range mergeSubjectRegexes {
+		if match := re.FindStringSubmatch(commit.Subject); match != nil {
+			if ticket := extractTicketFromBranch(cfg, match[1]); ticket != "" {
+				return ticket
+			}
+		}
+	}
+	if ticket := naming.FindTicket(commit.Subject); ticket != "" {
+		return ticket
+	}
+	return naming.FindTicket(commit.Body)
+}
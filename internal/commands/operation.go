@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/journal"
+	"github.com/imemir/gitext/pkg/operation"
+)
+
+// operationPath returns where the state of a paused command is kept; it is
+// per worktree, like git's own rebase and merge state
+func operationPath(g *git.Git) (string, error) {
+	path, err := g.GetGitPath("gitext/operation.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate operation state: %w", err)
+	}
+	return path, nil
+}
+
+// newOperation describes a rebase or merge a command is about to run
+func newOperation(command, kind, branch, target, remote string, entry *journal.Entry) *operation.State {
+	state := &operation.State{
+		Command:   command,
+		Operation: kind,
+		Branch:    branch,
+		Target:    target,
+		Remote:    remote,
+	}
+	if entry != nil {
+		state.JournalID = entry.ID
+		state.Stash = entry.Stash
+	}
+	return state
+}
+
+// pauseOperation persists a command stopped by conflicts and tells the user
+// how to finish or roll it back
+func pauseOperation(ctx *Context, state *operation.State, runErr error) error {
+	output := ctx.Output
+
+	// Only conflicts leave something to continue; anything else failed outright
+	if git.KindOf(runErr) != git.ErrorConflict {
+		if inProgress, err := ctx.Git.GetInProgressOperation(); err != nil || inProgress == "" {
+			return gitFailure(fmt.Sprintf("%s failed", state.Operation), runErr, state.Remote, nil)
+		}
+	}
+
+	path, err := operationPath(ctx.Git)
+	if err == nil {
+		err = operation.Save(path, state)
+	}
+	if err != nil {
+		output.Warning("Failed to save operation state: %v", err)
+	} else if state.Stash != "" && ctx.stash == state.Stash {
+		// continue or abort reapplies the stash
+		ctx.stash = ""
+	}
+
+	if files, err := ctx.Git.GetConflictedFiles(); err == nil && len(files) > 0 {
+		output.Error("%s stopped on conflicts in %d file(s):", capitalize(state.Operation), len(files))
+		for _, file := range files {
+			output.Print("  - %s", file)
+		}
+	} else {
+		output.Error("%s encountered conflicts", capitalize(state.Operation))
+	}
+	output.Set("operation", state)
+	if state.Stash != "" {
+		output.Info("Your uncommitted changes stay stashed as %s; gitext continue or abort reapplies them", stashRef(ctx.Git, state.Stash))
+	}
+	output.Next("resolve conflicts and stage them with git add, then run: gitext continue")
+	output.Next("or restore the state before %s: gitext abort", state.Command)
+
+	return fmt.Errorf("%s failed: %w", state.Operation, runErr)
+}
+
+// finishOperation reports a completed rebase or merge: what was done,
+// whether a force push is needed and what to do next. Changes stashed by
+// --autostash are reapplied first.
+func finishOperation(ctx *Context, state *operation.State) error {
+	output, repo := ctx.Output, ctx.Repo
+
+	output.Did("%s", state.Summary)
+	if err := restoreAutostash(ctx, state.Stash); err != nil {
+		return err
+	}
+
+	if !ctx.DryRun {
+		if remoteExists, err := repo.TrackingBranchExists(state.Remote, state.Branch); err == nil && remoteExists {
+			if ahead, behind, err := repo.GetAheadBehind(state.Remote, state.Branch); err == nil {
+				output.Info("Ahead of %s/%s by %d, behind by %d commit(s)", state.Remote, state.Branch, ahead, behind)
+			}
+		}
+	}
+
+	if state.ForcePush {
+		output.Warning("Remote branch exists. gitext push will force push with a lease on the commit it had before")
+		output.Next("push with force: gitext push")
+	} else {
+		output.Next("push changes: gitext push")
+	}
+	return nil
+}
+
+// capitalize upper-cases the first letter of s, which may be multi-byte
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// conflictingUpdate commits changes to the same file on stage and on the
+// feature branch, so gitext update stops on a conflict
+func conflictingUpdate(t *testing.T, repo *gitTestRepo) {
+	t.Helper()
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-1 add staged file")
+	repo.git(repo.dir, "checkout", "-q", "stage")
+	repo.writeFile("shared.txt", "stage\n")
+	repo.git(repo.dir, "add", "shared.txt")
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-2 change shared file")
+	repo.git(repo.dir, "push", "-q", "origin", "stage")
+	repo.git(repo.dir, "checkout", "-q", "feature/KWS-1-login")
+	repo.writeFile("shared.txt", "feature\n")
+	repo.git(repo.dir, "add", "shared.txt")
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-1 change shared file")
+
+	// Leave local stage behind origin so update moves it too
+	repo.git(repo.dir, "branch", "-f", "stage", "production")
+}
+
+func TestAbortRestoresStateBeforeUpdate(t *testing.T) {
+	repo := newGitTestRepo(t)
+	conflictingUpdate(t, repo)
+	before := repo.state()
+
+	if _, err := runGitext("update", "feature", "--with", "stage"); err == nil {
+		t.Fatal("expected update to stop on conflicts")
+	}
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git", "rebase-merge")); err != nil {
+		t.Fatal("expected a rebase in progress")
+	}
+
+	if _, err := runGitext("status"); err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+
+	if _, err := runGitext("continue"); err == nil || !strings.Contains(err.Error(), "unresolved conflicts") {
+		t.Errorf("expected continue to refuse unresolved conflicts, got %v", err)
+	}
+
+	if _, err := runGitext("abort"); err != nil {
+		t.Fatalf("abort failed: %v", err)
+	}
+	if after := repo.state(); after != before {
+		t.Fatalf("abort did not restore the repository\nbefore:\n%s\nafter:\n%s", before, after)
+	}
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git", "gitext", "operation.json")); !os.IsNotExist(err) {
+		t.Error("expected operation state to be cleared")
+	}
+}
+
+func TestContinueFinishesUpdate(t *testing.T) {
+	repo := newGitTestRepo(t)
+	conflictingUpdate(t, repo)
+
+	if _, err := runGitext("update", "feature", "--with", "stage"); err == nil {
+		t.Fatal("expected update to stop on conflicts")
+	}
+
+	repo.writeFile("shared.txt", "stage and feature\n")
+	repo.git(repo.dir, "add", "shared.txt")
+
+	if _, err := runGitext("continue"); err != nil {
+		t.Fatalf("continue failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git", "rebase-merge")); !os.IsNotExist(err) {
+		t.Fatal("expected the rebase to be complete")
+	}
+	if branch := strings.TrimSpace(repo.git(repo.dir, "symbolic-ref", "--short", "HEAD")); branch != "feature/KWS-1-login" {
+		t.Errorf("expected to be back on the feature branch, got %s", branch)
+	}
+	repo.git(repo.dir, "merge-base", "--is-ancestor", "origin/stage", "HEAD")
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git", "gitext", "operation.json")); !os.IsNotExist(err) {
+		t.Error("expected operation state to be cleared")
+	}
+
+	if _, err := runGitext("continue"); err == nil || !strings.Contains(err.Error(), "no operation in progress") {
+		t.Errorf("expected no operation in progress, got %v", err)
+	}
+}
+
+func TestContinueAfterRawAbort(t *testing.T) {
+	repo := newGitTestRepo(t)
+	conflictingUpdate(t, repo)
+	head := repo.git(repo.dir, "rev-parse", "HEAD")
+
+	if _, err := runGitext("update", "feature", "--with", "stage"); err == nil {
+		t.Fatal("expected update to stop on conflicts")
+	}
+	repo.git(repo.dir, "rebase", "--abort")
+
+	if _, err := runGitext("continue"); err == nil || !strings.Contains(err.Error(), "aborted outside gitext") {
+		t.Fatalf("expected continue to report the abort, got %v", err)
+	}
+	if got := repo.git(repo.dir, "rev-parse", "HEAD"); got != head {
+		t.Errorf("expected the branch untouched, got %s", got)
+	}
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git", "gitext", "operation.json")); !os.IsNotExist(err) {
+		t.Error("expected operation state to be cleared")
+	}
+}
+
+// conflictingPromotion leaves a stage commit that conflicts with production
+func conflictingPromotion(t *testing.T, repo *gitTestRepo) {
+	t.Helper()
+	repo.git(repo.dir, "stash", "-q")
+	repo.git(repo.dir, "checkout", "-q", "production")
+	repo.writeFile("shared.txt", "production\n")
+	repo.git(repo.dir, "add", "shared.txt")
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-4 change shared file")
+	repo.git(repo.dir, "checkout", "-q", "stage")
+	repo.git(repo.dir, "pull", "-q", "--ff-only", "origin", "stage")
+	repo.writeFile("shared.txt", "stage\n")
+	repo.git(repo.dir, "add", "shared.txt")
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-5 change shared file")
+	repo.git(repo.dir, "push", "-q", "origin", "production", "stage")
+	repo.git(repo.dir, "checkout", "-q", "feature/KWS-1-login")
+}
+
+func TestContinueFinishesPromotion(t *testing.T) {
+	repo := newGitTestRepo(t)
+	conflictingPromotion(t, repo)
+
+	if _, err := runGitext("promote", "stage", "production", "--yes", "--exclude", "KWS-2", "--branch", "release/test"); err == nil {
+		t.Fatal("expected promote to stop on conflicts")
+	}
+
+	repo.writeFile("shared.txt", "stage and production\n")
+	repo.git(repo.dir, "add", "shared.txt")
+
+	if _, err := runGitext("continue"); err != nil {
+		t.Fatalf("continue failed: %v", err)
+	}
+	if branch := strings.TrimSpace(repo.git(repo.dir, "symbolic-ref", "--short", "HEAD")); branch != "release/test" {
+		t.Errorf("expected to stay on the release branch, got %s", branch)
+	}
+	if body := repo.git(repo.dir, "log", "-1", "--format=%B"); !strings.Contains(body, "KWS-5") || !strings.Contains(body, "cherry picked from") {
+		t.Errorf("expected the KWS-5 commit to be picked, got %q", body)
+	}
+	if _, err := os.Stat(filepath.Join(repo.dir, ".git", "gitext", "operation.json")); !os.IsNotExist(err) {
+		t.Error("expected operation state to be cleared")
+	}
+}
+
+func TestAbortDeletesReleaseBranch(t *testing.T) {
+	repo := newGitTestRepo(t)
+	conflictingPromotion(t, repo)
+	before := repo.state()
+
+	if _, err := runGitext("promote", "stage", "production", "--yes", "--exclude", "KWS-2", "--branch", "release/test"); err == nil {
+		t.Fatal("expected promote to stop on conflicts")
+	}
+	if _, err := runGitext("abort"); err != nil {
+		t.Fatalf("abort failed: %v", err)
+	}
+	if after := repo.state(); after != before {
+		t.Errorf("abort did not restore the repository\nbefore:\n%s\nafter:\n%s", before, after)
+	}
+}
+
+func TestCapitalize(t *testing.T) {
+	tests := map[string]string{
+		"":           "",
+		"rebase":     "Rebase",
+		"élan vital": "Élan vital",
+		"über fix":   "Über fix",
+		"日本":         "日本",
+	}
+	for in, want := range tests {
+		if got := capitalize(in); got != want {
+			t.Errorf("capitalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,9 +1,33 @@
 package commands
 
-// Options holds global options for commands
+import (
+	"github.com/imemir/gitext/pkg/ui"
+)
+
+// Options holds the global flags. The root command binds its persistent
+// flags directly to these fields, so they hold the parsed values by the
+// time any command runs.
 type Options struct {
 	DryRun  bool
 	Verbose bool
+	Offline bool
+	Format  string // --output: text or json
 	Version string
+
+	ctx *Context
 }
 
+// Context returns the context of the command being run. It is built in the
+// root command's PersistentPreRunE; commands run without it (e.g., when
+// executed directly in tests) get a text-mode context from the current flags.
+func (o *Options) Context() *Context {
+	if o.ctx == nil {
+		o.ctx = newContext(o)
+	}
+	return o.ctx
+}
+
+// Output returns the output of the command being run
+func (o *Options) Output() *ui.Output {
+	return o.Context().Output
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/ui"
+)
+
+// checkShared refuses to rewrite a pushed branch someone else worked on,
+// unless force is set. Only the commits the branch adds on top of base
+// count, so commits of the environment below the fork point are ignored.
+func checkShared(ctx *Context, remote, branch, base string, force bool) error {
+	output, g := ctx.Output, ctx.Git
+
+	self, err := g.GetUserEmails()
+	if err != nil {
+		return fmt.Errorf("failed to get user identity: %w", err)
+	}
+	commits, err := ctx.Repo.GetUniqueCommits(base)
+	if err != nil {
+		return fmt.Errorf("failed to list commits of %s: %w", branch, err)
+	}
+	updates, err := g.GetRefLog(fmt.Sprintf("refs/remotes/%s/%s", remote, branch))
+	if err != nil {
+		return fmt.Errorf("failed to read the reflog of %s/%s: %w", remote, branch, err)
+	}
+
+	ownership := git.AnalyzeOwnership(self, commits, updates)
+	if !ownership.Shared() {
+		return nil
+	}
+
+	output.Set("contributors", ownership.Contributors)
+	report := output.Error
+	if force {
+		report = output.Warning
+	}
+	report("Branch '%s' appears to be shared; other contributors:", branch)
+	for _, c := range ownership.Contributors {
+		output.Print("  - %s <%s>: %d commit(s), %d push(es)", c.Name, c.Email, c.Commits, c.Pushes)
+	}
+
+	if !force {
+		output.Warning("Rewriting its history may affect other developers")
+		return ui.NewError("branch appears shared", "coordinate with the contributors, then use --i-know-what-im-doing to proceed")
+	}
+	output.Warning("Branch appears shared, proceeding with --i-know-what-im-doing flag")
+	return nil
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpdateRefusesToRebaseSharedBranch(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+
+	// Someone else adds a commit to the pushed feature branch
+	t.Setenv("GIT_AUTHOR_NAME", "Bob")
+	t.Setenv("GIT_AUTHOR_EMAIL", "bob@example.com")
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "KWS-1 bob's change")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	repo.git(repo.dir, "push", "-q", "origin", "feature/KWS-1-login")
+
+	before := repo.state()
+	_, err := runGitext("update", "feature", "--with", "stage")
+	if err == nil || !strings.Contains(err.Error(), "shared") {
+		t.Fatalf("expected the branch to be reported shared, got %v", err)
+	}
+	if repo.state() != before {
+		t.Fatal("update changed the repository of a shared branch")
+	}
+
+	if _, err := runGitext("update", "feature", "--with", "stage", "--mode", "merge"); err != nil {
+		t.Errorf("expected merge to be allowed on a shared branch, got %v", err)
+	}
+}
+
+func TestUpdateIgnoresCommitsBelowForkPoint(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+
+	// Others commit to stage; a new feature branch starts on top of it
+	t.Setenv("GIT_AUTHOR_NAME", "Bob")
+	t.Setenv("GIT_AUTHOR_EMAIL", "bob@example.com")
+	repo.git(repo.dir, "checkout", "-q", "stage")
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "KWS-7 bob's stage change")
+	repo.git(repo.dir, "push", "-q", "origin", "stage")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	repo.git(repo.dir, "checkout", "-q", "-b", "feature/KWS-8-mine")
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "KWS-8 my change")
+	repo.git(repo.dir, "push", "-q", "origin", "feature/KWS-8-mine")
+
+	if _, err := runGitext("update", "feature", "--with", "stage"); err != nil {
+		t.Errorf("expected a branch only the user worked on not to be shared, got %v", err)
+	}
+}
@@ -3,86 +3,84 @@ package commands
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/forge"
 	"github.com/imemir/gitext/pkg/git"
 	"github.com/imemir/gitext/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
 func NewPrepareCmd(opts *Options) *cobra.Command {
-	var to string
+	var to, title string
+	var create, noCache bool
+	var labels, reviewers []string
 
 	cmd := &cobra.Command{
 		Use:   "prepare pr",
 		Short: "Prepare a pull request",
 		Long: `Run CI checks and generate PR text for the current branch.
-CI commands are run based on the target branch (stage or production).`,
+CI commands are run based on the target environment (e.g., stage or production).
+Hotfix branches always target production.
+
+With --create, the branch is pushed to the push remote and the PR is opened on
+GitHub, GitLab or Gitea (detected from the integration remote URL) with the
+generated text as its body. A branch pushed to a fork is opened as owner:branch.
+The API token is read from GITHUB_TOKEN, GITLAB_TOKEN or GITEA_TOKEN, or the
+variable named by forge.tokenEnv in .gitext.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if args[0] != "pr" {
 				return fmt.Errorf("only 'pr' is supported")
 			}
 
-			output := ui.NewOutput(opts.Verbose)
-			g := git.NewGit(opts.DryRun, opts.Verbose)
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
 
-			if err := g.ValidateGitRepo(); err != nil {
-				return ui.NewError("not in a git repository", "run this command from within a git repository")
-			}
-
-			cfg, err := config.Load()
+			cfg, err := ctx.RequireConfig()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return err
 			}
 
-			// Validate flags
-			if to == "" {
-				return fmt.Errorf("--to is required (stage or production)")
+			// Get current branch
+			currentBranch, err := repo.GetCurrentBranch()
+			if err != nil {
+				return fmt.Errorf("failed to get current branch: %w", err)
 			}
 
-			var targetBranch string
-			switch to {
-			case "stage":
-				targetBranch = cfg.Branch.Stage
-			case "production":
-				targetBranch = cfg.Branch.Production
-			default:
-				return fmt.Errorf("--to must be 'stage' or 'production'")
+			// Hotfixes land on production and are back-merged afterwards
+			production := cfg.ProductionEnvironment()
+			hotfix := isHotfixBranch(cfg, currentBranch)
+			if hotfix {
+				if to == "" {
+					to = production.Name
+				}
+				if to != production.Name {
+					return ui.NewError(
+						fmt.Sprintf("hotfix branch '%s' must target %s", currentBranch, production.Name),
+						fmt.Sprintf("run: gitext prepare pr --to %s, then gitext finish hotfix to back-merge it", production.Name),
+					)
+				}
 			}
 
-			// Get current branch
-			currentBranch, err := g.GetCurrentBranch()
+			// Validate flags
+			targetEnv, err := resolveEnvironment(cfg, to, "--to")
 			if err != nil {
-				return fmt.Errorf("failed to get current branch: %w", err)
+				return err
 			}
-
-			// Get CI commands for target
-			var ciCommands []string
-			if to == "stage" {
-				ciCommands = cfg.CI.Stage
-			} else {
-				ciCommands = cfg.CI.Production
+			if create {
+				// Refuse before running CI, not after
+				if err := checkPushable(cfg, currentBranch); err != nil {
+					return err
+				}
 			}
+			targetBranch := targetEnv.Branch
 
 			// Run CI commands
-			if len(ciCommands) > 0 {
-				output.Doing("Running CI checks for %s", to)
-				for _, cmdStr := range ciCommands {
-					output.Verbose("Running: %s", cmdStr)
-					if !opts.DryRun {
-						if err := runCICommand(cmdStr); err != nil {
-							output.Error("CI check failed: %s", cmdStr)
-							return fmt.Errorf("CI check failed: %w", err)
-						}
-					}
-				}
-				output.Did("All CI checks passed")
-			} else {
-				output.Info("No CI commands configured for %s", to)
+			if err := runCIChecks(ctx, targetEnv, noCache); err != nil {
+				return err
 			}
 
 			// Generate PR text
@@ -91,30 +89,140 @@ CI commands are run based on the target branch (stage or production).`,
 			prText := generatePRText(cfg, currentBranch, targetBranch, g, output)
 
 			// Print PR text to stdout
-			output.Print("\n" + prText + "\n")
+			output.Print("\n%s\n", prText)
+			output.Set("branch", currentBranch)
+			output.Set("target", targetBranch)
+			output.Set("prText", prText)
 
 			output.Did("PR text generated")
-			output.Next("create PR on GitHub/GitLab or copy the text above")
+
+			if create {
+				if title == "" {
+					title = defaultPRTitle(cfg, g, currentBranch, targetBranch)
+				}
+				pr := &forge.PullRequest{
+					Title:     title,
+					Body:      prText,
+					Head:      currentBranch,
+					Base:      targetBranch,
+					Labels:    append(append([]string{}, cfg.Forge.Labels...), labels...),
+					Reviewers: append(append([]string{}, cfg.Forge.Reviewers...), reviewers...),
+				}
+				if err := createPullRequest(ctx, cfg, pr); err != nil {
+					return err
+				}
+			} else {
+				output.Next("create PR on GitHub/GitLab or copy the text above (or rerun with --create)")
+			}
+			if backmerge := backmergeEnvironment(cfg); hotfix && backmerge != nil {
+				output.Next("after the PR is merged, back-merge into %s: gitext finish hotfix", backmerge.Name)
+			}
 
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&to, "to", "", "Target branch for PR (stage or production)")
+	cmd.Flags().StringVar(&to, "to", "", "Target environment for PR (e.g., stage or production, defaults to production for hotfixes)")
+	cmd.Flags().BoolVar(&create, "create", false, "Push the branch and open the PR on the forge")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Rerun CI steps that already passed for the current tree")
+	cmd.Flags().StringVar(&title, "title", "", "PR title (default: derived from the commits or branch name)")
+	cmd.Flags().StringSliceVar(&labels, "label", nil, "Labels to add to the PR (in addition to forge.labels)")
+	cmd.Flags().StringSliceVar(&reviewers, "reviewer", nil, "Reviewers to request (in addition to forge.reviewers)")
 
 	return cmd
 }
 
-func runCICommand(cmdStr string) error {
-	parts := strings.Fields(cmdStr)
-	if len(parts) == 0 {
-		return fmt.Errorf("empty command")
+// createPullRequest pushes the PR head to the push remote and opens the PR
+// on the forge of the integration remote
+func createPullRequest(ctx *Context, cfg *config.Config, pr *forge.PullRequest) error {
+	output, g := ctx.Output, ctx.Git
+	remote, pushRemote := cfg.IntegrationRemote(), cfg.PushRemote()
+
+	repo, err := forgeRepository(g, remote)
+	if err != nil {
+		return err
+	}
+	if cfg.IsFork() {
+		fork, err := forgeRepository(g, pushRemote)
+		if err != nil {
+			return err
+		}
+		pr.HeadRepo = fork
+	}
+	forgeType := cfg.Forge.Type
+	if forgeType == "" {
+		forgeType = forge.DetectType(repo.Host)
+	}
+	client, err := forge.New(repo, forge.Options{
+		Type:    forgeType,
+		BaseURL: cfg.Forge.BaseURL,
+		Token:   forge.TokenFromEnv(forgeType, cfg.Forge.TokenEnv),
+	})
+	if err != nil {
+		return ui.NewError(err.Error(), "configure the forge section in .gitext or export the API token")
+	}
+
+	if err := pushBranch(ctx, cfg, pr.Head); err != nil {
+		return err
+	}
+
+	if ctx.DryRun {
+		ctx.Simulate("open %s PR '%s' from %s into %s", client.Name(), pr.Title, pr.HeadRef(), pr.Base)
+		return nil
+	}
+
+	output.Doing("Opening PR on %s", client.Name())
+	created, err := client.CreatePullRequest(pr)
+	if err != nil {
+		if created != nil {
+			output.Warning("%v", err)
+		} else {
+			return fmt.Errorf("failed to create PR: %w", err)
+		}
+	}
+	output.Did("Opened PR #%d: %s", created.Number, created.URL)
+	output.Set("pullRequest", map[string]interface{}{"number": created.Number, "url": created.URL})
+	output.Next("review the PR at %s", created.URL)
+	return nil
+}
+
+// forgeRepository identifies the forge repository a remote points to
+func forgeRepository(g *git.Git, remote string) (*forge.Repository, error) {
+	remoteURL, err := g.GetRemoteURL(remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URL of remote '%s': %w", remote, err)
 	}
 
-	cmd := exec.Command(parts[0], parts[1:]...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	repo, err := forge.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, ui.NewError(err.Error(), fmt.Sprintf("point remote '%s' at a repository hosted on GitHub, GitLab or Gitea", remote))
+	}
+	return repo, nil
+}
+
+// defaultPRTitle uses the subject of a single-commit branch, otherwise the
+// ticket and slug parsed from the branch name
+func defaultPRTitle(cfg *config.Config, g *git.Git, currentBranch, targetBranch string) string {
+	commits, err := g.GetCommits(fmt.Sprintf("%s/%s..%s", cfg.IntegrationRemote(), targetBranch, currentBranch))
+	if err == nil && len(commits) == 1 {
+		return commits[0].Subject
+	}
+
+	for _, branchType := range []string{branchTypeFeature, branchTypeHotfix} {
+		tmpl, err := cfg.BranchTemplate(branchType)
+		if err != nil {
+			continue
+		}
+		if values, ok := tmpl.Extract(currentBranch); ok && values.Slug != "" {
+			title := strings.ReplaceAll(values.Slug, "-", " ")
+			title = capitalize(title)
+			if values.Ticket != "" {
+				return fmt.Sprintf("%s: %s", values.Ticket, title)
+			}
+			return title
+		}
+	}
+	return currentBranch
 }
 
 func generatePRText(cfg *config.Config, currentBranch, targetBranch string, g *git.Git, output *ui.Output) string {
@@ -133,7 +241,7 @@ func generatePRText(cfg *config.Config, currentBranch, targetBranch string, g *g
 	}
 
 	// Extract ticket from branch name if possible
-	ticket := extractTicketFromBranch(currentBranch)
+	ticket := extractTicketFromBranch(cfg, currentBranch)
 
 	// Add branch info
 	prText.WriteString(fmt.Sprintf("## Branch: %s\n\n", currentBranch))
@@ -141,9 +249,12 @@ func generatePRText(cfg *config.Config, currentBranch, targetBranch string, g *g
 		prText.WriteString(fmt.Sprintf("**Ticket:** %s\n\n", ticket))
 	}
 	prText.WriteString(fmt.Sprintf("**Target:** %s\n\n", targetBranch))
+	if backmerge := backmergeEnvironment(cfg); isHotfixBranch(cfg, currentBranch) && backmerge != nil {
+		prText.WriteString(fmt.Sprintf("**Hotfix:** must be back-merged into %s after merge (`gitext finish hotfix`)\n\n", backmerge.Branch))
+	}
 
 	// Get commit summary
-	commits, err := getCommitSummary(cfg.Remote.Name, targetBranch, g)
+	commits, err := getCommitSummary(cfg.IntegrationRemote(), targetBranch, g)
 	if err == nil && commits != "" {
 		prText.WriteString("## Commits\n\n")
 		prText.WriteString(commits)
@@ -157,21 +268,6 @@ func generatePRText(cfg *config.Config, currentBranch, targetBranch string, g *g
 	return prText.String()
 }
 
-func extractTicketFromBranch(branch string) string {
-	// Try to extract ticket ID from branch name (e.g., feature/KWS-123-slug -> KWS-123)
-	parts := strings.Split(branch, "/")
-	if len(parts) > 1 {
-		nameParts := strings.Split(parts[1], "-")
-		if len(nameParts) >= 2 {
-			// Check if it looks like a ticket ID (e.g., KWS-123)
-			if len(nameParts[0]) >= 2 && len(nameParts[1]) >= 1 {
-				return fmt.Sprintf("%s-%s", nameParts[0], nameParts[1])
-			}
-		}
-	}
-	return ""
-}
-
 func getCommitSummary(remote, targetBranch string, g *git.Git) (string, error) {
 	currentBranch, err := g.GetCurrentBranch()
 	if err != nil {
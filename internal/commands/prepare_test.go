@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrepareHotfixTargetsProduction(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+	repo.git(repo.dir, "checkout", "-q", "hotfix/KWS-3-fix")
+
+	if _, err := runGitext("prepare", "pr", "--to", "stage"); err == nil || !strings.Contains(err.Error(), "must target production") {
+		t.Fatalf("expected a hotfix PR into stage to be refused, got %v", err)
+	}
+	if _, err := runGitext("prepare", "pr"); err != nil {
+		t.Fatalf("expected a hotfix PR to default to production: %v", err)
+	}
+}
@@ -0,0 +1,376 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/operation"
+	"github.com/imemir/gitext/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// noTicket groups commits that do not reference any ticket
+const noTicket = "(no ticket)"
+
+// cherryPickedRegex matches the trailer added by 'git cherry-pick -x'
+var cherryPickedRegex = regexp.MustCompile(`\(cherry picked from commit ([0-9a-f]{40})\)`)
+
+// pendingCommit is a commit missing from the target environment
+type pendingCommit struct {
+	git.Commit
+	Ticket string
+}
+
+// ticketGroup is the set of pending commits belonging to one ticket
+type ticketGroup struct {
+	Ticket  string
+	Commits []pendingCommit
+}
+
+func NewPromoteCmd(opts *Options) *cobra.Command {
+	var exclude []string
+	var branch string
+	var list, yes, noCache bool
+
+	cmd := &cobra.Command{
+		Use:   "promote <from> <to>",
+		Short: "Promote approved changes from one environment to the next",
+		Long: `Promote changes from one environment to another (e.g., stage to production).
+Lists the commits on <from> that are missing from <to> grouped by ticket,
+creates a release branch from <to>, cherry-picks the approved commits onto it,
+runs the CI checks of <to> and generates PR text.
+
+Use --exclude to leave tickets out of the release.
+
+If a cherry-pick stops on conflicts, resolve them and run gitext continue to
+finish the promotion, or gitext abort to return to the branch you were on
+and delete the release branch.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
+
+			cfg, err := ctx.RequireConfig()
+			if err != nil {
+				return err
+			}
+
+			fromEnv, err := resolveEnvironment(cfg, args[0], "source environment")
+			if err != nil {
+				return err
+			}
+			toEnv, err := resolveEnvironment(cfg, args[1], "target environment")
+			if err != nil {
+				return err
+			}
+
+			// Only promote along the configured chain
+			sources := cfg.PromotionSources(toEnv.Name)
+			if !containsString(sources, fromEnv.Name) {
+				suggestion := fmt.Sprintf("%s has no promotion sources configured", toEnv.Name)
+				if len(sources) > 0 {
+					suggestion = fmt.Sprintf("promote into %s from: %s", toEnv.Name, strings.Join(sources, ", "))
+				}
+				return ui.NewError(fmt.Sprintf("%s cannot be promoted into %s", fromEnv.Name, toEnv.Name), suggestion)
+			}
+
+			// Validate remote
+			remote := cfg.IntegrationRemote()
+			if err := g.ValidateRemote(remote); err != nil {
+				return err
+			}
+
+			// Check working tree
+			isClean, err := repo.IsWorkingTreeClean()
+			if err != nil {
+				return fmt.Errorf("failed to check working tree: %w", err)
+			}
+			if !isClean {
+				return ui.NewError("working tree has uncommitted changes", "commit or stash changes first")
+			}
+
+			// Fetch latest
+			if _, err := fetchRemote(ctx, cfg); err != nil {
+				return gitFailure("failed to fetch", err, remote, nil)
+			}
+
+			fromRef := fmt.Sprintf("%s/%s", remote, fromEnv.Branch)
+			toRef := fmt.Sprintf("%s/%s", remote, toEnv.Branch)
+
+			pending, err := pendingPromotions(cfg, g, fromRef, toRef)
+			if err != nil {
+				return fmt.Errorf("failed to compute pending changes: %w", err)
+			}
+			if len(pending) == 0 {
+				output.Success("%s has no changes missing from %s", fromEnv.Name, toEnv.Name)
+				return nil
+			}
+
+			// Split approved and excluded tickets
+			excluded := make(map[string]bool)
+			for _, ticket := range exclude {
+				excluded[strings.ToUpper(strings.TrimSpace(ticket))] = true
+			}
+			groups := groupByTicket(pending)
+			var approved []pendingCommit
+			var approvedGroups, skippedGroups []ticketGroup
+			for _, group := range groups {
+				if excluded[strings.ToUpper(group.Ticket)] {
+					skippedGroups = append(skippedGroups, group)
+				} else {
+					approvedGroups = append(approvedGroups, group)
+				}
+			}
+			for _, commit := range pending {
+				if !excluded[strings.ToUpper(commit.Ticket)] {
+					approved = append(approved, commit)
+				}
+			}
+			for _, ticket := range exclude {
+				if !hasTicket(groups, ticket) {
+					output.Warning("Excluded ticket %s has no pending changes", ticket)
+				}
+			}
+
+			output.Info("Changes on %s missing from %s:", fromEnv.Name, toEnv.Name)
+			for _, group := range groups {
+				marker := "+"
+				if excluded[strings.ToUpper(group.Ticket)] {
+					marker = "-"
+				}
+				output.Print("  %s %s (%d commit(s))", marker, group.Ticket, len(group.Commits))
+				for _, commit := range group.Commits {
+					output.Verbose("      %s %s", shortSHA(commit.SHA), commit.Subject)
+				}
+			}
+
+			output.Set("tickets", ticketsJSON(groups, excluded))
+
+			if list {
+				output.Next("promote with: gitext promote %s %s [--exclude <ticket>]", fromEnv.Name, toEnv.Name)
+				return nil
+			}
+			if len(approved) == 0 {
+				output.Info("All pending tickets are excluded, nothing to promote")
+				return nil
+			}
+
+			// Confirm promotion
+			if !ctx.DryRun && !yes {
+				confirmed, err := ui.PromptConfirm(fmt.Sprintf("Promote %d ticket(s) into %s?", len(approvedGroups), toEnv.Name), true)
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					output.Info("Promotion cancelled")
+					return nil
+				}
+			}
+
+			// Create release branch from the target environment
+			if branch == "" {
+				branch = fmt.Sprintf("%s%s-%s", config.DefaultReleasePrefix, toEnv.Name, time.Now().Format("20060102"))
+			}
+			exists, err := repo.BranchExists(branch)
+			if err != nil {
+				return fmt.Errorf("failed to check if branch exists: %w", err)
+			}
+			if exists {
+				return ui.NewError(fmt.Sprintf("branch '%s' already exists", branch), "pass --branch <name> to use a different release branch")
+			}
+
+			// Recorded so abort and undo delete the release branch again
+			entry, err := recordJournal(ctx, commandLine(cmd, args), branch)
+			if err != nil {
+				return err
+			}
+
+			output.Doing("Creating branch %s from %s", branch, toRef)
+			if _, err := g.RunWithTimeout("checkout", "-b", branch, toRef); err != nil {
+				return gitFailure("failed to create branch", err, remote, nil)
+			}
+
+			// Cherry-pick approved commits, oldest first; merges are picked
+			// against their first parent so each merged PR lands as one commit
+			cherryPickArgs := []string{"cherry-pick", "-x"}
+			for _, commit := range approved {
+				if commit.IsMerge() {
+					cherryPickArgs = append(cherryPickArgs, "-m", "1")
+					break
+				}
+			}
+			for _, commit := range approved {
+				cherryPickArgs = append(cherryPickArgs, commit.SHA)
+			}
+
+			state := newOperation(commandLine(cmd, args), git.OperationCherryPick, branch, toRef, remote, entry)
+			state.Summary = fmt.Sprintf("Cherry-picked approved changes onto %s", branch)
+			state.Promotion = &operation.Promotion{
+				Environment: toEnv.Name,
+				NoCache:     noCache,
+				PRText:      generatePromotionText(fromEnv, toEnv, branch, approvedGroups, skippedGroups),
+			}
+
+			output.Doing("Cherry-picking %d commit(s) onto %s", len(approved), branch)
+			if _, err := g.RunWithTimeout(cherryPickArgs...); err != nil {
+				return pauseOperation(ctx, state, err)
+			}
+			output.Did("%s", state.Summary)
+
+			return finishPromotion(ctx, toEnv, state)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "Tickets to leave out of the promotion (e.g., KWS-123,KWS-456)")
+	cmd.Flags().StringVar(&branch, "branch", "", "Release branch name (default: release/<to>-<date>)")
+	cmd.Flags().BoolVar(&list, "list", false, "Only list pending tickets, do not create a release branch")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Rerun CI steps that already passed for the current tree")
+
+	return cmd
+}
+
+// finishPromotion runs the CI checks of the target environment on the
+// release branch and prints the PR text, once the cherry-picks are done
+func finishPromotion(ctx *Context, toEnv *config.Environment, state *operation.State) error {
+	output := ctx.Output
+
+	if err := runCIChecks(ctx, toEnv, state.Promotion.NoCache); err != nil {
+		return err
+	}
+
+	output.Doing("Generating PR text")
+	output.Print("\n%s\n", state.Promotion.PRText)
+	output.Set("branch", state.Branch)
+	output.Set("prText", state.Promotion.PRText)
+	output.Did("PR text generated")
+
+	output.Next("push and open a PR into %s: gitext push", toEnv.Branch)
+	return nil
+}
+
+// pendingPromotions returns the first-parent commits on fromRef missing from
+// toRef, oldest first, with the ticket each one belongs to
+func pendingPromotions(cfg *config.Config, g *git.Git, fromRef, toRef string) ([]pendingCommit, error) {
+	commits, err := g.GetCommits("--first-parent", "--reverse", fmt.Sprintf("%s..%s", toRef, fromRef))
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip commits already promoted by an earlier 'cherry-pick -x'
+	promoted := make(map[string]bool)
+	targetOnly, err := g.GetCommits(fmt.Sprintf("%s..%s", fromRef, toRef))
+	if err != nil {
+		return nil, err
+	}
+	for _, commit := range targetOnly {
+		for _, match := range cherryPickedRegex.FindAllStringSubmatch(commit.Body, -1) {
+			promoted[match[1]] = true
+		}
+	}
+
+	var pending []pendingCommit
+	for _, commit := range commits {
+		if promoted[commit.SHA] {
+			continue
+		}
+		ticket := extractTicketFromCommit(cfg, commit)
+		if ticket == "" {
+			ticket = noTicket
+		}
+		pending = append(pending, pendingCommit{Commit: commit, Ticket: ticket})
+	}
+
+	return pending, nil
+}
+
+// groupByTicket groups commits by ticket in the order tickets first appear
+func groupByTicket(commits []pendingCommit) []ticketGroup {
+	var groups []ticketGroup
+	index := make(map[string]int)
+	for _, commit := range commits {
+		i, ok := index[commit.Ticket]
+		if !ok {
+			i = len(groups)
+			index[commit.Ticket] = i
+			groups = append(groups, ticketGroup{Ticket: commit.Ticket})
+		}
+		groups[i].Commits = append(groups[i].Commits, commit)
+	}
+	return groups
+}
+
+// ticketsJSON renders ticket groups for --output json
+func ticketsJSON(groups []ticketGroup, excluded map[string]bool) []map[string]interface{} {
+	tickets := []map[string]interface{}{}
+	for _, group := range groups {
+		var commits []map[string]string
+		for _, commit := range group.Commits {
+			commits = append(commits, map[string]string{"sha": commit.SHA, "subject": commit.Subject})
+		}
+		tickets = append(tickets, map[string]interface{}{
+			"ticket":   group.Ticket,
+			"excluded": excluded[strings.ToUpper(group.Ticket)],
+			"commits":  commits,
+		})
+	}
+	return tickets
+}
+
+// hasTicket reports whether any group belongs to ticket (case-insensitive)
+func hasTicket(groups []ticketGroup, ticket string) bool {
+	for _, group := range groups {
+		if strings.EqualFold(group.Ticket, strings.TrimSpace(ticket)) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortSHA abbreviates a commit hash for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// generatePromotionText builds the PR text for a promotion
+func generatePromotionText(fromEnv, toEnv *config.Environment, branch string, approved, skipped []ticketGroup) string {
+	var text strings.Builder
+
+	text.WriteString(fmt.Sprintf("## Promotion: %s → %s\n\n", fromEnv.Name, toEnv.Name))
+	text.WriteString(fmt.Sprintf("**Branch:** %s\n\n", branch))
+	text.WriteString(fmt.Sprintf("**Target:** %s\n\n", toEnv.Branch))
+
+	text.WriteString("## Tickets\n\n")
+	for _, group := range approved {
+		text.WriteString(fmt.Sprintf("- %s\n", group.Ticket))
+		for _, commit := range group.Commits {
+			text.WriteString(fmt.Sprintf("  - %s %s\n", shortSHA(commit.SHA), commit.Subject))
+		}
+	}
+
+	if len(skipped) > 0 {
+		text.WriteString("\n## Excluded\n\n")
+		for _, group := range skipped {
+			text.WriteString(fmt.Sprintf("- %s (%d commit(s))\n", group.Ticket, len(group.Commits)))
+		}
+	}
+
+	return text.String()
+}
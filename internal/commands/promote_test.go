@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imemir/gitext/pkg/git"
+)
+
+// commitFile commits a file on the current branch of the test repository
+func commitFile(repo *gitTestRepo, name, subject string) {
+	repo.t.Helper()
+	repo.writeFile(name, subject+"\n")
+	repo.git(repo.dir, "add", name)
+	repo.git(repo.dir, "commit", "-q", "-m", subject)
+}
+
+func TestPromoteCherryPicksApprovedTickets(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+	repo.git(repo.dir, "checkout", "-q", "stage")
+	repo.git(repo.dir, "pull", "-q", "--ff-only", "origin", "stage")
+
+	// KWS-6 was promoted earlier with cherry-pick -x
+	commitFile(repo, "promoted.txt", "KWS-6 already promoted")
+	promoted := strings.TrimSpace(repo.git(repo.dir, "rev-parse", "HEAD"))
+	commitFile(repo, "search.txt", "KWS-7 add search")
+
+	// A merged PR lands as its merge commit, not the commits behind it
+	repo.git(repo.dir, "checkout", "-q", "-b", "feature/KWS-8-export")
+	commitFile(repo, "export.txt", "KWS-8 add export")
+	commitFile(repo, "export.csv", "KWS-8 add csv export")
+	repo.git(repo.dir, "checkout", "-q", "stage")
+	repo.git(repo.dir, "merge", "-q", "--no-ff", "-m", "Merge branch 'feature/KWS-8-export' into stage", "feature/KWS-8-export")
+	commitFile(repo, "search.css", "KWS-7 style search")
+
+	repo.git(repo.dir, "checkout", "-q", "production")
+	repo.git(repo.dir, "cherry-pick", "-x", promoted)
+	repo.git(repo.dir, "push", "-q", "origin", "production", "stage")
+	repo.git(repo.dir, "checkout", "-q", "feature/KWS-1-login")
+
+	// KWS-2 is the empty "other change" already on stage
+	if _, err := runGitext("promote", "stage", "production", "--yes", "--exclude", "KWS-2"); err != nil {
+		t.Fatalf("promote failed: %v", err)
+	}
+
+	release := "release/production-" + time.Now().Format("20060102")
+	if branch := strings.TrimSpace(repo.git(repo.dir, "symbolic-ref", "--short", "HEAD")); branch != release {
+		t.Fatalf("expected to be on %s, got %s", release, branch)
+	}
+	landed := strings.Split(strings.TrimSpace(repo.git(repo.dir, "log", "--reverse", "--format=%s", "origin/production.."+release)), "\n")
+	want := []string{
+		"KWS-7 add search",
+		"Merge branch 'feature/KWS-8-export' into stage",
+		"KWS-7 style search",
+	}
+	if !reflect.DeepEqual(landed, want) {
+		t.Errorf("release branch has\n%q\nwant\n%q", landed, want)
+	}
+	if files := repo.git(repo.dir, "ls-tree", "--name-only", release); !strings.Contains(files, "export.csv") {
+		t.Errorf("expected the merged PR's changes on the release branch, got\n%s", files)
+	}
+}
+
+func TestGroupByTicket(t *testing.T) {
+	commit := func(sha, ticket string) pendingCommit {
+		return pendingCommit{Commit: git.Commit{SHA: sha}, Ticket: ticket}
+	}
+	groups := groupByTicket([]pendingCommit{
+		commit("a", "KWS-7"),
+		commit("b", "KWS-8"),
+		commit("c", noTicket),
+		commit("d", "KWS-7"),
+	})
+
+	var got []string
+	for _, group := range groups {
+		var shas []string
+		for _, c := range group.Commits {
+			shas = append(shas, c.SHA)
+		}
+		got = append(got, group.Ticket+":"+strings.Join(shas, ","))
+	}
+	want := []string{"KWS-7:a,d", "KWS-8:b", noTicket + ":c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupByTicket() = %v, want %v", got, want)
+	}
+}
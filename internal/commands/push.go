@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+func NewPushCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Push the current branch, safely forcing after a rewrite",
+		Long: `Push the current branch to the push remote (remote.pushRemote, e.g., your fork),
+setting its upstream on the first push.
+Protected environment branches are never pushed.
+
+After update --mode rebase or retarget rewrote a pushed branch, the push is
+forced with a lease on the remote commit gitext saw before the rewrite, so
+it fails instead of overwriting commits pushed by someone else since.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
+
+			cfg, err := ctx.RequireConfig()
+			if err != nil {
+				return err
+			}
+			remote := cfg.PushRemote()
+
+			branch, err := g.GetSymbolicHEAD()
+			if err != nil {
+				return fmt.Errorf("failed to get current branch: %w", err)
+			}
+			if branch == "" {
+				return ui.NewError("HEAD is detached", "checkout a branch: git checkout -b <branch-name>")
+			}
+			output.Set("branch", branch)
+
+			if err := pushBranch(ctx, cfg, branch); err != nil {
+				return err
+			}
+
+			if ahead, behind, err := repo.GetAheadBehind(remote, branch); err == nil && ahead == 0 && behind == 0 {
+				output.Success("%s is up to date with %s/%s", branch, remote, branch)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// checkPushable refuses to push a protected environment branch
+func checkPushable(cfg *config.Config, branch string) error {
+	if env := cfg.EnvironmentForBranch(branch); env != nil && env.IsProtected() {
+		return ui.NewError(
+			fmt.Sprintf("refusing to push to protected environment branch '%s'", branch),
+			fmt.Sprintf("open a PR into %s instead: gitext prepare pr --to %s", env.Name, env.Name))
+	}
+	return nil
+}
+
+// pushBranch pushes branch to the push remote, setting its upstream on the
+// first push. After a rewrite the push is forced with the recorded lease,
+// which is cleared once the push succeeds.
+func pushBranch(ctx *Context, cfg *config.Config, branch string) error {
+	output, g := ctx.Output, ctx.Git
+	remote := cfg.PushRemote()
+
+	if err := checkPushable(cfg, branch); err != nil {
+		return err
+	}
+	if err := g.ValidateRemote(remote); err != nil {
+		return err
+	}
+
+	pushArgs := []string{"push"}
+	if upstream, err := g.GetConfigValue(fmt.Sprintf("branch.%s.remote", branch)); err == nil && upstream == "" {
+		pushArgs = append(pushArgs, "-u")
+	}
+
+	lease, err := leaseFor(ctx, branch)
+	if err != nil {
+		return err
+	}
+	if lease != "" {
+		output.Info("Forcing with a lease on %s/%s at %s", remote, branch, shortSHA(lease))
+		pushArgs = append(pushArgs, fmt.Sprintf("--force-with-lease=%s:%s", branch, lease))
+	}
+	output.Set("forced", lease != "")
+	pushArgs = append(pushArgs, remote, branch)
+
+	output.Doing("Pushing %s to %s", branch, remote)
+	if _, err := g.RunWithTimeout(pushArgs...); err != nil {
+		hints := map[git.ErrorKind]string{
+			git.ErrorNonFastForward: fmt.Sprintf("%s/%s has commits you don't have; update first: gitext update feature --with <environment>", remote, branch),
+		}
+		if lease != "" {
+			hints[git.ErrorNonFastForward] = fmt.Sprintf("someone pushed to %s/%s after gitext rewrote it; fetch and review their commits before pushing: git log %s..%s/%s", remote, branch, shortSHA(lease), remote, branch)
+		}
+		return gitFailure("failed to push", err, remote, hints)
+	}
+	if lease != "" {
+		if err := clearLease(ctx, branch); err != nil {
+			output.Warning("Failed to clear the push lease: %v", err)
+		}
+	}
+	output.Did("Pushed %s to %s", branch, remote)
+	return nil
+}
+
+// leaseKey is the git config key holding the lease of a branch
+func leaseKey(branch string) string {
+	return fmt.Sprintf("branch.%s.gitextLease", branch)
+}
+
+// recordLease remembers the remote tip of a pushed branch before a command
+// rewrites it, so gitext push can force with a lease on exactly that commit.
+// An existing lease is kept: it is what the remote had before the first of
+// several rewrites.
+func recordLease(ctx *Context, remote, branch string) error {
+	g := ctx.Git
+
+	if lease, err := g.GetConfigValue(leaseKey(branch)); err != nil || lease != "" {
+		return err
+	}
+	tip, err := g.ResolveRef(fmt.Sprintf("refs/remotes/%s/%s", remote, branch))
+	if err != nil || tip == "" {
+		return err
+	}
+	if _, err := g.RunWithTimeout("config", leaseKey(branch), tip); err != nil {
+		return fmt.Errorf("failed to record push lease: %w", err)
+	}
+	return nil
+}
+
+// leaseFor returns the lease to force-push branch with, or an empty string
+// when a regular push will do. A lease whose commit the branch still
+// contains was already pushed over (e.g., by a plain git push) and is dropped.
+func leaseFor(ctx *Context, branch string) (string, error) {
+	g := ctx.Git
+
+	lease, err := g.GetConfigValue(leaseKey(branch))
+	if err != nil || lease == "" {
+		return "", err
+	}
+	if contained, err := g.IsAncestor(lease, branch); err != nil || !contained {
+		return lease, err
+	}
+	if err := clearLease(ctx, branch); err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// clearLease forgets the lease of a branch once it has been pushed
+func clearLease(ctx *Context, branch string) error {
+	_, err := ctx.Git.RunWithTimeout("config", "--unset", leaseKey(branch))
+	return err
+}
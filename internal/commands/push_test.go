@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPushRefusesProtectedBranch(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+	repo.git(repo.dir, "checkout", "-q", "stage")
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "KWS-5 direct change")
+	before := repo.state()
+
+	if _, err := runGitext("push"); err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Errorf("expected push to a protected branch to be refused, got %v", err)
+	}
+	if repo.state() != before {
+		t.Error("push changed the repository")
+	}
+}
+
+func TestPushSetsUpstreamOnFirstPush(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "checkout", "-q", "-b", "feature/KWS-9-new")
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-9 add staged file")
+
+	if _, err := runGitext("push"); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	if upstream := strings.TrimSpace(repo.git(repo.dir, "rev-parse", "--abbrev-ref", "@{upstream}")); upstream != "origin/feature/KWS-9-new" {
+		t.Errorf("expected upstream origin/feature/KWS-9-new, got %s", upstream)
+	}
+}
+
+func TestPushForcesWithRecordedLease(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+
+	if _, err := runGitext("update", "feature", "--with", "stage"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	lease := strings.TrimSpace(repo.git(repo.dir, "config", "--get", leaseKey("feature/KWS-1-login")))
+	if remote := strings.TrimSpace(repo.git(repo.dir, "rev-parse", "origin/feature/KWS-1-login")); lease != remote {
+		t.Fatalf("expected the lease to pin %s, got %s", remote, lease)
+	}
+
+	if _, err := runGitext("push"); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	head := repo.git(repo.dir, "rev-parse", "HEAD")
+	if remote := repo.git(repo.dir, "ls-remote", "origin", "refs/heads/feature/KWS-1-login"); !strings.HasPrefix(remote, strings.TrimSpace(head)) {
+		t.Errorf("expected the remote branch at %s, got %s", head, remote)
+	}
+	if config := repo.git(repo.dir, "config", "--list"); strings.Contains(config, "gitextlease") {
+		t.Errorf("expected the lease to be cleared, got\n%s", config)
+	}
+}
+
+func TestPushLeaseSurvivesBackgroundFetch(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+
+	if _, err := runGitext("update", "feature", "--with", "stage"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	// Someone else pushes to the branch, and a background fetch picks it up
+	tree := strings.TrimSpace(repo.git(repo.dir, "rev-parse", "origin/feature/KWS-1-login^{tree}"))
+	other := strings.TrimSpace(repo.git(repo.dir, "commit-tree", "-p", "origin/feature/KWS-1-login", "-m", "KWS-1 bob's change", tree))
+	repo.git(repo.dir, "push", "-q", "origin", other+":refs/heads/feature/KWS-1-login")
+	repo.git(repo.dir, "fetch", "-q", "origin")
+
+	if _, err := runGitext("push"); err == nil {
+		t.Fatal("expected the push to be rejected by the lease")
+	}
+	if remote := repo.git(repo.dir, "ls-remote", "origin", "refs/heads/feature/KWS-1-login"); !strings.HasPrefix(remote, other) {
+		t.Errorf("expected the other push to be kept, got %s", remote)
+	}
+}
+
+func TestPrepareCreateRefusesProtectedBranch(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+	repo.git(repo.dir, "checkout", "-q", "stage")
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "KWS-5 direct change")
+	before := repo.state()
+
+	if _, err := runGitext("prepare", "pr", "--to", "production", "--create"); err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Errorf("expected prepare pr --create on a protected branch to be refused, got %v", err)
+	}
+	if repo.state() != before {
+		t.Error("prepare pr --create changed the repository")
+	}
+}
@@ -2,23 +2,20 @@ package commands
 
 import (
 	"fmt"
-	"regexp"
-	"strings"
 
-	"github.com/imemir/gitext/pkg/config"
 	"github.com/imemir/gitext/pkg/git"
-	"github.com/imemir/gitext/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
 func NewRetargetCmd(opts *Options) *cobra.Command {
 	var onto, from string
-	var override, iKnowWhatImDoing bool
+	var override, iKnowWhatImDoing, autostash bool
 
 	cmd := &cobra.Command{
 		Use:   "retarget feature",
-		Short: "Retarget a feature branch from stage onto production",
-		Long: `Rebase a feature branch that was based on stage onto production.
+		Short: "Retarget a feature branch from one environment onto another",
+		Long: `Rebase a feature branch that was based on one environment onto another
+(by default from stage onto production).
 This is useful when a feature is ready for production but was developed from stage.
 Uses 'git rebase --onto' to rewrite history safely.`,
 		Args: cobra.ExactArgs(1),
@@ -27,128 +24,132 @@ Uses 'git rebase --onto' to rewrite history safely.`,
 				return fmt.Errorf("only 'feature' is supported currently")
 			}
 
-			output := ui.NewOutput(opts.Verbose)
-			g := git.NewGit(opts.DryRun, opts.Verbose)
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
 
-			if err := g.ValidateGitRepo(); err != nil {
-				return ui.NewError("not in a git repository", "run this command from within a git repository")
-			}
-
-			cfg, err := config.Load()
+			cfg, err := ctx.RequireConfig()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return err
 			}
 
-			// Validate flags
+			// Validate flags (default: from the environment below production onto production)
 			if onto == "" {
-				onto = "production"
+				onto = cfg.ProductionEnvironment().Name
 			}
 			if from == "" {
-				from = "stage"
+				if prev := cfg.PreviousEnvironment(onto); prev != nil {
+					from = prev.Name
+				}
 			}
 
-			if onto != "production" {
-				return fmt.Errorf("--onto must be 'production'")
+			ontoEnv, err := resolveEnvironment(cfg, onto, "--onto")
+			if err != nil {
+				return err
+			}
+			fromEnv, err := resolveEnvironment(cfg, from, "--from")
+			if err != nil {
+				return err
 			}
-			if from != "stage" {
-				return fmt.Errorf("--from must be 'stage'")
+			if ontoEnv.Name == fromEnv.Name {
+				return fmt.Errorf("--onto and --from must be different environments")
 			}
 
-			ontoBranch := cfg.Branch.Production
-			fromBranch := cfg.Branch.Stage
+			ontoBranch := ontoEnv.Branch
+			fromBranch := fromEnv.Branch
 
 			// Get current branch
-			currentBranch, err := g.GetCurrentBranch()
+			currentBranch, err := repo.GetCurrentBranch()
 			if err != nil {
 				return fmt.Errorf("failed to get current branch: %w", err)
 			}
 
 			// Validate current branch is a feature branch (unless override)
 			if !override {
-				pattern := strings.ReplaceAll(cfg.Naming.Feature, "*", ".*")
-				matched, err := regexp.MatchString("^"+pattern+"$", currentBranch)
+				matched, err := matchesPattern(cfg.Naming.Feature, currentBranch)
 				if err != nil {
-					return fmt.Errorf("failed to validate branch name: %w", err)
+					return err
 				}
 				if !matched {
 					return fmt.Errorf("current branch '%s' does not match feature pattern '%s' (use --override to bypass)", currentBranch, cfg.Naming.Feature)
 				}
 			}
 
-			// Validate remote
-			if err := g.ValidateRemote(cfg.Remote.Name); err != nil {
+			// Validate remotes
+			remote, pushRemote := cfg.IntegrationRemote(), cfg.PushRemote()
+			if err := g.ValidateRemote(remote); err != nil {
+				return err
+			}
+			if err := g.ValidateRemote(pushRemote); err != nil {
 				return err
 			}
 
 			// Check working tree
-			isClean, err := g.IsWorkingTreeClean()
+			dirty, err := checkWorkingTree(ctx, useAutostash(cmd, cfg, autostash))
 			if err != nil {
-				return fmt.Errorf("failed to check working tree: %w", err)
+				return err
 			}
-			if !isClean {
-				return ui.NewError("working tree has uncommitted changes", "commit or stash changes first")
+
+			// Fetch latest
+			if _, err := fetchRemote(ctx, cfg); err != nil {
+				return gitFailure("failed to fetch", err, remote, nil)
 			}
 
 			// Check if branch appears shared
-			remoteBranchExists, err := g.RemoteBranchExists(cfg.Remote.Name, currentBranch)
+			remoteBranchExists, err := repo.TrackingBranchExists(pushRemote, currentBranch)
 			if err == nil && remoteBranchExists {
-				// Check for multiple authors in recent commits
-				authors, err := g.GetCommitAuthors(10)
-				if err == nil && len(authors) > 1 {
-					if !iKnowWhatImDoing {
-						output.Error("Branch '%s' appears to be shared (multiple authors in recent commits)", currentBranch)
-						output.Warning("Retargeting will rewrite history and may affect other developers")
-						return fmt.Errorf("branch appears shared → use --i-know-what-im-doing to proceed")
-					}
-					output.Warning("Branch appears shared, proceeding with --i-know-what-im-doing flag")
+				fromRef := fmt.Sprintf("%s/%s", remote, fromEnv.Branch)
+				if err := checkShared(ctx, pushRemote, currentBranch, fromRef, iKnowWhatImDoing); err != nil {
+					return err
 				}
 			}
 
-			// Fetch latest
-			output.Doing("Fetching latest from %s", cfg.Remote.Name)
-			if _, err := g.RunWithTimeout("fetch", cfg.Remote.Name); err != nil {
-				return fmt.Errorf("failed to fetch: %w", err)
-			}
-
 			// Validate branches exist
-			if err := g.ValidateBranchExists(ontoBranch, cfg.Remote.Name); err != nil {
+			if err := g.ValidateBranchExists(ontoBranch, remote); err != nil {
 				return fmt.Errorf("target branch '%s' does not exist: %w", ontoBranch, err)
 			}
-			if err := g.ValidateBranchExists(fromBranch, cfg.Remote.Name); err != nil {
+			if err := g.ValidateBranchExists(fromBranch, remote); err != nil {
 				return fmt.Errorf("source branch '%s' does not exist: %w", fromBranch, err)
 			}
 
 			// Execute rebase --onto
-			ontoRef := fmt.Sprintf("%s/%s", cfg.Remote.Name, ontoBranch)
-			fromRef := fmt.Sprintf("%s/%s", cfg.Remote.Name, fromBranch)
+			ontoRef := fmt.Sprintf("%s/%s", remote, ontoBranch)
+			fromRef := fmt.Sprintf("%s/%s", remote, fromBranch)
 
-			output.Doing("Retargeting %s onto %s (from %s)", currentBranch, ontoRef, fromRef)
-			output.Warning("This will rewrite history. If the branch is pushed, you'll need to force push.")
+			if dirty {
+				if err := stashChanges(ctx, commandLine(cmd, args)); err != nil {
+					return err
+				}
+			}
+			entry, err := recordJournal(ctx, commandLine(cmd, args), currentBranch)
+			if err != nil {
+				return err
+			}
 
-			if _, err := g.RunWithTimeout("rebase", "--onto", ontoRef, fromRef); err != nil {
-				output.Error("Rebase encountered conflicts")
-				output.Next("resolve conflicts, then run: git rebase --continue")
-				return fmt.Errorf("rebase failed: %w", err)
+			state := newOperation(commandLine(cmd, args), git.OperationRebase, currentBranch, ontoRef, pushRemote, entry)
+			state.ForcePush = remoteBranchExists
+			if remoteBranchExists {
+				if err := recordLease(ctx, pushRemote, currentBranch); err != nil {
+					return err
+				}
 			}
+			state.Summary = fmt.Sprintf("Retargeted %s onto %s", currentBranch, ontoRef)
 
-			output.Did("Retargeted %s onto %s", currentBranch, ontoRef)
+			output.Doing("Retargeting %s onto %s (from %s)", currentBranch, ontoRef, fromRef)
+			output.Warning("This will rewrite history. If the branch is pushed, you'll need to force push.")
 
-			// Check if remote branch exists and warn about force push
-			if remoteBranchExists {
-				output.Warning("Remote branch exists. You'll need to force push with --force-with-lease")
-				output.Next("push with force: git push --force-with-lease %s %s", cfg.Remote.Name, currentBranch)
-			} else {
-				output.Next("push branch: git push %s %s", cfg.Remote.Name, currentBranch)
+			if _, err := g.RunWithTimeout("rebase", "--onto", ontoRef, fromRef); err != nil {
+				return pauseOperation(ctx, state, err)
 			}
 
-			return nil
+			return finishOperation(ctx, state)
 		},
 	}
 
-	cmd.Flags().StringVar(&onto, "onto", "production", "Target branch (must be production)")
-	cmd.Flags().StringVar(&from, "from", "stage", "Source branch (must be stage)")
+	cmd.Flags().StringVar(&onto, "onto", "", "Target environment (default: production)")
+	cmd.Flags().StringVar(&from, "from", "", "Environment the branch was based on (default: the one below --onto)")
 	cmd.Flags().BoolVar(&override, "override", false, "Allow retargeting non-feature branches")
 	cmd.Flags().BoolVar(&iKnowWhatImDoing, "i-know-what-im-doing", false, "Bypass shared branch safety check")
+	cmd.Flags().BoolVar(&autostash, "autostash", false, "Stash uncommitted changes, including untracked files, and reapply them afterwards (default: workflow.autostash)")
 
 	return cmd
 }
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// NewRootCmd creates the gitext root command with its global flags and
+// all subcommands
+func NewRootCmd(version, buildTime string) (*cobra.Command, *Options) {
+	opts := &Options{Version: version}
+
+	rootCmd := &cobra.Command{
+		Use:   "gitext",
+		Short: "Safe git workflow automation for engineering teams",
+		Long: fmt.Sprintf(`gitext is a CLI tool that replaces manual git workflow steps with safe,
+repeatable commands. It enforces branch protection rules and prevents
+accidental production contamination.
+
+Version: %s
+Build Time: %s`, version, buildTime),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return setupContext(opts, cmd)
+		},
+	}
+
+	rootCmd.PersistentFlags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would be done without executing")
+	rootCmd.PersistentFlags().BoolVar(&opts.Verbose, "verbose", false, "Show detailed git command output")
+	rootCmd.PersistentFlags().BoolVar(&opts.Offline, "offline", false, "Do not contact the remote; use remote-tracking refs from the last fetch")
+	rootCmd.PersistentFlags().StringVar(&opts.Format, "output", "text", "Output format: text or json")
+
+	// Add subcommands
+	AddCommands(rootCmd, opts)
+
+	return rootCmd, opts
+}
+
+// Execute runs the root command and writes the JSON document, if any
+func Execute(rootCmd *cobra.Command, opts *Options) error {
+	err := rootCmd.Execute()
+	if opts.ctx != nil {
+		if flushErr := opts.ctx.Finish(err); flushErr != nil {
+			fmt.Fprintln(os.Stderr, flushErr)
+		}
+	}
+	return err
+}
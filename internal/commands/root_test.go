@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/git"
+)
+
+// gitTestRepo is a working repository with a bare remote, where every git
+// call made by gitext is logged by a wrapper on PATH
+type gitTestRepo struct {
+	t       *testing.T
+	dir     string
+	realGit string
+	log     string
+}
+
+func newGitTestRepo(t *testing.T) *gitTestRepo {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("git wrapper script requires a POSIX shell")
+	}
+	realGit, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	repo := &gitTestRepo{
+		t:       t,
+		dir:     filepath.Join(root, "work"),
+		realGit: realGit,
+		log:     filepath.Join(root, "git.log"),
+	}
+
+	t.Setenv("HOME", root)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	remote := filepath.Join(root, "remote.git")
+	repo.git(root, "init", "-q", "--bare", "-b", "production", remote)
+	repo.git(root, "init", "-q", "-b", "production", repo.dir)
+	repo.git(repo.dir, "remote", "add", "origin", remote)
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "Initial commit")
+	repo.git(repo.dir, "branch", "stage")
+	repo.git(repo.dir, "checkout", "-q", "-b", "hotfix/KWS-3-fix")
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "KWS-3 fix")
+	repo.git(repo.dir, "checkout", "-q", "stage")
+	repo.git(repo.dir, "checkout", "-q", "-b", "feature/KWS-1-login")
+	repo.writeFile("login.txt", "login\n")
+	repo.git(repo.dir, "add", "login.txt")
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-1 add login")
+	repo.git(repo.dir, "push", "-q", "origin", "production", "stage", "hotfix/KWS-3-fix", "feature/KWS-1-login")
+	repo.git(repo.dir, "checkout", "-q", "stage")
+	repo.git(repo.dir, "commit", "-q", "--allow-empty", "-m", "KWS-2 other change")
+	repo.git(repo.dir, "push", "-q", "origin", "stage")
+	repo.git(repo.dir, "checkout", "-q", "feature/KWS-1-login")
+
+	// Leave a staged change for commit
+	repo.writeFile("staged.txt", "staged\n")
+	repo.git(repo.dir, "add", "staged.txt")
+
+	// Log every git call made by gitext, then run the real git
+	bin := filepath.Join(root, "bin")
+	if err := os.Mkdir(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	wrapper := fmt.Sprintf("#!/bin/sh\nprintf '%%s\\n' \"$*\" >> %q\nexec %q \"$@\"\n", repo.log, realGit)
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(wrapper), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	t.Chdir(repo.dir)
+	return repo
+}
+
+// git runs the real git, bypassing the logging wrapper
+func (r *gitTestRepo) git(dir string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command(r.realGit, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func (r *gitTestRepo) writeFile(name, content string) {
+	r.t.Helper()
+	if err := os.WriteFile(filepath.Join(r.dir, name), []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// state captures everything a mutating command could change
+func (r *gitTestRepo) state() string {
+	var state strings.Builder
+	state.WriteString(r.git(r.dir, "for-each-ref", "--format=%(refname) %(objectname)"))
+	state.WriteString(r.git(r.dir, "symbolic-ref", "HEAD"))
+	state.WriteString(r.git(r.dir, "status", "--porcelain", "--untracked-files=all"))
+	state.WriteString(r.git(r.dir, "stash", "list"))
+	state.WriteString(r.git(r.dir, "ls-remote", "origin"))
+	for _, path := range []string{".gitext", ".git/hooks/pre-push", ".git/hooks/commit-msg"} {
+		if _, err := os.Stat(filepath.Join(r.dir, path)); err == nil {
+			state.WriteString("exists " + path + "\n")
+		}
+	}
+	return state.String()
+}
+
+// loggedCalls returns the git calls logged since the log was last reset
+func (r *gitTestRepo) loggedCalls() []string {
+	data, err := os.ReadFile(r.log)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	_ = os.Remove(r.log)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+// runGitext executes gitext through cobra with the given arguments
+func runGitext(args ...string) (*Options, error) {
+	rootCmd, opts := NewRootCmd("test", "test")
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	err := Execute(rootCmd, opts)
+	return opts, err
+}
+
+func TestDryRunMakesNoMutatingGitCalls(t *testing.T) {
+	repo := newGitTestRepo(t)
+
+	commands := [][]string{
+		{"status"},
+		{"init", "--install-hooks"},
+		{"start", "feature", "--ticket", "KWS-9", "--slug", "new thing", "--from", "stage"},
+		{"start", "feature", "--ticket", "KWS-9", "--slug", "new thing", "--from", "stage", "--worktree"},
+		{"start", "feature", "--ticket", "KWS-9", "--slug", "new thing", "--from", "stage", "--autostash"},
+		{"update", "feature", "--with", "stage"},
+		{"update", "feature", "--with", "stage", "--mode", "merge"},
+		{"update", "feature", "--with", "stage", "--autostash"},
+		{"sync", "stage"},
+		{"sync", "stage", "--autostash"},
+		{"retarget", "feature"},
+		{"prepare", "pr", "--to", "stage"},
+		{"push"},
+		{"commit", "-m", "feat: add staged file"},
+		{"finish", "hotfix", "--branch", "hotfix/KWS-3-fix"},
+		{"promote", "stage", "production", "--yes"},
+		{"cleanup", "--hard"},
+		{"worktree", "list"},
+		{"worktree", "prune"},
+		{"lint-commits"},
+	}
+
+	before := repo.state()
+	for _, args := range commands {
+		name := strings.Join(args, " ")
+		opts, _ := runGitext(append(args, "--dry-run")...)
+
+		if !opts.Context().DryRun {
+			t.Errorf("%s: --dry-run was not applied", name)
+		}
+		for _, call := range repo.loggedCalls() {
+			if call != "" && !git.IsReadOnly(strings.Fields(call)) {
+				t.Errorf("%s: ran mutating git call in dry-run: git %s", name, call)
+			}
+		}
+		if after := repo.state(); after != before {
+			t.Fatalf("%s: repository changed in dry-run\nbefore:\n%s\nafter:\n%s", name, before, after)
+		}
+	}
+}
+
+func TestDryRunPlan(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+
+	opts, err := runGitext("start", "feature", "--ticket", "KWS-9", "--slug", "new thing", "--from", "stage", "--dry-run")
+	if err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+
+	// Read-only queries ran for real, so the plan reflects the actual state
+	want := []string{
+		"git fetch origin",
+		"git checkout stage",
+		"git pull --ff-only origin stage",
+		"git checkout -b feature/KWS-9-new-thing",
+	}
+	plan := opts.Context().Git.Plan().Steps()
+	if strings.Join(plan, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected plan:\n%s\nwant:\n%s", strings.Join(plan, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGlobalFlagsAreParsed(t *testing.T) {
+	newGitTestRepo(t)
+
+	opts, err := runGitext("status", "--verbose", "--dry-run")
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	ctx := opts.Context()
+	if !ctx.Verbose || !ctx.DryRun {
+		t.Errorf("expected verbose and dry-run to be set, got verbose=%v dry-run=%v", ctx.Verbose, ctx.DryRun)
+	}
+	if ctx.RepoRoot == "" {
+		t.Error("expected repository root to be set")
+	}
+	if cfg, err := ctx.RequireConfig(); err != nil || cfg.IntegrationEnvironment().Name != "stage" {
+		t.Errorf("expected default config, got %v", err)
+	}
+
+	if _, err := runGitext("status", "--output", "xml"); err == nil {
+		t.Error("expected error for invalid --output")
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"runtime"
 	"strings"
 
-	"github.com/imemir/gitext/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -38,7 +37,7 @@ func NewSelfUpdateCmd(opts *Options) *cobra.Command {
 		Long: `Check for the latest version of gitext and update the binary if a newer version is available.
 This command downloads the latest release from GitHub and replaces the current binary.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output := ui.NewOutput(opts.Verbose)
+			output := opts.Output()
 
 			currentVersion := opts.Version
 			if currentVersion == "" {
@@ -66,7 +65,7 @@ This command downloads the latest release from GitHub and replaces the current b
 			// Confirm update
 			if !yesFlag {
 				output.Info("Update available: %s -> %s", currentVersion, latestVersion)
-				fmt.Print("Do you want to update? [Y/n]: ")
+				fmt.Fprint(output.Log(), "Do you want to update? [Y/n]: ")
 				var response string
 				fmt.Scanln(&response)
 				if strings.ToLower(response) == "n" {
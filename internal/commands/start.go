@@ -2,39 +2,42 @@ package commands
 
 import (
 	"fmt"
-	"regexp"
-	"strings"
+	"os"
 
 	"github.com/imemir/gitext/pkg/config"
-	"github.com/imemir/gitext/pkg/git"
 	"github.com/imemir/gitext/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
 func NewStartCmd(opts *Options) *cobra.Command {
 	var ticket, slug, from string
+	var worktree, autostash bool
 
 	cmd := &cobra.Command{
-		Use:   "start feature",
-		Short: "Start a new feature branch",
-		Long: `Create a new feature branch from stage or production.
-The branch name will be: feature/<ticket>-<slug>`,
+		Use:   "start [feature|hotfix]",
+		Short: "Start a new feature or hotfix branch",
+		Long: `Create a new feature branch from any environment (e.g., stage or production),
+or a hotfix branch from production.
+The branch name is generated from naming.template in .gitext
+(default: {type}/{ticket}-{slug}, e.g., feature/KWS-123-retry-policy).
+
+With --worktree, the branch is checked out in a new git worktree under
+worktree.dir instead, leaving the current checkout (and any uncommitted
+changes in it) untouched. With --autostash, uncommitted changes are carried
+over to the new branch.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if args[0] != "feature" {
-				return fmt.Errorf("only 'feature' is supported currently")
+			branchType := args[0]
+			if branchType != branchTypeFeature && branchType != branchTypeHotfix {
+				return fmt.Errorf("only 'feature' and 'hotfix' are supported")
 			}
 
-			output := ui.NewOutput(opts.Verbose)
-			g := git.NewGit(opts.DryRun, opts.Verbose)
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
 
-			if err := g.ValidateGitRepo(); err != nil {
-				return ui.NewError("not in a git repository", "run this command from within a git repository")
-			}
-
-			cfg, err := config.Load()
+			cfg, err := ctx.RequireConfig()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return err
 			}
 
 			// Validate flags
@@ -44,54 +47,58 @@ The branch name will be: feature/<ticket>-<slug>`,
 			if slug == "" {
 				return fmt.Errorf("--slug is required")
 			}
-			if from == "" {
-				return fmt.Errorf("--from is required (stage or production)")
-			}
-
-			var sourceBranch string
-			switch from {
-			case "stage":
-				sourceBranch = cfg.Branch.Stage
-			case "production":
-				sourceBranch = cfg.Branch.Production
-			default:
-				return fmt.Errorf("--from must be 'stage' or 'production'")
+			production := cfg.ProductionEnvironment()
+			if branchType == branchTypeHotfix {
+				// Hotfixes always start from what is running in production
+				if from == "" {
+					from = production.Name
+				}
+				if from != production.Name {
+					return ui.NewError(
+						fmt.Sprintf("hotfix branches must start from %s", production.Name),
+						"run: gitext start hotfix --ticket "+ticket+" --slug "+slug,
+					)
+				}
+			}
+
+			sourceEnv, err := resolveEnvironment(cfg, from, "--from")
+			if err != nil {
+				return err
 			}
+			sourceBranch := sourceEnv.Branch
 
 			// Validate remote
-			if err := g.ValidateRemote(cfg.Remote.Name); err != nil {
+			remote := cfg.IntegrationRemote()
+			if err := g.ValidateRemote(remote); err != nil {
 				return err
 			}
 
-			// Validate source branch exists
-			if err := g.ValidateBranchExists(sourceBranch, cfg.Remote.Name); err != nil {
-				return err
+			// Check working tree; a new worktree leaves this one alone
+			var dirty bool
+			if !worktree {
+				if dirty, err = checkWorkingTree(ctx, useAutostash(cmd, cfg, autostash)); err != nil {
+					return err
+				}
 			}
 
-			// Check working tree
-			isClean, err := g.IsWorkingTreeClean()
+			// Generate branch name
+			branchName, err := generateBranchName(cfg, g, branchType, ticket, slug)
 			if err != nil {
-				return fmt.Errorf("failed to check working tree: %w", err)
-			}
-			if !isClean {
-				return ui.NewError("working tree has uncommitted changes", "commit or stash changes first")
+				return fmt.Errorf("failed to generate branch name: %w", err)
 			}
 
-			// Generate branch name
-			branchName := fmt.Sprintf("feature/%s-%s", ticket, slug)
-
 			// Validate branch name matches pattern
-			pattern := strings.ReplaceAll(cfg.Naming.Feature, "*", ".*")
-			matched, err := regexp.MatchString("^"+pattern+"$", branchName)
+			pattern := namingPattern(cfg, branchType)
+			matched, err := matchesPattern(pattern, branchName)
 			if err != nil {
-				return fmt.Errorf("failed to validate branch name: %w", err)
+				return err
 			}
 			if !matched {
-				return fmt.Errorf("branch name '%s' does not match pattern '%s'", branchName, cfg.Naming.Feature)
+				return fmt.Errorf("branch name '%s' does not match pattern '%s'", branchName, pattern)
 			}
 
 			// Check if branch already exists
-			exists, err := g.BranchExists(branchName)
+			exists, err := repo.BranchExists(branchName)
 			if err != nil {
 				return fmt.Errorf("failed to check if branch exists: %w", err)
 			}
@@ -100,39 +107,106 @@ The branch name will be: feature/<ticket>-<slug>`,
 			}
 
 			// Fetch latest
-			output.Doing("Fetching latest from %s", cfg.Remote.Name)
-			if _, err := g.RunWithTimeout("fetch", cfg.Remote.Name); err != nil {
-				return fmt.Errorf("failed to fetch: %w", err)
+			fetched, err := fetchRemote(ctx, cfg)
+			if err != nil {
+				return gitFailure("failed to fetch", err, remote, nil)
+			}
+
+			// Validate source branch exists, as of the fetch
+			if err := g.ValidateBranchExists(sourceBranch, remote); err != nil {
+				return err
+			}
+
+			if worktree {
+				return startWorktree(ctx, cfg, remote, sourceBranch, branchName, branchType)
+			}
+
+			if dirty {
+				if err := stashChanges(ctx, commandLine(cmd, args)); err != nil {
+					return err
+				}
 			}
 
 			// Checkout source branch
 			output.Doing("Checking out %s", sourceBranch)
-			if _, err := g.RunWithTimeout("checkout", sourceBranch); err != nil {
-				return fmt.Errorf("failed to checkout %s: %w", sourceBranch, err)
+			if err := checkoutBranch(g, remote, sourceBranch); err != nil {
+				return gitFailure(fmt.Sprintf("failed to checkout %s", sourceBranch), err, remote, nil)
 			}
 
 			// Pull latest
 			output.Doing("Pulling latest changes")
-			if _, err := g.RunWithTimeout("pull", "--ff-only", cfg.Remote.Name, sourceBranch); err != nil {
+			if _, err := fastForward(g, fetched, remote, sourceBranch); err != nil {
 				output.Warning("Fast-forward pull failed, continuing anyway")
 			}
 
 			// Create and checkout new branch
 			output.Doing("Creating branch %s", branchName)
 			if _, err := g.RunWithTimeout("checkout", "-b", branchName); err != nil {
-				return fmt.Errorf("failed to create branch: %w", err)
+				return gitFailure("failed to create branch", err, remote, nil)
 			}
 			output.Did("Created and checked out %s", branchName)
+			output.Set("branch", branchName)
+			if err := restoreAutostash(ctx, ctx.stash); err != nil {
+				return err
+			}
 
-			output.Next("start making changes, then run: gitext prepare pr --to stage")
+			startNext(ctx, cfg, branchType)
 
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&ticket, "ticket", "", "Ticket ID (e.g., KWS-123)")
-	cmd.Flags().StringVar(&slug, "slug", "", "Feature slug (e.g., retry-policy)")
-	cmd.Flags().StringVar(&from, "from", "", "Source branch (stage or production)")
+	cmd.Flags().StringVar(&slug, "slug", "", "Branch slug (e.g., retry-policy)")
+	cmd.Flags().StringVar(&from, "from", "", "Source environment (e.g., stage or production, hotfixes always use production)")
+	cmd.Flags().BoolVar(&worktree, "worktree", false, "Create the branch in a new worktree under worktree.dir instead of checking it out here")
+	cmd.Flags().BoolVar(&autostash, "autostash", false, "Stash uncommitted changes, including untracked files, and reapply them afterwards (default: workflow.autostash)")
 
 	return cmd
 }
+
+// startWorktree creates branch from the source branch in a new worktree,
+// without touching the current checkout. The branch starts from the
+// remote-tracking branch, which the fetch just refreshed.
+func startWorktree(ctx *Context, cfg *config.Config, remote, sourceBranch, branch, branchType string) error {
+	output, g, repo := ctx.Output, ctx.Git, ctx.Repo
+
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	path, err := worktreePath(cfg, worktrees, branch)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return ui.NewError(fmt.Sprintf("'%s' already exists", path), "remove it, or set worktree.dir in .gitext to another directory")
+	}
+
+	startPoint := sourceBranch
+	if exists, err := repo.TrackingBranchExists(remote, sourceBranch); err == nil && exists {
+		startPoint = fmt.Sprintf("%s/%s", remote, sourceBranch)
+	}
+
+	// --no-track: the branch is pushed to its own upstream, not the source's
+	output.Doing("Creating worktree %s for %s from %s", path, branch, startPoint)
+	if _, err := g.RunWithTimeout("worktree", "add", "--no-track", "-b", branch, path, startPoint); err != nil {
+		return gitFailure("failed to create worktree", err, remote, nil)
+	}
+	output.Did("Created %s in worktree %s", branch, path)
+	output.Set("branch", branch)
+	output.Set("worktree", path)
+
+	output.Next("switch to it: cd %s", path)
+	startNext(ctx, cfg, branchType)
+	return nil
+}
+
+// startNext suggests what to do on a freshly started branch
+func startNext(ctx *Context, cfg *config.Config, branchType string) {
+	if branchType == branchTypeHotfix {
+		ctx.Output.Next("fix the issue, then run: gitext prepare pr --to %s", cfg.ProductionEnvironment().Name)
+	} else {
+		ctx.Output.Next("start making changes, then run: gitext prepare pr --to %s", cfg.IntegrationEnvironment().Name)
+	}
+}
@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStartHotfixFromProduction(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "stash", "-q")
+
+	if _, err := runGitext("start", "hotfix", "--ticket", "KWS-9", "--slug", "crash", "--from", "stage"); err == nil || !strings.Contains(err.Error(), "must start from production") {
+		t.Fatalf("expected a hotfix from stage to be refused, got %v", err)
+	}
+
+	if _, err := runGitext("start", "hotfix", "--ticket", "KWS-9", "--slug", "crash"); err != nil {
+		t.Fatalf("start hotfix failed: %v", err)
+	}
+	if branch := strings.TrimSpace(repo.git(repo.dir, "branch", "--show-current")); branch != "hotfix/KWS-9-crash" {
+		t.Fatalf("expected hotfix/KWS-9-crash to be checked out, got %s", branch)
+	}
+	head := repo.git(repo.dir, "rev-parse", "HEAD")
+	if production := repo.git(repo.dir, "rev-parse", "origin/production"); head != production {
+		t.Errorf("expected the hotfix to start at origin/production %s, got %s", production, head)
+	}
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/imemir/gitext/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// autostashMessage tags the stashes gitext creates in git stash list
+const autostashMessage = "gitext autostash"
+
+// useAutostash returns --autostash when given, otherwise workflow.autostash
+func useAutostash(cmd *cobra.Command, cfg *config.Config, autostash bool) bool {
+	if cmd.Flags().Changed("autostash") {
+		return autostash
+	}
+	return cfg.Workflow.Autostash
+}
+
+// checkWorkingTree fails on uncommitted changes unless autostash is set, in
+// which case it reports whether they need to be stashed. The check comes
+// early, the stash right before the command starts changing things, so
+// commands refused along the way leave the changes where they were.
+func checkWorkingTree(ctx *Context, autostash bool) (bool, error) {
+	isClean, err := ctx.Repo.IsWorkingTreeClean()
+	if err != nil {
+		return false, fmt.Errorf("failed to check working tree: %w", err)
+	}
+	if isClean {
+		return false, nil
+	}
+	if !autostash {
+		return false, ui.NewError("working tree has uncommitted changes", "commit or stash changes first, or rerun with --autostash")
+	}
+	return true, nil
+}
+
+// stashChanges stashes uncommitted changes, including untracked files, for
+// the command to reapply when it is done. Until then the stash is recorded
+// in the context, and in the journal entry and operation state of the
+// command.
+func stashChanges(ctx *Context, command string) error {
+	output, g := ctx.Output, ctx.Git
+
+	output.Doing("Stashing uncommitted changes")
+	stash, err := g.Stash(fmt.Sprintf("%s: %s", autostashMessage, command))
+	if err != nil {
+		return gitFailure("failed to stash changes", err, "", nil)
+	}
+	ctx.stash = stash
+	output.Did("Stashed uncommitted changes as %s", stashRef(g, stash))
+	output.Set("autostash", stash)
+	return nil
+}
+
+// restoreAutostash reapplies stashed changes on the branch the command left
+// checked out and drops the stash. If they conflict, the stash is kept so
+// nothing is lost.
+func restoreAutostash(ctx *Context, stash string) error {
+	output, g := ctx.Output, ctx.Git
+	if stash == "" {
+		return nil
+	}
+	if ctx.stash == stash {
+		ctx.stash = ""
+	}
+
+	output.Doing("Reapplying stashed changes")
+	if _, err := g.RunWithTimeout("stash", "apply", stash); err != nil {
+		ref := stashRef(g, stash)
+		if git.KindOf(err) == git.ErrorConflict {
+			return ui.NewError("reapplying your stashed changes conflicted",
+				fmt.Sprintf("resolve the conflicts; your changes are kept in %s, drop it once done: git stash drop %s", ref, ref))
+		}
+		return gitFailure("failed to reapply your stashed changes", err, "", map[git.ErrorKind]string{
+			git.ErrorUnknown: fmt.Sprintf("your changes are kept in %s; apply them once the tree allows: git stash apply %s", ref, ref),
+		})
+	}
+
+	// Drop the stash unless the user already did
+	if ref := stashRef(g, stash); ctx.DryRun || ref != stash {
+		if _, err := g.RunWithTimeout("stash", "drop", ref); err != nil {
+			output.Warning("Reapplied your changes but failed to drop %s: %v", ref, err)
+		}
+	}
+	output.Did("Reapplied stashed changes")
+	return nil
+}
+
+// stashRef names a stash commit for the user: its stash@{n} entry while it
+// is in the stash list, otherwise the commit itself
+func stashRef(g *git.Git, stash string) string {
+	if g.DryRun() {
+		return stash
+	}
+	if ref, err := g.GetStashRef(stash); err == nil && ref != "" {
+		return ref
+	}
+	return stash
+}
+
+// reportAutostash warns about changes still stashed when a command failed
+// before it could reapply them
+func (c *Context) reportAutostash(err error) {
+	if c.stash == "" || err == nil {
+		return
+	}
+	ref := stashRef(c.Git, c.stash)
+	c.Output.Warning("Your uncommitted changes are still stashed as %s", ref)
+	c.Output.Next("restore them on the branch you want: git stash pop %s", ref)
+}
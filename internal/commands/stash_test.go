@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// makeDirty leaves the staged file from newGitTestRepo, a modified tracked
+// file and an untracked file in the working tree
+func makeDirty(repo *gitTestRepo) {
+	repo.writeFile("login.txt", "login\nremember me\n")
+	repo.writeFile("notes.txt", "notes\n")
+}
+
+func assertDirty(t *testing.T, repo *gitTestRepo) {
+	t.Helper()
+	for name, want := range map[string]string{
+		"staged.txt": "staged\n",
+		"login.txt":  "login\nremember me\n",
+		"notes.txt":  "notes\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(repo.dir, name))
+		if err != nil || string(data) != want {
+			t.Errorf("expected %s to be restored as %q, got %q (%v)", name, want, data, err)
+		}
+	}
+	if list := repo.git(repo.dir, "stash", "list"); list != "" {
+		t.Errorf("expected the stash to be dropped, got:\n%s", list)
+	}
+}
+
+func TestDirtyTreeRequiresAutostash(t *testing.T) {
+	repo := newGitTestRepo(t)
+	before := repo.state()
+
+	_, err := runGitext("update", "feature", "--with", "stage")
+	if err == nil || !strings.Contains(err.Error(), "--autostash") {
+		t.Fatalf("expected update to refuse the dirty tree, got %v", err)
+	}
+	if after := repo.state(); after != before {
+		t.Fatalf("refused update changed the repository\nbefore:\n%s\nafter:\n%s", before, after)
+	}
+}
+
+func TestUpdateAutostash(t *testing.T) {
+	repo := newGitTestRepo(t)
+	makeDirty(repo)
+
+	if _, err := runGitext("update", "feature", "--with", "stage", "--autostash"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	repo.git(repo.dir, "merge-base", "--is-ancestor", "origin/stage", "HEAD")
+	assertDirty(t, repo)
+}
+
+func TestStartAutostashFromConfig(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.writeFile(".gitext", "workflow:\n  autostash: true\n")
+	repo.writeFile(".git/info/exclude", ".gitext\n")
+	repo.writeFile("notes.txt", "notes\n")
+
+	if _, err := runGitext("start", "feature", "--ticket", "KWS-9", "--slug", "carry", "--from", "stage"); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if branch := strings.TrimSpace(repo.git(repo.dir, "symbolic-ref", "--short", "HEAD")); branch != "feature/KWS-9-carry" {
+		t.Errorf("expected to be on the new branch, got %s", branch)
+	}
+
+	for _, name := range []string{"staged.txt", "notes.txt"} {
+		if _, err := os.Stat(filepath.Join(repo.dir, name)); err != nil {
+			t.Errorf("expected %s to be carried over: %v", name, err)
+		}
+	}
+}
+
+func TestSyncAutostashConflictKeepsStash(t *testing.T) {
+	repo := newGitTestRepo(t)
+	makeDirty(repo)
+
+	// login.txt does not exist on stage, so the modification cannot be reapplied
+	_, err := runGitext("sync", "stage", "--autostash")
+	if err == nil || !strings.Contains(err.Error(), "conflicted") {
+		t.Fatalf("expected reapplying the stash to conflict, got %v", err)
+	}
+	if list := repo.git(repo.dir, "stash", "list"); !strings.Contains(list, "gitext autostash: gitext sync stage --autostash") {
+		t.Errorf("expected the stash to be kept, got:\n%s", list)
+	}
+}
+
+func TestAbortReappliesAutostash(t *testing.T) {
+	repo := newGitTestRepo(t)
+	conflictingUpdate(t, repo)
+	makeDirty(repo)
+
+	if _, err := runGitext("update", "feature", "--with", "stage", "--autostash"); err == nil {
+		t.Fatal("expected update to stop on conflicts")
+	}
+	if list := repo.git(repo.dir, "stash", "list"); !strings.Contains(list, "gitext autostash") {
+		t.Fatalf("expected the changes to stay stashed during the conflict, got:\n%s", list)
+	}
+
+	if _, err := runGitext("abort"); err != nil {
+		t.Fatalf("abort failed: %v", err)
+	}
+	assertDirty(t, repo)
+}
+
+func TestContinueReappliesAutostash(t *testing.T) {
+	repo := newGitTestRepo(t)
+	conflictingUpdate(t, repo)
+	makeDirty(repo)
+
+	if _, err := runGitext("update", "feature", "--with", "stage", "--autostash"); err == nil {
+		t.Fatal("expected update to stop on conflicts")
+	}
+
+	repo.writeFile("shared.txt", "stage and feature\n")
+	repo.git(repo.dir, "add", "shared.txt")
+
+	if _, err := runGitext("continue"); err != nil {
+		t.Fatalf("continue failed: %v", err)
+	}
+	assertDirty(t, repo)
+}
@@ -3,9 +3,8 @@ package commands
 import (
 	"fmt"
 
-	"github.com/imemir/gitext/pkg/config"
 	"github.com/imemir/gitext/pkg/git"
-	"github.com/imemir/gitext/pkg/ui"
+	"github.com/imemir/gitext/pkg/operation"
 	"github.com/spf13/cobra"
 )
 
@@ -13,43 +12,47 @@ func NewStatusCmd(opts *Options) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show current git status and suggest next steps",
-		Long: `Show the current branch, ahead/behind status vs stage and production,
+		Long: `Show the current branch, ahead/behind status vs each environment,
 working tree state, and suggest the next recommended command.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			output := ui.NewOutput(opts.Verbose)
-			g := git.NewGit(opts.DryRun, opts.Verbose)
+			ctx := opts.Context()
+			output, repo := ctx.Output, ctx.Repo
 
-			if err := g.ValidateGitRepo(); err != nil {
-				return ui.NewError("not in a git repository", "run this command from within a git repository")
-			}
-
-			cfg, err := config.Load()
+			cfg, err := ctx.RequireConfig()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return err
 			}
 
 			// Get current branch
-			currentBranch, err := g.GetCurrentBranch()
+			currentBranch, err := repo.GetCurrentBranch()
 			if err != nil {
 				return fmt.Errorf("failed to get current branch: %w", err)
 			}
 
+			// A rebase, merge or cherry-pick stopped on conflicts takes priority
+			if inProgress, err := repo.GetInProgressOperation(); err == nil && inProgress != "" {
+				return reportInProgress(ctx, inProgress, currentBranch)
+			}
+
 			// Check if detached HEAD
-			isDetached, err := g.IsDetachedHEAD()
-			if err == nil && isDetached {
+			isDetached := currentBranch == "HEAD"
+			output.Set("detached", isDetached)
+			if isDetached {
 				output.Warning("HEAD is detached")
 				output.Next("checkout a branch: git checkout -b <branch-name>")
 				return nil
 			}
 
 			output.Info("Current branch: %s", currentBranch)
+			output.Set("branch", currentBranch)
 
 			// Check working tree
-			isClean, err := g.IsWorkingTreeClean()
+			isClean, err := repo.IsWorkingTreeClean()
 			if err != nil {
 				return fmt.Errorf("failed to check working tree: %w", err)
 			}
 
+			output.Set("workingTree", map[string]bool{"clean": isClean})
 			if !isClean {
 				output.Warning("Working tree has uncommitted changes")
 				output.Next("commit or stash changes: git commit -am 'message' or git stash")
@@ -57,71 +60,83 @@ working tree state, and suggest the next recommended command.`,
 				output.Success("Working tree is clean")
 			}
 
-			// Validate remote
-			if err := g.ValidateRemote(cfg.Remote.Name); err != nil {
-				output.Warning("Remote '%s' not configured", cfg.Remote.Name)
-				return nil
+			// Validate remotes
+			remote := cfg.IntegrationRemote()
+			for _, name := range []string{remote, cfg.PushRemote()} {
+				if err := repo.ValidateRemote(name); err != nil {
+					output.Warning("Remote '%s' not configured", name)
+					return nil
+				}
 			}
 
 			// Fetch to get latest remote state
-			output.Verbose("Fetching from remote...")
-			if _, err := g.RunWithTimeout("fetch", cfg.Remote.Name); err != nil {
-				output.Warning("Failed to fetch from remote: %v", err)
+			if _, err := fetchRemote(ctx, cfg); err != nil {
+				output.Warning("%v", gitFailure("Failed to fetch from remote", err, remote, nil))
 			}
 
-			// Check status vs remote branch
-			remoteBranchExists, err := g.RemoteBranchExists(cfg.Remote.Name, currentBranch)
+			// Check status vs remote branch: environment branches live on the
+			// integration remote, feature branches on the push remote
+			branchRemote := cfg.PushRemote()
+			if cfg.EnvironmentForBranch(currentBranch) != nil {
+				branchRemote = remote
+			}
+			remoteBranchExists, err := repo.TrackingBranchExists(branchRemote, currentBranch)
 			if err == nil && remoteBranchExists {
-				ahead, behind, err := g.GetAheadBehind(cfg.Remote.Name, currentBranch)
+				ahead, behind, err := repo.GetAheadBehind(branchRemote, currentBranch)
 				if err == nil {
+					output.Set("upstream", aheadBehind{
+						Ref:    fmt.Sprintf("%s/%s", branchRemote, currentBranch),
+						Ahead:  ahead,
+						Behind: behind,
+					})
 					if ahead > 0 {
-						output.Info("Ahead of %s/%s by %d commit(s)", cfg.Remote.Name, currentBranch, ahead)
+						output.Info("Ahead of %s/%s by %d commit(s)", branchRemote, currentBranch, ahead)
 					}
 					if behind > 0 {
-						output.Warning("Behind %s/%s by %d commit(s)", cfg.Remote.Name, currentBranch, behind)
-						output.Next("sync with remote: gitext sync %s", currentBranch)
+						output.Warning("Behind %s/%s by %d commit(s)", branchRemote, currentBranch, behind)
+						if env := cfg.EnvironmentForBranch(currentBranch); env != nil {
+							output.Next("sync with remote: gitext sync %s", env.Name)
+						} else {
+							output.Next("sync with remote: git pull --ff-only %s %s", branchRemote, currentBranch)
+						}
 					}
 					if ahead > 0 && behind == 0 {
-						output.Next("push changes: git push %s %s", cfg.Remote.Name, currentBranch)
+						output.Next("push changes: gitext push")
 					}
 				}
 			}
 
-			// Check status vs stage
-			if currentBranch != cfg.Branch.Stage {
-				stageExists, err := g.RemoteBranchExists(cfg.Remote.Name, cfg.Branch.Stage)
-				if err == nil && stageExists {
-					_, behind, err := g.GetAheadBehind(cfg.Remote.Name, cfg.Branch.Stage)
-					if err == nil {
-						if behind > 0 {
-							output.Info("Behind %s by %d commit(s)", cfg.Branch.Stage, behind)
-							if isClean {
-								output.Next("update with stage: gitext update feature --with stage")
-							}
-						}
-					}
+			// Check status vs each environment
+			integration := cfg.IntegrationEnvironment()
+			environments := []environmentStatus{}
+			for _, env := range cfg.Environments {
+				if currentBranch == env.Branch {
+					continue
 				}
-			}
-
-			// Check status vs production
-			if currentBranch != cfg.Branch.Production {
-				prodExists, err := g.RemoteBranchExists(cfg.Remote.Name, cfg.Branch.Production)
-				if err == nil && prodExists {
-					_, behind, err := g.GetAheadBehind(cfg.Remote.Name, cfg.Branch.Production)
-					if err == nil {
-						if behind > 0 {
-							output.Info("Behind %s by %d commit(s)", cfg.Branch.Production, behind)
-						}
+				envExists, err := repo.TrackingBranchExists(remote, env.Branch)
+				if err != nil || !envExists {
+					continue
+				}
+				_, behind, err := repo.GetAheadBehind(remote, env.Branch)
+				if err == nil {
+					environments = append(environments, environmentStatus{Name: env.Name, Branch: env.Branch, Behind: behind})
+				}
+				if err == nil && behind > 0 {
+					output.Info("Behind %s by %d commit(s)", env.Branch, behind)
+					if isClean && env.Name == integration.Name {
+						output.Next("update with %s: gitext update feature --with %s", env.Name, env.Name)
 					}
 				}
 			}
 
+			output.Set("environments", environments)
+
 			// Suggest next steps based on branch type
 			if isClean {
-				if currentBranch == cfg.Branch.Stage || currentBranch == cfg.Branch.Production {
-					output.Next("sync latest changes: gitext sync %s", currentBranch)
+				if env := cfg.EnvironmentForBranch(currentBranch); env != nil {
+					output.Next("sync latest changes: gitext sync %s", env.Name)
 				} else {
-					output.Next("prepare PR: gitext prepare pr --to stage")
+					output.Next("prepare PR: gitext prepare pr --to %s", integration.Name)
 				}
 			}
 
@@ -131,3 +146,70 @@ working tree state, and suggest the next recommended command.`,
 
 	return cmd
 }
+
+// reportInProgress describes an operation git has in progress and how to
+// finish or abort it
+func reportInProgress(ctx *Context, inProgress, currentBranch string) error {
+	output, g := ctx.Output, ctx.Git
+
+	branch := currentBranch
+	if inProgress == git.OperationRebase {
+		if rebasing, err := g.GetRebaseBranch(); err == nil && rebasing != "" {
+			branch = rebasing
+		}
+	}
+	output.Warning("%s in progress on %s", capitalize(inProgress), branch)
+	output.Set("branch", branch)
+
+	status := operationStatus{Operation: inProgress, Branch: branch, Conflicts: []string{}}
+	if path, err := operationPath(g); err == nil {
+		if state, err := operation.Load(path); err == nil && state != nil {
+			output.Info("Started by: %s", state.Command)
+			status.Command = state.Command
+			status.Target = state.Target
+		}
+	}
+
+	files, err := g.GetConflictedFiles()
+	if err != nil {
+		return fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+	if len(files) > 0 {
+		status.Conflicts = files
+		output.Warning("%d file(s) with unresolved conflicts:", len(files))
+		for _, file := range files {
+			output.Print("  - %s", file)
+		}
+		output.Next("resolve conflicts and stage them with git add, then run: gitext continue")
+	} else {
+		output.Success("All conflicts resolved")
+		output.Next("continue: gitext continue")
+	}
+	output.Next("or abort: gitext abort")
+
+	output.Set("operation", status)
+	return nil
+}
+
+// operationStatus is the in-progress operation reported in JSON output
+type operationStatus struct {
+	Operation string   `json:"operation"`
+	Branch    string   `json:"branch"`
+	Command   string   `json:"command,omitempty"`
+	Target    string   `json:"target,omitempty"`
+	Conflicts []string `json:"conflicts"`
+}
+
+// aheadBehind is the JSON form of the comparison with the upstream branch
+type aheadBehind struct {
+	Ref    string `json:"ref"`
+	Ahead  int    `json:"ahead"`
+	Behind int    `json:"behind"`
+}
+
+// environmentStatus is the JSON form of how far behind an environment the branch is
+type environmentStatus struct {
+	Name   string `json:"name"`
+	Branch string `json:"branch"`
+	Behind int    `json:"behind"`
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/git"
+)
+
+// runFakeStatus runs gitext status against repo and decodes its result
+func runFakeStatus(t *testing.T, repo *git.FakeRepository, result interface{}) {
+	t.Helper()
+	var doc bytes.Buffer
+	opts := newFakeContext(t, repo, &doc)
+
+	cmd := NewStatusCmd(opts)
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if err := opts.Context().Finish(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(doc.Bytes(), result); err != nil {
+		t.Fatalf("invalid JSON document: %v\n%s", err, doc.String())
+	}
+}
+
+func TestStatusReportsBranchState(t *testing.T) {
+	repo := &git.FakeRepository{
+		Branch: "feature/KWS-1-login",
+		Dirty:  true,
+		RemoteBranches: map[string][]string{
+			"origin": {"feature/KWS-1-login", "stage", "production"},
+		},
+		AheadBehind: map[string][2]int{
+			"origin/feature/KWS-1-login": {2, 1},
+			"origin/stage":               {3, 4},
+			"origin/production":          {5, 0},
+		},
+	}
+
+	var result struct {
+		Result struct {
+			Branch       string              `json:"branch"`
+			Detached     bool                `json:"detached"`
+			WorkingTree  map[string]bool     `json:"workingTree"`
+			Upstream     aheadBehind         `json:"upstream"`
+			Environments []environmentStatus `json:"environments"`
+		} `json:"result"`
+	}
+	runFakeStatus(t, repo, &result)
+
+	got := result.Result
+	if got.Branch != "feature/KWS-1-login" || got.Detached || got.WorkingTree["clean"] {
+		t.Errorf("unexpected branch state: %+v", got)
+	}
+	if want := (aheadBehind{Ref: "origin/feature/KWS-1-login", Ahead: 2, Behind: 1}); got.Upstream != want {
+		t.Errorf("expected upstream %+v, got %+v", want, got.Upstream)
+	}
+	want := []environmentStatus{
+		{Name: "stage", Branch: "stage", Behind: 4},
+		{Name: "production", Branch: "production", Behind: 0},
+	}
+	if !reflect.DeepEqual(got.Environments, want) {
+		t.Errorf("expected environments %+v, got %+v", want, got.Environments)
+	}
+}
+
+func TestStatusReportsDetachedHEAD(t *testing.T) {
+	repo := &git.FakeRepository{Branch: "HEAD"}
+
+	var result struct {
+		Result struct {
+			Detached bool `json:"detached"`
+		} `json:"result"`
+	}
+	runFakeStatus(t, repo, &result)
+
+	if !result.Result.Detached {
+		t.Error("expected a detached HEAD")
+	}
+}
@@ -3,95 +3,100 @@ package commands
 import (
 	"fmt"
 
-	"github.com/imemir/gitext/pkg/config"
 	"github.com/imemir/gitext/pkg/git"
-	"github.com/imemir/gitext/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
 func NewSyncCmd(opts *Options) *cobra.Command {
+	var autostash bool
+
 	cmd := &cobra.Command{
-		Use:   "sync [stage|production]",
+		Use:   "sync <environment>",
 		Short: "Safely sync a branch with its remote",
-		Long: `Fetch from remote and pull with --ff-only to safely update stage or production.
+		Long: `Fetch from remote and pull with --ff-only to safely update an environment branch
+(e.g., stage or production).
 Fails if fast-forward is not possible, suggesting an update command instead.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			target := args[0]
-			output := ui.NewOutput(opts.Verbose)
-			g := git.NewGit(opts.DryRun, opts.Verbose)
-
-			if err := g.ValidateGitRepo(); err != nil {
-				return ui.NewError("not in a git repository", "run this command from within a git repository")
-			}
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
 
-			cfg, err := config.Load()
+			cfg, err := ctx.RequireConfig()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return err
 			}
 
-			var branch string
-			switch target {
-			case "stage":
-				branch = cfg.Branch.Stage
-			case "production":
-				branch = cfg.Branch.Production
-			default:
-				return fmt.Errorf("invalid target '%s', must be 'stage' or 'production'", target)
+			env, err := resolveEnvironment(cfg, target, "target")
+			if err != nil {
+				return err
 			}
+			branch := env.Branch
 
 			// Validate remote
-			if err := g.ValidateRemote(cfg.Remote.Name); err != nil {
+			remote := cfg.IntegrationRemote()
+			if err := g.ValidateRemote(remote); err != nil {
 				return err
 			}
 
-			// Check if branch exists
-			if err := g.ValidateBranchExists(branch, cfg.Remote.Name); err != nil {
+			// Fetch from remote
+			fetched, err := fetchRemote(ctx, cfg)
+			if err != nil {
+				return gitFailure("failed to fetch", err, remote, nil)
+			}
+			if fetched {
+				output.Did("Fetched from %s", remote)
+			}
+
+			// Check if branch exists, as of the fetch
+			if err := g.ValidateBranchExists(branch, remote); err != nil {
 				return err
 			}
 
 			// Check working tree
-			isClean, err := g.IsWorkingTreeClean()
+			dirty, err := checkWorkingTree(ctx, useAutostash(cmd, cfg, autostash))
 			if err != nil {
-				return fmt.Errorf("failed to check working tree: %w", err)
-			}
-			if !isClean {
-				return ui.NewError("working tree has uncommitted changes", "commit or stash changes first")
+				return err
 			}
 
-			currentBranch, err := g.GetCurrentBranch()
+			currentBranch, err := repo.GetCurrentBranch()
 			if err != nil {
 				return fmt.Errorf("failed to get current branch: %w", err)
 			}
 
+			if dirty {
+				if err := stashChanges(ctx, commandLine(cmd, args)); err != nil {
+					return err
+				}
+			}
+
+			if _, err := recordJournal(ctx, commandLine(cmd, args), branch); err != nil {
+				return err
+			}
+
 			// Checkout branch if not already on it
 			if currentBranch != branch {
 				output.Doing("Checking out %s", branch)
-				if _, err := g.RunWithTimeout("checkout", branch); err != nil {
-					return fmt.Errorf("failed to checkout %s: %w", branch, err)
+				if err := checkoutBranch(g, remote, branch); err != nil {
+					return gitFailure(fmt.Sprintf("failed to checkout %s", branch), err, remote, nil)
 				}
 				output.Did("Checked out %s", branch)
 			}
 
-			// Fetch from remote
-			output.Doing("Fetching from %s", cfg.Remote.Name)
-			if _, err := g.RunWithTimeout("fetch", cfg.Remote.Name); err != nil {
-				return fmt.Errorf("failed to fetch: %w", err)
-			}
-			output.Did("Fetched from %s", cfg.Remote.Name)
-
-			// Pull with --ff-only
+			// Pull with --ff-only, or fast-forward to the remote-tracking
+			// branch when the remote was not fetched
 			output.Doing("Pulling with --ff-only")
-			remoteRef := fmt.Sprintf("%s/%s", cfg.Remote.Name, branch)
-			if _, err := g.RunWithTimeout("pull", "--ff-only", cfg.Remote.Name, branch); err != nil {
+			remoteRef := fmt.Sprintf("%s/%s", remote, branch)
+			if _, err := fastForward(g, fetched, remote, branch); err != nil {
 				output.Error("Fast-forward pull failed")
-				output.Next("branch has diverged, run: git pull --rebase %s %s", cfg.Remote.Name, branch)
-				return fmt.Errorf("fast-forward not possible: %w", err)
+				return gitFailure("fast-forward not possible", err, remote, map[git.ErrorKind]string{
+					git.ErrorNonFastForward: fmt.Sprintf("branch has diverged, run: git pull --rebase %s %s", remote, branch),
+				})
 			}
 			output.Did("Pulled %s", remoteRef)
 
 			// Show status
-			ahead, behind, err := g.GetAheadBehind(cfg.Remote.Name, branch)
+			ahead, behind, err := repo.GetAheadBehind(remote, branch)
 			if err == nil {
 				if ahead == 0 && behind == 0 {
 					output.Success("%s is up to date with %s", branch, remoteRef)
@@ -100,11 +105,16 @@ Fails if fast-forward is not possible, suggesting an update command instead.`,
 				}
 			}
 
+			if err := restoreAutostash(ctx, ctx.stash); err != nil {
+				return err
+			}
 			output.Next("continue working or run: gitext status")
 
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&autostash, "autostash", false, "Stash uncommitted changes, including untracked files, and reapply them afterwards (default: workflow.autostash)")
+
 	return cmd
 }
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/imemir/gitext/pkg/journal"
+	"github.com/imemir/gitext/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+func NewUndoCmd(opts *Options) *cobra.Command {
+	var list, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "undo [id]",
+		Short: "Undo a previous update, retarget, sync or cleanup",
+		Long: `Restore the branches a gitext command moved or deleted, using the journal kept in
+.git/gitext/journal. update, retarget, sync and cleanup --hard record an entry
+before they change anything, including the tips of branches cleanup deletes.
+
+Without an id, undoes the most recent entry that has not been undone.
+Undo records its own entry, so an undo can itself be undone.
+Use --list to show the journal.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
+
+			if _, err := ctx.RequireConfig(); err != nil {
+				return err
+			}
+
+			j, err := openJournal(g)
+			if err != nil {
+				return err
+			}
+
+			if list {
+				return listJournal(ctx, j)
+			}
+
+			// Pick the entry to undo
+			var entry *journal.Entry
+			if len(args) == 1 {
+				entry, err = j.Get(args[0])
+			} else {
+				entry, err = j.Latest()
+			}
+			if errors.Is(err, journal.ErrNotFound) {
+				if len(args) == 1 {
+					return ui.NewError(fmt.Sprintf("journal entry %s not found", args[0]), "list entries with: gitext undo --list")
+				}
+				return ui.NewError("nothing to undo", "list entries with: gitext undo --list")
+			}
+			if err != nil {
+				return err
+			}
+			if entry.Undone() {
+				return ui.NewError(fmt.Sprintf("%s was already undone by %s", entry.ID, entry.UndoneBy),
+					fmt.Sprintf("redo it with: gitext undo %s", entry.UndoneBy))
+			}
+
+			// Check working tree
+			isClean, err := repo.IsWorkingTreeClean()
+			if err != nil {
+				return fmt.Errorf("failed to check working tree: %w", err)
+			}
+			if !isClean {
+				return ui.NewError("working tree has uncommitted changes", "commit or stash changes first")
+			}
+
+			output.Info("Undoing %s: %s (%s)", entry.ID, entry.Command, entry.Time.Local().Format("2006-01-02 15:04:05"))
+			for _, branch := range sortedKeys(entry.Refs) {
+				if tip := entry.Refs[branch]; tip == "" {
+					output.Print("  - delete %s (did not exist)", branch)
+				} else {
+					output.Print("  - reset %s to %s", branch, shortSHA(tip))
+				}
+			}
+			for _, branch := range sortedKeys(entry.Deleted) {
+				output.Print("  - restore %s at %s", branch, shortSHA(entry.Deleted[branch]))
+			}
+			if entry.Branch != "" {
+				output.Print("  - check out %s", entry.Branch)
+			} else {
+				output.Print("  - check out %s (detached)", shortSHA(entry.HEAD))
+			}
+
+			if !ctx.DryRun && !yes {
+				confirmed, err := ui.PromptConfirm("Undo this command?", true)
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					output.Info("Undo cancelled")
+					return nil
+				}
+			}
+
+			if err := undoEntry(ctx, j, entry, commandLine(cmd, args)); err != nil {
+				return err
+			}
+
+			output.Set("undone", entry)
+			output.Next("run: gitext status")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&list, "list", false, "List journal entries")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+
+	return cmd
+}
+
+// undoEntry restores the refs and HEAD recorded in entry, first recording
+// the current state so the undo can itself be undone
+func undoEntry(ctx *Context, j *journal.Journal, entry *journal.Entry, command string) error {
+	output, g := ctx.Output, ctx.Git
+
+	touched := sortedKeys(entry.Refs)
+	touched = append(touched, sortedKeys(entry.Deleted)...)
+	redo, err := snapshot(g, command, touched...)
+	if err != nil {
+		return err
+	}
+	redo.Undoes = entry.ID
+	if !ctx.DryRun {
+		if err := j.Record(redo); err != nil {
+			return err
+		}
+	}
+
+	current, err := g.GetSymbolicHEAD()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	reason := fmt.Sprintf("gitext undo %s", entry.ID)
+
+	// The checked-out branch is reset with --keep so the working tree follows
+	if tip := entry.Refs[current]; current != "" && tip != "" {
+		output.Doing("Resetting %s to %s", current, shortSHA(tip))
+		if _, err := g.RunWithTimeout("reset", "--keep", tip); err != nil {
+			return fmt.Errorf("failed to reset %s: %w", current, err)
+		}
+	}
+
+	// Other branches are moved without touching the working tree
+	var created []string
+	for _, branch := range sortedKeys(entry.Refs) {
+		tip := entry.Refs[branch]
+		if tip == "" {
+			created = append(created, branch)
+			continue
+		}
+		if branch == current {
+			continue
+		}
+		output.Doing("Resetting %s to %s", branch, shortSHA(tip))
+		if _, err := g.RunWithTimeout("update-ref", "-m", reason, "refs/heads/"+branch, tip); err != nil {
+			return fmt.Errorf("failed to reset %s: %w", branch, err)
+		}
+	}
+
+	// Recreate deleted branches, leaving any that were recreated since alone
+	for _, branch := range sortedKeys(entry.Deleted) {
+		tip := entry.Deleted[branch]
+		existing, err := g.ResolveRef("refs/heads/" + branch)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", branch, err)
+		}
+		if existing != "" {
+			if existing != tip {
+				output.Warning("%s exists again at %s, not restoring %s", branch, shortSHA(existing), shortSHA(tip))
+			}
+			continue
+		}
+		output.Doing("Restoring %s at %s", branch, shortSHA(tip))
+		if _, err := g.RunWithTimeout("branch", branch, tip); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", branch, err)
+		}
+	}
+
+	// Return to where HEAD was
+	if entry.Branch != current {
+		target := entry.Branch
+		checkoutArgs := []string{"checkout", target}
+		if target == "" {
+			target = shortSHA(entry.HEAD)
+			checkoutArgs = []string{"checkout", "--detach", entry.HEAD}
+		}
+		output.Doing("Checking out %s", target)
+		if _, err := g.RunWithTimeout(checkoutArgs...); err != nil {
+			return fmt.Errorf("failed to checkout %s: %w", target, err)
+		}
+	}
+
+	// Branches the command created did not exist before
+	for _, branch := range created {
+		if _, err := g.RunWithTimeout("branch", "-D", branch); err != nil {
+			output.Warning("Failed to delete %s: %v", branch, err)
+		}
+	}
+
+	if !ctx.DryRun {
+		entry.UndoneBy = redo.ID
+		if err := j.Save(entry); err != nil {
+			return err
+		}
+		// Undoing an undo makes the original entry current again
+		if entry.Undoes != "" {
+			if original, err := j.Get(entry.Undoes); err == nil && original.UndoneBy == entry.ID {
+				original.UndoneBy = ""
+				if err := j.Save(original); err != nil {
+					return err
+				}
+			}
+		}
+		output.Did("Undid %s (redo with: gitext undo %s)", entry.ID, redo.ID)
+	}
+
+	return nil
+}
+
+// listJournal prints the journal, newest first
+func listJournal(ctx *Context, j *journal.Journal) error {
+	output := ctx.Output
+
+	entries, err := j.List()
+	if err != nil {
+		return err
+	}
+	output.Set("entries", entries)
+
+	if len(entries) == 0 {
+		output.Info("Journal is empty")
+		return nil
+	}
+
+	for _, entry := range entries {
+		line := fmt.Sprintf("%s  %s  %s", entry.ID, entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Command)
+		if entry.Undone() {
+			line += fmt.Sprintf("  (undone by %s)", entry.UndoneBy)
+		}
+		output.Print("%s", line)
+	}
+	output.Next("undo the latest entry: gitext undo")
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/journal"
+)
+
+func TestUndoUpdate(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-1 add staged file")
+	before := repo.state()
+
+	if _, err := runGitext("update", "feature", "--with", "stage"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if repo.state() == before {
+		t.Fatal("update did not change the repository")
+	}
+
+	if _, err := runGitext("undo", "--yes"); err != nil {
+		t.Fatalf("undo failed: %v", err)
+	}
+	if after := repo.state(); after != before {
+		t.Fatalf("undo did not restore the repository\nbefore:\n%s\nafter:\n%s", before, after)
+	}
+
+	if _, err := runGitext("undo", "--yes"); err == nil || !strings.Contains(err.Error(), "nothing to undo") {
+		t.Errorf("expected nothing to undo, got %v", err)
+	}
+}
+
+func TestUndoRestoresDeletedBranches(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-1 add staged file")
+	repo.git(repo.dir, "branch", "feature/KWS-4-merged", "production")
+	before := repo.state()
+
+	if _, err := runGitext("cleanup", "--hard"); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	if strings.Contains(repo.git(repo.dir, "branch", "--list"), "feature/KWS-4-merged") {
+		t.Fatal("cleanup did not delete the merged branch")
+	}
+
+	if _, err := runGitext("undo", "--list"); err != nil {
+		t.Fatalf("undo --list failed: %v", err)
+	}
+
+	if _, err := runGitext("undo", "--yes"); err != nil {
+		t.Fatalf("undo failed: %v", err)
+	}
+	if after := repo.state(); after != before {
+		t.Fatalf("undo did not restore deleted branches\nbefore:\n%s\nafter:\n%s", before, after)
+	}
+}
+
+func TestCleanupJournalsOnlyDeletedBranches(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.git(repo.dir, "commit", "-q", "-m", "KWS-1 add staged file")
+	repo.git(repo.dir, "branch", "feature/KWS-4-merged", "production")
+	dirty := filepath.Join(filepath.Dir(repo.dir), "dirty")
+	repo.git(repo.dir, "worktree", "add", "-q", "-b", "feature/KWS-7-wip", dirty, "stage")
+	if err := os.WriteFile(filepath.Join(dirty, "wip.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	j := journal.New(filepath.Join(repo.dir, ".git", "gitext", "journal"))
+
+	if _, err := runGitext("cleanup", "--hard"); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	entry, err := j.Latest()
+	if err != nil {
+		t.Fatalf("expected a journal entry: %v", err)
+	}
+	if _, ok := entry.Deleted["feature/KWS-4-merged"]; !ok || len(entry.Deleted) != 1 {
+		t.Errorf("expected only the deleted branch in the entry, got %v", entry.Deleted)
+	}
+
+	// The kept branch is all that is left, so nothing is recorded
+	if _, err := runGitext("cleanup", "--hard"); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	entries, err := j.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected no entry for a cleanup that deleted nothing, got %d entries", len(entries))
+	}
+}
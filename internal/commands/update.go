@@ -2,22 +2,19 @@ package commands
 
 import (
 	"fmt"
-	"regexp"
-	"strings"
 
-	"github.com/imemir/gitext/pkg/config"
-	"github.com/imemir/gitext/pkg/git"
-	"github.com/imemir/gitext/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
 func NewUpdateCmd(opts *Options) *cobra.Command {
 	var with, mode string
+	var iKnowWhatImDoing, autostash bool
 
 	cmd := &cobra.Command{
 		Use:   "update feature",
-		Short: "Update feature branch with changes from stage or production",
-		Long: `Update the current feature branch with changes from stage or production.
+		Short: "Update feature branch with changes from an environment",
+		Long: `Update the current feature branch with changes from an environment branch
+(e.g., stage or production).
 Uses rebase or merge based on the --mode flag.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -25,22 +22,15 @@ Uses rebase or merge based on the --mode flag.`,
 				return fmt.Errorf("only 'feature' is supported currently")
 			}
 
-			output := ui.NewOutput(opts.Verbose)
-			g := git.NewGit(opts.DryRun, opts.Verbose)
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
 
-			if err := g.ValidateGitRepo(); err != nil {
-				return ui.NewError("not in a git repository", "run this command from within a git repository")
-			}
-
-			cfg, err := config.Load()
+			cfg, err := ctx.RequireConfig()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return err
 			}
 
 			// Validate flags
-			if with == "" {
-				return fmt.Errorf("--with is required (stage or production)")
-			}
 			if mode == "" {
 				mode = "rebase" // default
 			}
@@ -48,86 +38,110 @@ Uses rebase or merge based on the --mode flag.`,
 				return fmt.Errorf("--mode must be 'rebase' or 'merge'")
 			}
 
-			var sourceBranch string
-			switch with {
-			case "stage":
-				sourceBranch = cfg.Branch.Stage
-			case "production":
-				sourceBranch = cfg.Branch.Production
-			default:
-				return fmt.Errorf("--with must be 'stage' or 'production'")
+			sourceEnv, err := resolveEnvironment(cfg, with, "--with")
+			if err != nil {
+				return err
 			}
+			sourceBranch := sourceEnv.Branch
 
 			// Get current branch
-			currentBranch, err := g.GetCurrentBranch()
+			currentBranch, err := repo.GetCurrentBranch()
 			if err != nil {
 				return fmt.Errorf("failed to get current branch: %w", err)
 			}
 
 			// Validate current branch is a feature branch
-			pattern := strings.ReplaceAll(cfg.Naming.Feature, "*", ".*")
-			matched, err := regexp.MatchString("^"+pattern+"$", currentBranch)
+			matched, err := matchesPattern(cfg.Naming.Feature, currentBranch)
 			if err != nil {
-				return fmt.Errorf("failed to validate branch name: %w", err)
+				return err
 			}
 			if !matched {
 				return fmt.Errorf("current branch '%s' does not match feature pattern '%s'", currentBranch, cfg.Naming.Feature)
 			}
 
-			// Validate remote
-			if err := g.ValidateRemote(cfg.Remote.Name); err != nil {
+			// Validate remotes
+			remote, pushRemote := cfg.IntegrationRemote(), cfg.PushRemote()
+			if err := g.ValidateRemote(remote); err != nil {
+				return err
+			}
+			if err := g.ValidateRemote(pushRemote); err != nil {
 				return err
 			}
 
 			// Check working tree
-			isClean, err := g.IsWorkingTreeClean()
+			dirty, err := checkWorkingTree(ctx, useAutostash(cmd, cfg, autostash))
 			if err != nil {
-				return fmt.Errorf("failed to check working tree: %w", err)
-			}
-			if !isClean {
-				return ui.NewError("working tree has uncommitted changes", "commit or stash changes first")
+				return err
 			}
 
 			// Fetch latest
-			output.Doing("Fetching latest from %s", cfg.Remote.Name)
-			if _, err := g.RunWithTimeout("fetch", cfg.Remote.Name); err != nil {
-				return fmt.Errorf("failed to fetch: %w", err)
+			fetched, err := fetchRemote(ctx, cfg)
+			if err != nil {
+				return gitFailure("failed to fetch", err, remote, nil)
+			}
+
+			// Rebasing a pushed branch rewrites history others may have built on
+			remoteRef := fmt.Sprintf("%s/%s", remote, sourceBranch)
+			remoteBranchExists, err := repo.TrackingBranchExists(pushRemote, currentBranch)
+			pushed := err == nil && remoteBranchExists
+			if mode == "rebase" && pushed {
+				if err := checkShared(ctx, pushRemote, currentBranch, remoteRef, iKnowWhatImDoing); err != nil {
+					return err
+				}
+			}
+
+			if dirty {
+				if err := stashChanges(ctx, commandLine(cmd, args)); err != nil {
+					return err
+				}
+			}
+
+			// Record the branches about to move, so the update can be undone
+			entry, err := recordJournal(ctx, commandLine(cmd, args), currentBranch, sourceBranch)
+			if err != nil {
+				return err
 			}
 
 			// Update source branch first (sync)
-			output.Doing("Updating %s", sourceBranch)
-			if _, err := g.RunWithTimeout("fetch", cfg.Remote.Name, fmt.Sprintf("%s:%s", sourceBranch, sourceBranch)); err != nil {
-				output.Verbose("Note: %s may not exist locally, using remote reference", sourceBranch)
+			if fetched {
+				output.Doing("Updating %s", sourceBranch)
+				if _, err := g.RunWithTimeout("fetch", remote, fmt.Sprintf("%s:%s", sourceBranch, sourceBranch)); err != nil {
+					output.Verbose("Note: %s may not exist locally, using remote reference", sourceBranch)
+				}
 			}
 
 			// Apply changes
-			remoteRef := fmt.Sprintf("%s/%s", cfg.Remote.Name, sourceBranch)
+			state := newOperation(commandLine(cmd, args), mode, currentBranch, remoteRef, pushRemote, entry)
 			if mode == "rebase" {
+				state.ForcePush = pushed
+				if pushed {
+					if err := recordLease(ctx, pushRemote, currentBranch); err != nil {
+						return err
+					}
+				}
+				state.Summary = fmt.Sprintf("Rebased onto %s", remoteRef)
+
 				output.Doing("Rebasing onto %s", remoteRef)
 				if _, err := g.RunWithTimeout("rebase", remoteRef); err != nil {
-					output.Error("Rebase encountered conflicts")
-					output.Next("resolve conflicts, then run: git rebase --continue")
-					return fmt.Errorf("rebase failed: %w", err)
+					return pauseOperation(ctx, state, err)
 				}
-				output.Did("Rebased onto %s", remoteRef)
 			} else {
+				state.Summary = fmt.Sprintf("Merged %s", remoteRef)
+
 				output.Doing("Merging %s", remoteRef)
 				if _, err := g.RunWithTimeout("merge", remoteRef); err != nil {
-					output.Error("Merge encountered conflicts")
-					output.Next("resolve conflicts, then run: git commit")
-					return fmt.Errorf("merge failed: %w", err)
+					return pauseOperation(ctx, state, err)
 				}
-				output.Did("Merged %s", remoteRef)
 			}
 
-			output.Next("push changes: git push %s %s", cfg.Remote.Name, currentBranch)
-
-			return nil
+			return finishOperation(ctx, state)
 		},
 	}
 
-	cmd.Flags().StringVar(&with, "with", "", "Source branch to update from (stage or production)")
+	cmd.Flags().StringVar(&with, "with", "", "Source environment to update from (e.g., stage or production)")
 	cmd.Flags().StringVar(&mode, "mode", "rebase", "Update mode: rebase or merge")
+	cmd.Flags().BoolVar(&iKnowWhatImDoing, "i-know-what-im-doing", false, "Bypass shared branch safety check when rebasing")
+	cmd.Flags().BoolVar(&autostash, "autostash", false, "Stash uncommitted changes, including untracked files, and reapply them afterwards (default: workflow.autostash)")
 
 	return cmd
 }
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/imemir/gitext/pkg/config"
+	"github.com/imemir/gitext/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+// NewWorktreeCmd creates the 'worktree' command group
+func NewWorktreeCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "worktree",
+		Short: "Manage the worktrees created by gitext start --worktree",
+		Long: `Commands for the git worktrees gitext start --worktree creates under worktree.dir,
+so several branches can be checked out at once without stashing or committing.`,
+	}
+
+	// Add subcommands
+	cmd.AddCommand(NewWorktreeListCmd(opts))
+	cmd.AddCommand(NewWorktreeRemoveCmd(opts))
+	cmd.AddCommand(NewWorktreePruneCmd(opts))
+
+	return cmd
+}
+
+// worktreePath returns where start --worktree checks out branch: a
+// directory named after the branch in worktree.dir, resolved against the
+// main worktree so it is the same from every worktree
+func worktreePath(cfg *config.Config, worktrees []git.Worktree, branch string) (string, error) {
+	if len(worktrees) == 0 || !worktrees[0].Main {
+		return "", fmt.Errorf("failed to locate the main worktree")
+	}
+	return filepath.Join(cfg.WorktreeDir(worktrees[0].Path), strings.ReplaceAll(branch, "/", "-")), nil
+}
+
+// findWorktree returns the worktree with branch checked out or at path, or nil
+func findWorktree(worktrees []git.Worktree, branchOrPath string) *git.Worktree {
+	if wt := git.WorktreeForBranch(worktrees, branchOrPath); wt != nil {
+		return wt
+	}
+	path, err := filepath.Abs(branchOrPath)
+	if err != nil {
+		return nil
+	}
+	for i := range worktrees {
+		if samePath(worktrees[i].Path, path) {
+			return &worktrees[i]
+		}
+	}
+	return nil
+}
+
+// samePath reports whether two paths name the same directory, resolving
+// symlinks (e.g., /tmp on macOS) when both exist
+func samePath(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// removeWorktree removes a linked worktree. git refuses when it has
+// uncommitted changes unless force is set.
+func removeWorktree(g *git.Git, wt *git.Worktree, force bool) error {
+	args := []string{"worktree", "remove"}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, wt.Path)
+	_, err := g.RunWithTimeout(args...)
+	return err
+}
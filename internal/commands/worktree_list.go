@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewWorktreeListCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List worktrees",
+		Long:  `List the worktrees of the repository with the branch each has checked out.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := opts.Context()
+			output, repo := ctx.Output, ctx.Repo
+
+			if _, err := ctx.RequireConfig(); err != nil {
+				return err
+			}
+
+			worktrees, err := repo.ListWorktrees()
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+			output.Set("worktrees", worktrees)
+
+			for _, wt := range worktrees {
+				branch := wt.Branch
+				if branch == "" {
+					branch = fmt.Sprintf("detached at %s", shortSHA(wt.HEAD))
+				}
+				var notes string
+				if wt.Main {
+					notes += " (main)"
+				}
+				if wt.Locked {
+					notes += " (locked)"
+				}
+				if wt.Prunable {
+					notes += " (missing, prune with: gitext worktree prune)"
+				}
+				output.Print("  %s  %s%s", wt.Path, branch, notes)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewWorktreePruneCmd(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Forget worktrees whose directory was deleted",
+		Long: `Remove git's records of worktrees whose directory no longer exists (e.g., deleted
+with rm -rf), so their branches can be checked out again.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
+
+			if _, err := ctx.RequireConfig(); err != nil {
+				return err
+			}
+
+			worktrees, err := repo.ListWorktrees()
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+			pruned := []string{}
+			for _, wt := range worktrees {
+				if wt.Prunable {
+					pruned = append(pruned, wt.Path)
+				}
+			}
+			output.Set("pruned", pruned)
+			if len(pruned) == 0 {
+				output.Info("No missing worktrees to prune")
+				return nil
+			}
+
+			output.Doing("Pruning %d missing worktree(s)", len(pruned))
+			if _, err := g.RunWithTimeout("worktree", "prune"); err != nil {
+				return gitFailure("failed to prune worktrees", err, "", nil)
+			}
+			for _, path := range pruned {
+				output.Print("  - %s", path)
+			}
+			output.Did("Pruned %d worktree(s)", len(pruned))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
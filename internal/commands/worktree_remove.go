@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imemir/gitext/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+func NewWorktreeRemoveCmd(opts *Options) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "remove <branch|path>",
+		Short: "Remove a worktree",
+		Long: `Remove the worktree that has the branch checked out, or the worktree at path.
+The branch itself is kept. Worktrees with uncommitted changes are only
+removed with --force.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := opts.Context()
+			output, g, repo := ctx.Output, ctx.Git, ctx.Repo
+
+			if _, err := ctx.RequireConfig(); err != nil {
+				return err
+			}
+
+			worktrees, err := repo.ListWorktrees()
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+			wt := findWorktree(worktrees, args[0])
+			if wt == nil {
+				return ui.NewError(fmt.Sprintf("no worktree for '%s'", args[0]), "list worktrees with: gitext worktree list")
+			}
+			if wt.Main {
+				return ui.NewError("the main worktree cannot be removed", "pass a linked worktree (see: gitext worktree list)")
+			}
+			if samePath(wt.Path, ctx.RepoRoot) {
+				return ui.NewError("cannot remove the worktree you are in", fmt.Sprintf("run it from another worktree, e.g.: cd %s", worktrees[0].Path))
+			}
+			output.Set("path", wt.Path)
+			output.Set("branch", wt.Branch)
+
+			output.Doing("Removing worktree %s", wt.Path)
+			if err := removeWorktree(g, wt, force); err != nil {
+				if !force && strings.Contains(err.Error(), "use --force") {
+					return ui.NewError(fmt.Sprintf("worktree %s has uncommitted changes", wt.Path),
+						fmt.Sprintf("commit or stash them first, or discard them with: gitext worktree remove --force %s", args[0]))
+				}
+				return gitFailure("failed to remove worktree", err, "", nil)
+			}
+			output.Did("Removed worktree %s", wt.Path)
+			if wt.Branch != "" {
+				output.Info("Branch %s was kept", wt.Branch)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Remove the worktree even if it has uncommitted changes")
+
+	return cmd
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStartWorktreeKeepsDirtyCheckout(t *testing.T) {
+	repo := newGitTestRepo(t)
+	before := repo.git(repo.dir, "status", "--porcelain")
+
+	if _, err := runGitext("start", "feature", "--ticket", "KWS-9", "--slug", "tree", "--from", "stage", "--worktree"); err != nil {
+		t.Fatalf("start --worktree failed: %v", err)
+	}
+	if after := repo.git(repo.dir, "status", "--porcelain"); after != before {
+		t.Errorf("expected the current checkout untouched, got\n%s", after)
+	}
+	if branch := strings.TrimSpace(repo.git(repo.dir, "branch", "--show-current")); branch != "feature/KWS-1-login" {
+		t.Errorf("expected to stay on feature/KWS-1-login, got %s", branch)
+	}
+
+	path := filepath.Join(filepath.Dir(repo.dir), "work.worktrees", "feature-KWS-9-tree")
+	if branch := strings.TrimSpace(repo.git(path, "branch", "--show-current")); branch != "feature/KWS-9-tree" {
+		t.Fatalf("expected feature/KWS-9-tree checked out in %s, got %s", path, branch)
+	}
+	if head, stage := repo.git(path, "rev-parse", "HEAD"), repo.git(repo.dir, "rev-parse", "origin/stage"); head != stage {
+		t.Errorf("expected the branch to start at origin/stage %s, got %s", stage, head)
+	}
+	if upstream := repo.git(repo.dir, "config", "--list"); strings.Contains(upstream, "branch.feature/KWS-9-tree.remote") {
+		t.Error("expected the new branch not to track the source branch")
+	}
+
+	// gitext works inside the worktree, where .git is a file
+	t.Chdir(path)
+	if _, err := runGitext("status"); err != nil {
+		t.Errorf("status in the worktree failed: %v", err)
+	}
+	if _, err := runGitext("worktree", "remove", "feature/KWS-9-tree"); err == nil {
+		t.Error("expected removing the current worktree to be refused")
+	}
+
+	t.Chdir(repo.dir)
+	if _, err := runGitext("worktree", "remove", "feature/KWS-9-tree"); err != nil {
+		t.Fatalf("worktree remove failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed", path)
+	}
+	if branches := repo.git(repo.dir, "branch", "--list", "feature/KWS-9-tree"); branches == "" {
+		t.Error("expected the branch to be kept")
+	}
+}
+
+func TestWorktreePrune(t *testing.T) {
+	repo := newGitTestRepo(t)
+	path := filepath.Join(filepath.Dir(repo.dir), "gone")
+	repo.git(repo.dir, "worktree", "add", "-q", "-b", "feature/KWS-6-gone", path, "stage")
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runGitext("worktree", "prune"); err != nil {
+		t.Fatalf("worktree prune failed: %v", err)
+	}
+	if list := repo.git(repo.dir, "worktree", "list"); strings.Contains(list, path) {
+		t.Errorf("expected the missing worktree to be pruned, got\n%s", list)
+	}
+}
+
+func TestCleanupRemovesMergedWorktrees(t *testing.T) {
+	repo := newGitTestRepo(t)
+	root := filepath.Dir(repo.dir)
+	merged := filepath.Join(root, "merged")
+	dirty := filepath.Join(root, "dirty")
+	repo.git(repo.dir, "worktree", "add", "-q", "-b", "feature/KWS-5-done", merged, "stage")
+	repo.git(repo.dir, "worktree", "add", "-q", "-b", "feature/KWS-7-wip", dirty, "stage")
+	if err := os.WriteFile(filepath.Join(dirty, "wip.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runGitext("cleanup", "--hard"); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	if _, err := os.Stat(merged); !os.IsNotExist(err) {
+		t.Errorf("expected the worktree of the merged branch to be removed")
+	}
+	if branches := repo.git(repo.dir, "branch", "--list", "feature/KWS-5-done"); branches != "" {
+		t.Errorf("expected the merged branch to be deleted, got %s", branches)
+	}
+
+	// Uncommitted work is never thrown away
+	if _, err := os.Stat(filepath.Join(dirty, "wip.txt")); err != nil {
+		t.Errorf("expected the dirty worktree to be kept: %v", err)
+	}
+	if branches := repo.git(repo.dir, "branch", "--list", "feature/KWS-7-wip"); branches == "" {
+		t.Error("expected the branch of the dirty worktree to be kept")
+	}
+}
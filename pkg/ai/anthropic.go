@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/imemir/gitext/pkg/aiconfig"
+)
+
+const (
+	anthropicAPIURL  = "https://api.anthropic.com"
+	anthropicVersion = "2023-06-01"
+	anthropicTimeout = 30 * time.Second
+)
+
+// AnthropicModels are offered by gitext ai setup
+var AnthropicModels = []Model{
+	{ID: "claude-3-5-haiku-latest", Description: "default - fast and cheap"},
+	{ID: "claude-sonnet-4-0", Description: "more capable"},
+	{ID: "claude-opus-4-0", Description: "most capable"},
+}
+
+var anthropicProvider = ProviderInfo{
+	Schema: aiconfig.Schema{
+		Name: ProviderAnthropic,
+		Fields: []aiconfig.Field{
+			{Name: "api_key", Label: "API Key", Prompt: "Enter your Anthropic API key", Required: true, Secret: true},
+			{Name: ModelField, Label: "Model", Default: AnthropicModels[0].ID},
+			{Name: "base_url", Label: "URL", Default: anthropicAPIURL, URL: true, Advanced: true},
+		},
+	},
+	Label:       "Anthropic",
+	Description: "Anthropic Messages API with Claude models (requires API key)",
+	Models: func(aiconfig.Settings) ([]Model, error) {
+		return AnthropicModels, nil
+	},
+	New: func(settings aiconfig.Settings) (Provider, error) {
+		return NewAnthropicProvider(settings["base_url"], settings["api_key"], settings[ModelField]), nil
+	},
+}
+
+// AnthropicProvider implements the Provider interface for the Anthropic
+// Messages API
+type AnthropicProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewAnthropicProvider creates a new Anthropic provider; baseURL defaults to
+// the Anthropic API
+func NewAnthropicProvider(baseURL, apiKey, model string) *AnthropicProvider {
+	if baseURL == "" {
+		baseURL = anthropicAPIURL
+	}
+	if model == "" {
+		model = AnthropicModels[0].ID
+	}
+	return &AnthropicProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		client: &http.Client{
+			Timeout: anthropicTimeout,
+		},
+	}
+}
+
+// Name returns the provider name
+func (p *AnthropicProvider) Name() string {
+	return "Anthropic"
+}
+
+// GenerateCommitMessage generates a commit message using Anthropic
+func (p *AnthropicProvider) GenerateCommitMessage(request CommitRequest) (*CommitMessage, error) {
+	content, err := p.complete(commitMessagePrompt(request))
+	if err != nil {
+		return nil, err
+	}
+	return parseCommitMessage(content)
+}
+
+// SummarizeDiff summarizes part of a large diff using Anthropic
+func (p *AnthropicProvider) SummarizeDiff(diff string) (string, error) {
+	content, err := p.complete(diffSummaryPrompt(diff))
+	if err != nil {
+		return "", err
+	}
+	return trimMessage(content), nil
+}
+
+// complete sends a prompt to the Messages API and returns the text of the
+// answer
+func (p *AnthropicProvider) complete(prompt string) (string, error) {
+	requestBody := map[string]interface{}{
+		"model": p.model,
+		"messages": []map[string]string{
+			{
+				"role":    "user",
+				"content": prompt,
+			},
+		},
+		"temperature": 0.7,
+		"max_tokens":  500,
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", p.baseURL+"/v1/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", p.apiKey)
+	req.Header.Set("Anthropic-Version", anthropicVersion)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error.Message != "" {
+			return "", fmt.Errorf("Anthropic API error: %s", errorResp.Error.Message)
+		}
+		return "", fmt.Errorf("Anthropic API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no text in response")
+	}
+
+	return text.String(), nil
+}
@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/aiconfig"
+)
+
+// anthropicServer answers /v1/messages like the Messages API and records the
+// requests it got
+func anthropicServer(t *testing.T, text string) (*httptest.Server, *[]*http.Request) {
+	t.Helper()
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.Header.Get("X-Api-Key") != "sk-ant-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`))
+			return
+		}
+		var request struct {
+			Model     string `json:"model"`
+			MaxTokens int    `json:"max_tokens"`
+			Messages  []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.MaxTokens == 0 || len(request.Messages) != 1 {
+			t.Errorf("unexpected request: %+v (%v)", request, err)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"type":    "message",
+			"role":    "assistant",
+			"model":   request.Model,
+			"content": []map[string]string{{"type": "text", "text": text}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestAnthropicGenerateCommitMessage(t *testing.T) {
+	server, requests := anthropicServer(t, `{"type": "docs", "subject": "describe providers", "body": "", "breaking": false}`)
+
+	cfg := &aiconfig.Config{Provider: ProviderAnthropic}
+	cfg.Settings()["api_key"] = "sk-ant-key"
+	cfg.Settings()["base_url"] = server.URL
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := cfg.Settings()["model"]; got != AnthropicModels[0].ID {
+		t.Errorf("expected the default model, got %q", got)
+	}
+	service, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	message, err := service.GenerateCommitMessage(CommitRequest{Diff: "diff"})
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage() error = %v", err)
+	}
+	if got := message.String(); got != "docs: describe providers" {
+		t.Errorf("message = %q", got)
+	}
+
+	request := (*requests)[0]
+	if request.URL.Path != "/v1/messages" || request.Header.Get("Anthropic-Version") != anthropicVersion {
+		t.Errorf("unexpected request %s %v", request.URL.Path, request.Header)
+	}
+}
+
+func TestAnthropicError(t *testing.T) {
+	server, _ := anthropicServer(t, "")
+
+	_, err := NewAnthropicProvider(server.URL, "wrong", "").SummarizeDiff("diff")
+	if err == nil || !strings.Contains(err.Error(), "Anthropic API error: invalid x-api-key") {
+		t.Errorf("expected the API error message, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package ai
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/imemir/gitext/pkg/aiconfig"
+)
+
+// localTimeout allows for models running on a workstation, which answer
+// much slower than hosted APIs
+const localTimeout = 2 * time.Minute
+
+var openAICompatibleProvider = ProviderInfo{
+	Schema: aiconfig.Schema{
+		Name:    ProviderOpenAICompatible,
+		Section: "openai_compatible",
+		Fields: []aiconfig.Field{
+			{Name: "base_url", Label: "URL", Prompt: "Enter base URL (e.g., http://localhost:8000/v1)", Required: true, URL: true},
+			{Name: ModelField, Label: "Model", Prompt: "Enter model name", Required: true},
+			{Name: "api_key", Label: "API Key", Prompt: "Enter API key (leave empty if the server needs none)", Secret: true},
+		},
+	},
+	Label:       "OpenAI-compatible",
+	Description: "Self-hosted server with the OpenAI API (vLLM, LM Studio, LocalAI, ...)",
+	New: func(settings aiconfig.Settings) (Provider, error) {
+		return NewOpenAICompatibleProvider(settings["base_url"], settings["api_key"], settings[ModelField]), nil
+	},
+}
+
+// NewOpenAICompatibleProvider creates a provider for a server offering the
+// OpenAI chat completions API at baseURL (e.g., http://localhost:8000/v1 for
+// vLLM, LM Studio or LocalAI). apiKey is optional.
+func NewOpenAICompatibleProvider(baseURL, apiKey, model string) *OpenAIProvider {
+	name := "OpenAI-compatible"
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		name += " (" + u.Host + ")"
+	}
+	return &OpenAIProvider{
+		name:   name,
+		url:    strings.TrimRight(baseURL, "/") + "/chat/completions",
+		apiKey: apiKey,
+		model:  model,
+		client: &http.Client{
+			Timeout: localTimeout,
+		},
+	}
+}
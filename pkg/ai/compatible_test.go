@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/aiconfig"
+)
+
+// compatibleServer answers /v1/chat/completions like the OpenAI API and
+// records the Authorization headers it got
+func compatibleServer(t *testing.T, content string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v1/chat/completions" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail": "Not Found"}`))
+			return
+		}
+		auth = append(auth, r.Header.Get("Authorization"))
+		var request struct {
+			Model string `json:"model"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Model != "qwen2.5-coder" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"message": "unknown model", "type": "invalid_request_error"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &auth
+}
+
+func TestOpenAICompatibleGenerateCommitMessage(t *testing.T) {
+	server, auth := compatibleServer(t, "```json\n{\"type\": \"fix\", \"subject\": \"handle empty diff\"}\n```")
+
+	cfg := &aiconfig.Config{Provider: ProviderOpenAICompatible}
+	cfg.Settings()["base_url"] = server.URL + "/v1/"
+	cfg.Settings()["model"] = "qwen2.5-coder"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	service, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	if name := service.GetProviderName(); !strings.HasPrefix(name, "OpenAI-compatible (127.0.0.1:") {
+		t.Errorf("GetProviderName() = %q", name)
+	}
+
+	message, err := service.GenerateCommitMessage(CommitRequest{Diff: "diff"})
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage() error = %v", err)
+	}
+	if got := message.String(); got != "fix: handle empty diff" {
+		t.Errorf("message = %q", got)
+	}
+	if len(*auth) != 1 || (*auth)[0] != "" {
+		t.Errorf("expected no Authorization header without an API key, got %q", *auth)
+	}
+}
+
+func TestOpenAICompatibleAPIKey(t *testing.T) {
+	server, auth := compatibleServer(t, "a.go: fix typo")
+
+	if _, err := NewOpenAICompatibleProvider(server.URL+"/v1", "secret", "qwen2.5-coder").SummarizeDiff("diff"); err != nil {
+		t.Fatalf("SummarizeDiff() error = %v", err)
+	}
+	if (*auth)[0] != "Bearer secret" {
+		t.Errorf("expected the API key as bearer token, got %q", (*auth)[0])
+	}
+}
+
+func TestOpenAICompatibleErrors(t *testing.T) {
+	server, _ := compatibleServer(t, "")
+
+	_, err := NewOpenAICompatibleProvider(server.URL+"/v1", "", "llama").SummarizeDiff("diff")
+	if err == nil || !strings.Contains(err.Error(), "API error: unknown model") {
+		t.Errorf("expected the API error message, got %v", err)
+	}
+
+	_, err = NewOpenAICompatibleProvider(server.URL, "", "qwen2.5-coder").SummarizeDiff("diff")
+	if err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("expected the status for a wrong base URL, got %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package ai
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultDiffExclude are files left out of the diff sent to the AI: lockfiles,
+// vendored dependencies, build output and generated code
+var DefaultDiffExclude = []string{
+	"*.lock", "package-lock.json", "pnpm-lock.yaml", "go.sum", "npm-shrinkwrap.json",
+	"vendor/**", "node_modules/**", "dist/**",
+	"*.min.js", "*.min.css", "*.map", "*.pb.go", "*_generated.go", "*.gen.go",
+}
+
+// Diff modes: how much of the diff the AI sees
+const (
+	DiffFull       = "full"       // the filtered diff
+	DiffCondensed  = "condensed"  // file stats and the changed sections of each file
+	DiffSummarized = "summarized" // file stats and AI summaries of groups of files
+)
+
+// maxSummaryChunks bounds the summarisation requests for one commit; files
+// beyond them only appear in the stats
+const maxSummaryChunks = 8
+
+// DiffOptions control what of a diff is sent to the AI
+type DiffOptions struct {
+	// Exclude are globs of files left out, in addition to DefaultDiffExclude:
+	// "*.lock" matches the base name, "vendor/**" a directory, other
+	// patterns the whole path
+	Exclude []string
+
+	// MaxHunkLines cuts longer hunks, 0 for no limit
+	MaxHunkLines int
+
+	// TokenBudget is the estimated number of tokens the diff may use, 0 for
+	// no limit
+	TokenBudget int
+}
+
+// FileDiff is the diff of one file
+type FileDiff struct {
+	Path    string
+	Header  string   // diff --git, index and ---/+++ lines
+	Hunks   []string // each starting with its @@ line
+	Binary  bool
+	Added   int
+	Deleted int
+}
+
+// Text returns the diff of the file as git prints it
+func (f *FileDiff) Text() string {
+	return strings.Join(append([]string{f.Header}, f.Hunks...), "\n")
+}
+
+// Stat returns a git diff --stat style line for the file
+func (f *FileDiff) Stat() string {
+	if f.Binary {
+		return fmt.Sprintf("%s | binary", f.Path)
+	}
+	return fmt.Sprintf("%s | +%d -%d", f.Path, f.Added, f.Deleted)
+}
+
+// PreparedDiff is the diff to generate a commit message from
+type PreparedDiff struct {
+	Text string
+	Mode string
+
+	// Files are the diffs that are described, Excluded and Truncated the
+	// paths left out and those with hunks cut
+	Files     []*FileDiff
+	Excluded  []string
+	Truncated []string
+
+	// Chunks group the files to summarise separately in DiffSummarized mode
+	Chunks [][]*FileDiff
+}
+
+// EstimateTokens approximates the tokens of text, at about four characters
+// per token
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// ParseDiff splits the output of git diff into files
+func ParseDiff(diff string) []*FileDiff {
+	var files []*FileDiff
+	var file *FileDiff
+	var header, hunk []string
+
+	flush := func() {
+		if file == nil {
+			return
+		}
+		if hunk != nil {
+			file.Hunks = append(file.Hunks, strings.Join(hunk, "\n"))
+		}
+		file.Header = strings.Join(header, "\n")
+		files = append(files, file)
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			file, header, hunk = &FileDiff{}, []string{line}, nil
+			if _, b, ok := strings.Cut(line, " b/"); ok {
+				file.Path = b
+			}
+		case file == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			if hunk != nil {
+				file.Hunks = append(file.Hunks, strings.Join(hunk, "\n"))
+			}
+			hunk = []string{line}
+		case hunk != nil:
+			hunk = append(hunk, line)
+			if strings.HasPrefix(line, "+") {
+				file.Added++
+			} else if strings.HasPrefix(line, "-") {
+				file.Deleted++
+			}
+		default:
+			header = append(header, line)
+			if strings.HasPrefix(line, "+++ b/") {
+				file.Path = strings.TrimPrefix(line, "+++ b/")
+			}
+			if strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch" {
+				file.Binary = true
+			}
+		}
+	}
+	flush()
+
+	return files
+}
+
+// PrepareDiff filters a diff for the AI. Excluded, binary and generated
+// files are left out and long hunks are cut. When the result exceeds the
+// token budget, it falls back to file stats with the changed sections of
+// each file, and when even that is too large, to chunks of files to be
+// summarised separately (see Service.PrepareDiff).
+func PrepareDiff(diff string, opts DiffOptions) *PreparedDiff {
+	prepared := &PreparedDiff{Mode: DiffFull}
+	exclude := append(append([]string{}, DefaultDiffExclude...), opts.Exclude...)
+
+	for _, file := range ParseDiff(diff) {
+		if file.Binary || isExcluded(file.Path, exclude) || isGenerated(file) {
+			prepared.Excluded = append(prepared.Excluded, file.Path)
+			continue
+		}
+		if truncateHunks(file, opts.MaxHunkLines) {
+			prepared.Truncated = append(prepared.Truncated, file.Path)
+		}
+		prepared.Files = append(prepared.Files, file)
+	}
+
+	var texts []string
+	for _, file := range prepared.Files {
+		texts = append(texts, file.Text())
+	}
+	prepared.Text = strings.Join(texts, "\n")
+	if opts.TokenBudget <= 0 || EstimateTokens(prepared.Text) <= opts.TokenBudget {
+		return prepared
+	}
+
+	prepared.Mode = DiffCondensed
+	prepared.Text = condensedDiff(prepared.Files)
+	if EstimateTokens(prepared.Text) <= opts.TokenBudget {
+		return prepared
+	}
+
+	prepared.Mode = DiffSummarized
+	prepared.Chunks = chunkFiles(prepared.Files, opts.TokenBudget)
+	prepared.Text = ""
+	return prepared
+}
+
+// isExcluded reports whether a path matches one of the globs
+func isExcluded(filePath string, globs []string) bool {
+	for _, glob := range globs {
+		if dir, ok := strings.CutSuffix(glob, "/**"); ok {
+			if strings.HasPrefix(filePath, dir+"/") || strings.Contains(filePath, "/"+dir+"/") {
+				return true
+			}
+			continue
+		}
+		target := filePath
+		if !strings.Contains(glob, "/") {
+			target = path.Base(filePath)
+		}
+		if matched, _ := path.Match(glob, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isGenerated reports whether a file carries the standard "Code generated
+// ... DO NOT EDIT." marker near its top
+func isGenerated(file *FileDiff) bool {
+	if len(file.Hunks) == 0 || !strings.HasPrefix(file.Hunks[0], "@@ -0,0 ") && !strings.HasPrefix(file.Hunks[0], "@@ -1,") {
+		return false
+	}
+	lines := strings.SplitN(file.Hunks[0], "\n", 12)
+	for _, line := range lines[1:] {
+		if strings.Contains(line, "Code generated") && strings.Contains(line, "DO NOT EDIT") {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateHunks cuts hunks longer than maxLines and reports whether any was
+func truncateHunks(file *FileDiff, maxLines int) bool {
+	if maxLines <= 0 {
+		return false
+	}
+	truncated := false
+	for i, hunk := range file.Hunks {
+		lines := strings.Split(hunk, "\n")
+		// The @@ line does not count
+		if len(lines)-1 > maxLines {
+			lines = append(lines[:maxLines+1], fmt.Sprintf("... (%d more lines)", len(lines)-1-maxLines))
+			file.Hunks[i] = strings.Join(lines, "\n")
+			truncated = true
+		}
+	}
+	return truncated
+}
+
+// condensedDiff describes files by their stats and the functions or
+// sections their hunks change, from the context git prints after @@
+func condensedDiff(files []*FileDiff) string {
+	var text strings.Builder
+	text.WriteString("The diff is too large to show. Changed files, with the sections changed in each:\n")
+	for _, file := range files {
+		text.WriteString("\n" + file.Stat() + "\n")
+		seen := make(map[string]bool)
+		for _, hunk := range file.Hunks {
+			atLine, _, _ := strings.Cut(hunk, "\n")
+			section := ""
+			if parts := strings.SplitN(atLine, "@@", 3); len(parts) == 3 {
+				section = strings.TrimSpace(parts[2])
+			}
+			if section != "" && !seen[section] {
+				seen[section] = true
+				text.WriteString("  " + section + "\n")
+			}
+		}
+	}
+	return text.String()
+}
+
+// chunkFiles groups files into chunks that fit the token budget, cutting
+// files larger than the budget on their own
+func chunkFiles(files []*FileDiff, budget int) [][]*FileDiff {
+	var chunks [][]*FileDiff
+	var chunk []*FileDiff
+	size := 0
+	for _, file := range files {
+		tokens := EstimateTokens(file.Text())
+		if tokens > budget {
+			file = truncateFile(file, budget)
+			tokens = budget
+		}
+		if size+tokens > budget && len(chunk) > 0 {
+			chunks = append(chunks, chunk)
+			chunk, size = nil, 0
+		}
+		chunk = append(chunk, file)
+		size += tokens
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// truncateFile returns a copy of file with hunks dropped from the end until
+// it fits the token budget
+func truncateFile(file *FileDiff, budget int) *FileDiff {
+	cut := *file
+	cut.Hunks = nil
+	size := EstimateTokens(file.Header)
+	for _, hunk := range file.Hunks {
+		tokens := EstimateTokens(hunk)
+		if size+tokens > budget {
+			if len(cut.Hunks) == 0 {
+				cut.Hunks = append(cut.Hunks, hunk[:max(0, (budget-size)*4)])
+			}
+			cut.Hunks = append(cut.Hunks, fmt.Sprintf("... (%d more hunks)", len(file.Hunks)-len(cut.Hunks)))
+			break
+		}
+		cut.Hunks = append(cut.Hunks, hunk)
+		size += tokens
+	}
+	return &cut
+}
@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fileDiff returns the diff of a new file with lines added to func f
+func fileDiff(path string, lines int) string {
+	diff := fmt.Sprintf("diff --git a/%s b/%s\nnew file mode 100644\nindex 0000000..1111111\n--- /dev/null\n+++ b/%s\n@@ -0,0 +1,%d @@ func f() {", path, path, path, lines)
+	for i := 0; i < lines; i++ {
+		diff += fmt.Sprintf("\n+\tline %d of %s", i, path)
+	}
+	return diff
+}
+
+func TestParseDiff(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\nindex 1111111..2222222 100644\n--- a/main.go\n+++ b/main.go\n@@ -1,3 +1,3 @@ package main\n-old\n+new\n context\n@@ -10,2 +10,3 @@ func main() {\n+added\n" +
+		"\ndiff --git a/logo.png b/logo.png\nindex 1111111..2222222 100644\nBinary files a/logo.png and b/logo.png differ\n" +
+		"diff --git a/old.txt b/old.txt\ndeleted file mode 100644\n--- a/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-gone"
+
+	files := ParseDiff(diff)
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(files))
+	}
+	if f := files[0]; f.Path != "main.go" || len(f.Hunks) != 2 || f.Added != 2 || f.Deleted != 1 || f.Binary {
+		t.Errorf("unexpected main.go diff: %+v", f)
+	}
+	if f := files[1]; f.Path != "logo.png" || !f.Binary {
+		t.Errorf("expected logo.png to be binary: %+v", f)
+	}
+	if f := files[2]; f.Path != "old.txt" || f.Deleted != 1 {
+		t.Errorf("unexpected old.txt diff: %+v", f)
+	}
+	if got := files[0].Text(); !strings.HasPrefix(diff, got) {
+		t.Errorf("Text() does not reproduce the diff:\n%s", got)
+	}
+}
+
+func TestPrepareDiffExcludes(t *testing.T) {
+	generated := "diff --git a/api.go b/api.go\n--- /dev/null\n+++ b/api.go\n@@ -0,0 +1,2 @@\n+// Code generated by protoc. DO NOT EDIT.\n+package api"
+	diff := strings.Join([]string{
+		fileDiff("main.go", 2),
+		fileDiff("go.sum", 2),
+		fileDiff("web/yarn.lock", 2),
+		fileDiff("vendor/lib/lib.go", 2),
+		fileDiff("web/node_modules/x/index.js", 2),
+		fileDiff("docs/out/index.html", 2),
+		generated,
+	}, "\n")
+
+	prepared := PrepareDiff(diff, DiffOptions{Exclude: []string{"docs/out/*"}})
+	if prepared.Mode != DiffFull {
+		t.Errorf("expected mode %s, got %s", DiffFull, prepared.Mode)
+	}
+	want := []string{"go.sum", "web/yarn.lock", "vendor/lib/lib.go", "web/node_modules/x/index.js", "docs/out/index.html", "api.go"}
+	if !reflect.DeepEqual(prepared.Excluded, want) {
+		t.Errorf("Excluded = %v, want %v", prepared.Excluded, want)
+	}
+	if prepared.Text != fileDiff("main.go", 2) {
+		t.Errorf("expected only main.go in the text, got:\n%s", prepared.Text)
+	}
+}
+
+func TestPrepareDiffTruncatesHunks(t *testing.T) {
+	prepared := PrepareDiff(fileDiff("main.go", 10), DiffOptions{MaxHunkLines: 4})
+	if !reflect.DeepEqual(prepared.Truncated, []string{"main.go"}) {
+		t.Errorf("Truncated = %v", prepared.Truncated)
+	}
+	if !strings.Contains(prepared.Text, "line 3 of main.go\n... (6 more lines)") || strings.Contains(prepared.Text, "line 4 of") {
+		t.Errorf("expected the hunk cut after 4 lines:\n%s", prepared.Text)
+	}
+}
+
+func TestPrepareDiffCondenses(t *testing.T) {
+	diff := fileDiff("a.go", 50) + "\n" + fileDiff("b.go", 50)
+	prepared := PrepareDiff(diff, DiffOptions{TokenBudget: 200})
+	if prepared.Mode != DiffCondensed {
+		t.Fatalf("expected mode %s, got %s", DiffCondensed, prepared.Mode)
+	}
+	for _, want := range []string{"a.go | +50 -0", "b.go | +50 -0", "  func f() {"} {
+		if !strings.Contains(prepared.Text, want) {
+			t.Errorf("expected %q in the condensed diff:\n%s", want, prepared.Text)
+		}
+	}
+	if EstimateTokens(prepared.Text) > 200 {
+		t.Errorf("condensed diff exceeds the budget: %d tokens", EstimateTokens(prepared.Text))
+	}
+}
+
+func TestPrepareDiffChunks(t *testing.T) {
+	var files []string
+	for i := 0; i < 30; i++ {
+		files = append(files, fileDiff(fmt.Sprintf("pkg/file%d.go", i), 8))
+	}
+	files = append(files, fileDiff("big.go", 400))
+
+	budget := 250
+	prepared := PrepareDiff(strings.Join(files, "\n"), DiffOptions{TokenBudget: budget})
+	if prepared.Mode != DiffSummarized {
+		t.Fatalf("expected mode %s, got %s", DiffSummarized, prepared.Mode)
+	}
+	count := 0
+	for _, chunk := range prepared.Chunks {
+		size := 0
+		for _, file := range chunk {
+			size += EstimateTokens(file.Text())
+			count++
+		}
+		if len(chunk) > 1 && size > budget {
+			t.Errorf("chunk of %d files exceeds the budget: %d tokens", len(chunk), size)
+		}
+	}
+	if count != 31 {
+		t.Errorf("expected every file in a chunk, got %d", count)
+	}
+	last := prepared.Chunks[len(prepared.Chunks)-1]
+	if big := last[len(last)-1]; big.Path != "big.go" || EstimateTokens(big.Text()) > budget+10 {
+		t.Errorf("expected big.go cut to the budget, got %d tokens", EstimateTokens(big.Text()))
+	}
+}
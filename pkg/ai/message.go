@@ -0,0 +1,230 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/imemir/gitext/pkg/conventional"
+)
+
+// CommitMessage is a commit message following the Conventional Commits
+// specification (https://www.conventionalcommits.org/en/v1.0.0/)
+type CommitMessage struct {
+	Type    string `json:"type"`
+	Scope   string `json:"scope,omitempty"`
+	Subject string `json:"subject"`
+	Body    string `json:"body,omitempty"`
+
+	// Breaking marks a breaking change, described by BreakingChange in the
+	// BREAKING CHANGE footer
+	Breaking       bool   `json:"breaking,omitempty"`
+	BreakingChange string `json:"breakingChange,omitempty"`
+
+	// Footers are git trailers such as Refs: KWS-123, in order
+	Footers []Footer `json:"footers,omitempty"`
+}
+
+// Footer is a "token: value" trailer at the end of a commit message
+type Footer struct {
+	Token string `json:"token"`
+	Value string `json:"value"`
+}
+
+// Header returns the first line of the message: type(scope)!: subject
+func (m *CommitMessage) Header() string {
+	if m.Type == "" {
+		return m.Subject
+	}
+	header := m.Type
+	if m.Scope != "" {
+		header += "(" + m.Scope + ")"
+	}
+	if m.Breaking {
+		header += "!"
+	}
+	return header + ": " + m.Subject
+}
+
+// String returns the full message: header, body and footers separated by
+// blank lines, with the BREAKING CHANGE footer first
+func (m *CommitMessage) String() string {
+	parts := []string{m.Header()}
+	if body := strings.TrimSpace(m.Body); body != "" {
+		parts = append(parts, body)
+	}
+
+	var footers []string
+	if m.Breaking {
+		description := m.BreakingChange
+		if description == "" {
+			description = m.Subject
+		}
+		footers = append(footers, conventional.BreakingChangeToken+": "+description)
+	}
+	for _, footer := range m.Footers {
+		footers = append(footers, footer.Token+": "+footer.Value)
+	}
+	if len(footers) > 0 {
+		parts = append(parts, strings.Join(footers, "\n"))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// SetFooter adds a footer, replacing the value of an existing one with the
+// same token
+func (m *CommitMessage) SetFooter(token, value string) {
+	for i, footer := range m.Footers {
+		if strings.EqualFold(footer.Token, token) {
+			m.Footers[i].Value = value
+			return
+		}
+	}
+	m.Footers = append(m.Footers, Footer{Token: token, Value: value})
+}
+
+// normalize trims every field and moves a BREAKING CHANGE footer into
+// Breaking and BreakingChange
+func (m *CommitMessage) normalize() {
+	m.Type = strings.ToLower(strings.TrimSpace(m.Type))
+	m.Scope = strings.TrimSpace(m.Scope)
+	m.Subject = strings.TrimSpace(m.Subject)
+	m.Body = strings.TrimSpace(m.Body)
+	m.BreakingChange = strings.TrimSpace(m.BreakingChange)
+
+	footers := m.Footers[:0]
+	for _, footer := range m.Footers {
+		footer.Token = strings.TrimSpace(footer.Token)
+		footer.Value = strings.TrimSpace(footer.Value)
+		if footer.Token == "" || footer.Value == "" {
+			continue
+		}
+		if conventional.IsBreakingChangeToken(footer.Token) {
+			m.Breaking = true
+			if m.BreakingChange == "" {
+				m.BreakingChange = footer.Value
+			}
+			continue
+		}
+		footers = append(footers, footer)
+	}
+	m.Footers = footers
+}
+
+// CommitRequest is what a commit message is generated from
+type CommitRequest struct {
+	Diff string
+
+	// Rules the message must pass; types, scopes and the length limit are
+	// given to the model
+	Rules conventional.Rules
+
+	// Ticket is added as a Refs footer (e.g., KWS-123)
+	Ticket string
+
+	// Problems found in the previous answer, for a retry
+	Problems []conventional.Problem
+}
+
+// commitMessagePrompt asks for a structured commit message for a request
+func commitMessagePrompt(request CommitRequest) string {
+	var prompt strings.Builder
+	prompt.WriteString(`You are a git commit message generator. Analyze the following git diff and generate a commit message following the Conventional Commits specification (https://www.conventionalcommits.org/en/v1.0.0/).
+
+Answer with a JSON object with these fields:
+- type: one of ` + strings.Join(request.Rules.AllowedTypes(), ", ") + `
+`)
+	if len(request.Rules.Scopes) > 0 {
+		prompt.WriteString("- scope: optional, one of " + strings.Join(request.Rules.Scopes, ", ") + ", or an empty string\n")
+	} else {
+		prompt.WriteString("- scope: optional, the area affected (e.g., auth, api, ui), or an empty string\n")
+	}
+	prompt.WriteString(`- subject: brief summary in imperative mood
+- body: what changed and why, in a few short sentences or "- " bullet lines wrapped at 72 characters; an empty string for trivial changes
+- breaking: true if the change breaks existing users (removed or renamed APIs, flags, config or behavior), otherwise false
+- breakingChange: when breaking, what breaks and how to migrate; otherwise an empty string
+- footers: other trailers as a list of {"token", "value"} objects, usually empty
+
+Rules:
+- Use lowercase for the type
+- Use imperative mood for the subject (e.g., "add feature" not "added feature")
+`)
+	if max := request.Rules.MaxSubjectLength; max > 0 {
+		prompt.WriteString(fmt.Sprintf("- Keep type(scope): subject within %d characters, without a trailing period\n", max))
+	} else {
+		prompt.WriteString("- Keep the subject concise, without a trailing period\n")
+	}
+	prompt.WriteString("- Do not repeat the subject in the body\n")
+
+	if len(request.Problems) > 0 {
+		prompt.WriteString("\nYour previous answer was rejected:\n")
+		for _, problem := range request.Problems {
+			prompt.WriteString("- " + problem.Message + "\n")
+		}
+	}
+
+	prompt.WriteString(`
+Git diff:
+` + request.Diff + `
+
+Respond with ONLY the JSON object, nothing else.`)
+	return prompt.String()
+}
+
+// diffSummaryPrompt asks for a summary of part of a large diff
+func diffSummaryPrompt(diff string) string {
+	return `You are summarizing part of a large git diff so that a commit message can be written from the summaries of all parts.
+
+For each file, write one line "path: what changed", in a few words. Mention new, removed or renamed functions, types, flags and config, and anything that breaks existing users. Do not describe formatting changes.
+
+Git diff:
+` + diff + `
+
+Respond with ONLY the lines, nothing else.`
+}
+
+// parseCommitMessage reads the model's answer: the JSON object asked for,
+// possibly in a code fence, or else a plain-text commit message
+func parseCommitMessage(content string) (*CommitMessage, error) {
+	content = trimMessage(content)
+
+	if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+		message := &CommitMessage{}
+		if err := json.Unmarshal([]byte(content[start:end+1]), message); err == nil {
+			message.normalize()
+			if message.Subject == "" {
+				return nil, fmt.Errorf("no subject in generated commit message")
+			}
+			return message, nil
+		}
+	}
+
+	content = strings.TrimSpace(strings.Trim(content, "`"))
+	if content == "" {
+		return nil, fmt.Errorf("empty commit message")
+	}
+	return parseCommitText(content), nil
+}
+
+// parseCommitText splits a plain-text commit message into its parts; a
+// first line that is not type(scope): subject becomes the subject
+func parseCommitText(text string) *CommitMessage {
+	parsed, err := conventional.Parse(conventional.Clean(text))
+	if err != nil {
+		return &CommitMessage{Subject: strings.TrimSpace(parsed.Header)}
+	}
+
+	message := &CommitMessage{
+		Type:     parsed.Type,
+		Scope:    parsed.Scope,
+		Subject:  parsed.Subject,
+		Body:     parsed.Body,
+		Breaking: parsed.Breaking,
+	}
+	for _, footer := range parsed.Footers {
+		message.Footers = append(message.Footers, Footer{Token: footer.Token, Value: footer.Value})
+	}
+	message.normalize()
+	return message
+}
@@ -0,0 +1,104 @@
+package ai
+
+import "testing"
+
+func TestParseCommitMessageJSON(t *testing.T) {
+	content := "```json\n" + `{
+  "type": "Feat",
+  "scope": "auth",
+  "subject": "drop session cookies",
+  "body": "Tokens replace cookie sessions.\n\n- remove the cookie store",
+  "breaking": true,
+  "breakingChange": "clients must send a bearer token",
+  "footers": [{"token": "Reviewed-by", "value": "Alice"}, {"token": "", "value": "ignored"}]
+}` + "\n```"
+
+	message, err := parseCommitMessage(content)
+	if err != nil {
+		t.Fatalf("parseCommitMessage() error = %v", err)
+	}
+	message.SetFooter("Refs", "KWS-123")
+
+	want := `feat(auth)!: drop session cookies
+
+Tokens replace cookie sessions.
+
+- remove the cookie store
+
+BREAKING CHANGE: clients must send a bearer token
+Reviewed-by: Alice
+Refs: KWS-123`
+	if got := message.String(); got != want {
+		t.Errorf("String() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestParseCommitMessageBreakingFooter(t *testing.T) {
+	message, err := parseCommitMessage(`{"type": "fix", "subject": "rename flag", "footers": [{"token": "BREAKING CHANGE", "value": "--to is now --into"}, {"token": "Refs", "value": "KWS-1"}]}`)
+	if err != nil {
+		t.Fatalf("parseCommitMessage() error = %v", err)
+	}
+	if !message.Breaking || message.BreakingChange != "--to is now --into" {
+		t.Errorf("expected the footer to flag a breaking change, got %+v", message)
+	}
+
+	// The Refs footer is replaced rather than repeated
+	message.SetFooter("Refs", "KWS-2")
+	want := "fix!: rename flag\n\nBREAKING CHANGE: --to is now --into\nRefs: KWS-2"
+	if got := message.String(); got != want {
+		t.Errorf("String() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestParseCommitMessageText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    CommitMessage
+	}{
+		{
+			name:    "header only",
+			content: `"docs: update readme"`,
+			want:    CommitMessage{Type: "docs", Subject: "update readme"},
+		},
+		{
+			name:    "body and footers",
+			content: "feat(api)!: remove v1\n\nThe v1 endpoints are gone.\n\nBREAKING CHANGE: use /v2\nRefs #42",
+			want: CommitMessage{
+				Type: "feat", Scope: "api", Subject: "remove v1", Body: "The v1 endpoints are gone.",
+				Breaking: true, BreakingChange: "use /v2", Footers: []Footer{{Token: "Refs", Value: "#42"}},
+			},
+		},
+		{
+			name:    "no trailers",
+			content: "fix: handle nil\n\nFirst paragraph.\n\nLast paragraph, not a trailer.",
+			want:    CommitMessage{Type: "fix", Subject: "handle nil", Body: "First paragraph.\n\nLast paragraph, not a trailer."},
+		},
+		{
+			name:    "not conventional",
+			content: "Update things",
+			want:    CommitMessage{Subject: "Update things"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCommitMessage(tt.content)
+			if err != nil {
+				t.Fatalf("parseCommitMessage() error = %v", err)
+			}
+			if got.String() != tt.want.String() || got.Breaking != tt.want.Breaking || len(got.Footers) != len(tt.want.Footers) {
+				t.Errorf("parseCommitMessage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommitMessageEmpty(t *testing.T) {
+	if _, err := parseCommitMessage("  "); err == nil {
+		t.Error("expected an error for an empty answer")
+	}
+	if _, err := parseCommitMessage(`{"type": "feat", "subject": ""}`); err == nil {
+		t.Error("expected an error for a message without subject")
+	}
+}
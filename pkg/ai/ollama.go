@@ -0,0 +1,210 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/imemir/gitext/pkg/aiconfig"
+)
+
+// DefaultOllamaURL is where Ollama listens by default
+const DefaultOllamaURL = "http://localhost:11434"
+
+var ollamaProvider = ProviderInfo{
+	Schema: aiconfig.Schema{
+		Name: ProviderOllama,
+		Fields: []aiconfig.Field{
+			{Name: "base_url", Label: "URL", Prompt: "Enter Ollama URL", Default: DefaultOllamaURL, URL: true},
+			{Name: ModelField, Label: "Model", Prompt: "Enter model name (e.g., llama3.1, pull it with 'ollama pull llama3.1')", Required: true},
+		},
+	},
+	Label:       "Ollama",
+	Description: "Local Ollama server (code never leaves your machine)",
+	Models: func(settings aiconfig.Settings) ([]Model, error) {
+		names, err := ListOllamaModels(settings["base_url"])
+		if err != nil {
+			return nil, err
+		}
+		models := make([]Model, len(names))
+		for i, name := range names {
+			models[i] = Model{ID: name}
+		}
+		return models, nil
+	},
+	New: func(settings aiconfig.Settings) (Provider, error) {
+		return NewOllamaProvider(settings["base_url"], settings[ModelField]), nil
+	},
+	Test: testOllama,
+}
+
+// testOllama checks that the model is pulled before asking it for a
+// commit message, as Ollama would otherwise only answer "not found"
+func testOllama(provider Provider, settings aiconfig.Settings) error {
+	names, err := ListOllamaModels(settings["base_url"])
+	if err != nil {
+		return err
+	}
+	model := settings[ModelField]
+	pulled := false
+	for _, name := range names {
+		if name == model || name == model+":latest" {
+			pulled = true
+		}
+	}
+	if !pulled {
+		return fmt.Errorf("model %q is not available in Ollama; pull it with: ollama pull %s", model, model)
+	}
+	return testCommitMessage(provider)
+}
+
+// OllamaProvider implements the Provider interface for a local Ollama server
+// (https://ollama.com), so that no code leaves the machine
+type OllamaProvider struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewOllamaProvider creates a new Ollama provider
+func NewOllamaProvider(baseURL, model string) *OllamaProvider {
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+	return &OllamaProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		client: &http.Client{
+			Timeout: localTimeout,
+		},
+	}
+}
+
+// Name returns the provider name
+func (p *OllamaProvider) Name() string {
+	return "Ollama"
+}
+
+// GenerateCommitMessage generates a commit message using Ollama
+func (p *OllamaProvider) GenerateCommitMessage(request CommitRequest) (*CommitMessage, error) {
+	content, err := p.complete(commitMessagePrompt(request), "json")
+	if err != nil {
+		return nil, err
+	}
+	return parseCommitMessage(content)
+}
+
+// SummarizeDiff summarizes part of a large diff using Ollama
+func (p *OllamaProvider) SummarizeDiff(diff string) (string, error) {
+	content, err := p.complete(diffSummaryPrompt(diff), "")
+	if err != nil {
+		return "", err
+	}
+	return trimMessage(content), nil
+}
+
+// complete sends a prompt to the Ollama chat API and returns the answer.
+// With format "json", Ollama constrains the model to answer valid JSON.
+func (p *OllamaProvider) complete(prompt, format string) (string, error) {
+	requestBody := map[string]interface{}{
+		"model": p.model,
+		"messages": []map[string]string{
+			{
+				"role":    "user",
+				"content": prompt,
+			},
+		},
+		"stream": false,
+		"options": map[string]interface{}{
+			"temperature": 0.7,
+			"num_predict": 500,
+		},
+	}
+	if format != "" {
+		requestBody["format"] = format
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", p.baseURL+"/api/chat", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request (is Ollama running at %s?): %w", p.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error != "" {
+			return "", fmt.Errorf("Ollama API error: %s", errorResp.Error)
+		}
+		return "", fmt.Errorf("Ollama API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if response.Message.Content == "" {
+		return "", fmt.Errorf("empty response from Ollama")
+	}
+
+	return response.Message.Content, nil
+}
+
+// ListOllamaModels returns the models pulled into the Ollama server at baseURL
+func ListOllamaModels(baseURL string) ([]string, error) {
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(strings.TrimRight(baseURL, "/") + "/api/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Ollama at %s: %w", baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama API error: status %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	models := make([]string, len(response.Models))
+	for i, model := range response.Models {
+		models[i] = model.Name
+	}
+	return models, nil
+}
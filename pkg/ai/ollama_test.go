@@ -0,0 +1,122 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/aiconfig"
+)
+
+// ollamaServer answers /api/chat with content and /api/tags with models,
+// and records the chat requests it got
+func ollamaServer(t *testing.T, content string, models ...string) (*httptest.Server, *[]map[string]interface{}) {
+	t.Helper()
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/chat":
+			var request map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+			requests = append(requests, request)
+			if request["model"] != "llama3.1" {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]string{"error": `model "` + request["model"].(string) + `" not found, try pulling it first`})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"model":   "llama3.1",
+				"message": map[string]string{"role": "assistant", "content": content},
+				"done":    true,
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/tags":
+			var list []map[string]string
+			for _, model := range models {
+				list = append(list, map[string]string{"name": model})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"models": list})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestOllamaGenerateCommitMessage(t *testing.T) {
+	server, requests := ollamaServer(t, `{"type": "feat", "scope": "auth", "subject": "add login", "body": "", "breaking": false}`)
+
+	cfg := &aiconfig.Config{Provider: ProviderOllama}
+	cfg.Settings()["base_url"] = server.URL + "/"
+	cfg.Settings()["model"] = "llama3.1"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	service, err := NewService(cfg)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	if got := service.GetProviderName(); got != "Ollama" {
+		t.Errorf("GetProviderName() = %q", got)
+	}
+
+	message, err := service.GenerateCommitMessage(CommitRequest{Diff: "diff --git a/a.go b/a.go", Ticket: "KWS-1"})
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage() error = %v", err)
+	}
+	if got, want := message.String(), "feat(auth): add login\n\nRefs: KWS-1"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("expected one request, got %d", len(*requests))
+	}
+	request := (*requests)[0]
+	if request["stream"] != false || request["format"] != "json" {
+		t.Errorf("expected a non-streaming JSON request, got %v", request)
+	}
+	if messages := request["messages"].([]interface{}); !strings.Contains(messages[0].(map[string]interface{})["content"].(string), "diff --git a/a.go b/a.go") {
+		t.Errorf("expected the diff in the prompt, got %v", messages)
+	}
+}
+
+func TestOllamaSummarizeDiff(t *testing.T) {
+	server, requests := ollamaServer(t, "a.go: add login handler\n")
+
+	summary, err := NewOllamaProvider(server.URL, "llama3.1").SummarizeDiff("diff --git a/a.go b/a.go")
+	if err != nil {
+		t.Fatalf("SummarizeDiff() error = %v", err)
+	}
+	if summary != "a.go: add login handler" {
+		t.Errorf("SummarizeDiff() = %q", summary)
+	}
+	if _, ok := (*requests)[0]["format"]; ok {
+		t.Errorf("expected a free-form answer for summaries, got %v", (*requests)[0])
+	}
+}
+
+func TestOllamaError(t *testing.T) {
+	server, _ := ollamaServer(t, "")
+
+	_, err := NewOllamaProvider(server.URL, "mistral").SummarizeDiff("diff")
+	if err == nil || !strings.Contains(err.Error(), `model "mistral" not found`) {
+		t.Errorf("expected the Ollama error, got %v", err)
+	}
+}
+
+func TestListOllamaModels(t *testing.T) {
+	server, _ := ollamaServer(t, "", "llama3.1:latest", "qwen2.5-coder:7b")
+
+	models, err := ListOllamaModels(server.URL)
+	if err != nil {
+		t.Fatalf("ListOllamaModels() error = %v", err)
+	}
+	if want := []string{"llama3.1:latest", "qwen2.5-coder:7b"}; !reflect.DeepEqual(models, want) {
+		t.Errorf("ListOllamaModels() = %v, want %v", models, want)
+	}
+}
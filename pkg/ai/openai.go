@@ -7,18 +7,50 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/imemir/gitext/pkg/aiconfig"
 )
 
 const (
-	openAIAPIURL = "https://api.openai.com/v1/chat/completions"
+	openAIAPIURL  = "https://api.openai.com/v1/chat/completions"
 	openAITimeout = 30 * time.Second
 )
 
-// OpenAIProvider implements the Provider interface for OpenAI
+// OpenAIModels are offered by gitext ai setup
+var OpenAIModels = []Model{
+	{ID: "gpt-4o", Description: "default - recommended"},
+	{ID: "gpt-4o-mini", Description: "faster, cheaper"},
+	{ID: "gpt-4-turbo"},
+	{ID: "gpt-3.5-turbo", Description: "cheapest"},
+}
+
+var openAIProvider = ProviderInfo{
+	Schema: aiconfig.Schema{
+		Name: ProviderOpenAI,
+		Fields: []aiconfig.Field{
+			{Name: "api_key", Label: "API Key", Prompt: "Enter your OpenAI API key", Required: true, Secret: true},
+			{Name: ModelField, Label: "Model", Default: "gpt-4o"},
+		},
+	},
+	Label:       "OpenAI",
+	Description: "Official OpenAI API (requires API key)",
+	Models: func(aiconfig.Settings) ([]Model, error) {
+		return OpenAIModels, nil
+	},
+	New: func(settings aiconfig.Settings) (Provider, error) {
+		return NewOpenAIProvider(settings["api_key"], settings[ModelField]), nil
+	},
+}
+
+// OpenAIProvider implements the Provider interface for OpenAI and servers
+// offering the same chat completions API (see NewOpenAICompatibleProvider)
 type OpenAIProvider struct {
-	apiKey string
-	model  string
-	client *http.Client
+	name    string
+	url     string
+	apiKey  string // not sent when empty
+	model   string
+	headers map[string]string // extra request headers, e.g. for OpenRouter
+	client  *http.Client
 }
 
 // NewOpenAIProvider creates a new OpenAI provider
@@ -27,6 +59,8 @@ func NewOpenAIProvider(apiKey, model string) *OpenAIProvider {
 		model = "gpt-4o"
 	}
 	return &OpenAIProvider{
+		name:   "OpenAI",
+		url:    openAIAPIURL,
 		apiKey: apiKey,
 		model:  model,
 		client: &http.Client{
@@ -37,32 +71,29 @@ func NewOpenAIProvider(apiKey, model string) *OpenAIProvider {
 
 // Name returns the provider name
 func (p *OpenAIProvider) Name() string {
-	return "OpenAI"
+	return p.name
 }
 
-// GenerateCommitMessage generates a commit message using OpenAI
-func (p *OpenAIProvider) GenerateCommitMessage(diff string) (string, error) {
-	prompt := `You are a git commit message generator. Analyze the following git diff and generate a commit message following the Conventional Commits specification (https://www.conventionalcommits.org/en/v1.0.0/).
-
-The commit message format should be:
-type(scope): description
-
-Where:
-- type: feat, fix, docs, style, refactor, perf, test, chore, etc.
-- scope: optional, the area affected (e.g., auth, api, ui)
-- description: brief summary in imperative mood
-
-Rules:
-- Use lowercase for the type
-- Use imperative mood for description (e.g., "add feature" not "added feature")
-- Keep description concise (max 72 characters)
-- If there are breaking changes, add "!" after type or "BREAKING CHANGE:" in body
-
-Git diff:
-` + diff + `
+// GenerateCommitMessage generates a commit message using the chat completions API
+func (p *OpenAIProvider) GenerateCommitMessage(request CommitRequest) (*CommitMessage, error) {
+	content, err := p.complete(commitMessagePrompt(request))
+	if err != nil {
+		return nil, err
+	}
+	return parseCommitMessage(content)
+}
 
-Generate ONLY the commit message header (type(scope): description), nothing else.`
+// SummarizeDiff summarizes part of a large diff using the chat completions API
+func (p *OpenAIProvider) SummarizeDiff(diff string) (string, error) {
+	content, err := p.complete(diffSummaryPrompt(diff))
+	if err != nil {
+		return "", err
+	}
+	return trimMessage(content), nil
+}
 
+// complete sends a prompt to the chat completions API and returns the answer
+func (p *OpenAIProvider) complete(prompt string) (string, error) {
 	requestBody := map[string]interface{}{
 		"model": p.model,
 		"messages": []map[string]string{
@@ -72,7 +103,7 @@ Generate ONLY the commit message header (type(scope): description), nothing else
 			},
 		},
 		"temperature": 0.7,
-		"max_tokens": 100,
+		"max_tokens":  500,
 	}
 
 	jsonData, err := json.Marshal(requestBody)
@@ -80,13 +111,18 @@ Generate ONLY the commit message header (type(scope): description), nothing else
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", openAIAPIURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest("POST", p.url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
@@ -106,10 +142,10 @@ Generate ONLY the commit message header (type(scope): description), nothing else
 				Type    string `json:"type"`
 			} `json:"error"`
 		}
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			return "", fmt.Errorf("OpenAI API error: %s", errorResp.Error.Message)
+		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error.Message != "" {
+			return "", fmt.Errorf("%s API error: %s", p.name, errorResp.Error.Message)
 		}
-		return "", fmt.Errorf("OpenAI API error: status %d, body: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("%s API error: status %d, body: %s", p.name, resp.StatusCode, string(body))
 	}
 
 	var response struct {
@@ -128,9 +164,5 @@ Generate ONLY the commit message header (type(scope): description), nothing else
 		return "", fmt.Errorf("no choices in response")
 	}
 
-	message := response.Choices[0].Message.Content
-	// Clean up the message (remove quotes, trim whitespace)
-	message = trimMessage(message)
-
-	return message, nil
+	return response.Choices[0].Message.Content, nil
 }
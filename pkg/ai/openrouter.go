@@ -1,12 +1,10 @@
 package ai
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 	"time"
+
+	"github.com/imemir/gitext/pkg/aiconfig"
 )
 
 const (
@@ -36,127 +34,48 @@ var FreeModels = []Model{
 	},
 }
 
-// OpenRouterProvider implements the Provider interface for OpenRouter
-type OpenRouterProvider struct {
-	apiKey       string
-	model        string
-	useFreeModel bool
-	client       *http.Client
+var openRouterProvider = ProviderInfo{
+	Schema: aiconfig.Schema{
+		Name: ProviderOpenRouter,
+		Fields: []aiconfig.Field{
+			{Name: "api_key", Label: "API Key", Prompt: "Enter your OpenRouter API key", Required: true, Secret: true},
+			{Name: "use_free_model", Label: "Using free model", Prompt: "Use a free model?", Default: "true", Bool: true},
+			{Name: ModelField, Label: "Model", Prompt: "Enter custom model name (e.g., anthropic/claude-3-opus)", Default: FreeModels[0].ID},
+		},
+	},
+	Label:       "OpenRouter",
+	Description: "OpenRouter API (supports free models)",
+	Models: func(settings aiconfig.Settings) ([]Model, error) {
+		if settings.Bool("use_free_model") {
+			return FreeModels, nil
+		}
+		return nil, nil
+	},
+	New: func(settings aiconfig.Settings) (Provider, error) {
+		return NewOpenRouterProvider(settings["api_key"], settings[ModelField]), nil
+	},
 }
 
-// NewOpenRouterProvider creates a new OpenRouter provider
-func NewOpenRouterProvider(apiKey, model string, useFreeModel bool) *OpenRouterProvider {
+// openRouterHeaders identify gitext to OpenRouter
+var openRouterHeaders = map[string]string{
+	"HTTP-Referer": "https://github.com/imemir/gitext",
+	"X-Title":      "gitext",
+}
+
+// NewOpenRouterProvider creates a provider for OpenRouter, which offers the
+// OpenAI chat completions API
+func NewOpenRouterProvider(apiKey, model string) *OpenAIProvider {
 	if model == "" {
 		model = FreeModels[0].ID
 	}
-	return &OpenRouterProvider{
-		apiKey:       apiKey,
-		model:        model,
-		useFreeModel: useFreeModel,
+	return &OpenAIProvider{
+		name:    "OpenRouter",
+		url:     openRouterAPIURL,
+		apiKey:  apiKey,
+		model:   model,
+		headers: openRouterHeaders,
 		client: &http.Client{
 			Timeout: openRouterTimeout,
 		},
 	}
 }
-
-// Name returns the provider name
-func (p *OpenRouterProvider) Name() string {
-	return "OpenRouter"
-}
-
-// GenerateCommitMessage generates a commit message using OpenRouter
-func (p *OpenRouterProvider) GenerateCommitMessage(diff string) (string, error) {
-	prompt := `You are a git commit message generator. Analyze the following git diff and generate a commit message following the Conventional Commits specification (https://www.conventionalcommits.org/en/v1.0.0/).
-
-The commit message format should be:
-type(scope): description
-
-Where:
-- type: feat, fix, docs, style, refactor, perf, test, chore, etc.
-- scope: optional, the area affected (e.g., auth, api, ui)
-- description: brief summary in imperative mood
-
-Rules:
-- Use lowercase for the type
-- Use imperative mood for description (e.g., "add feature" not "added feature")
-- Keep description concise (max 72 characters)
-- If there are breaking changes, add "!" after type or "BREAKING CHANGE:" in body
-
-Git diff:
-` + diff + `
-
-Generate ONLY the commit message header (type(scope): description), nothing else.`
-
-	requestBody := map[string]interface{}{
-		"model": p.model,
-		"messages": []map[string]string{
-			{
-				"role":    "user",
-				"content": prompt,
-			},
-		},
-		"temperature": 0.7,
-		"max_tokens":  100,
-	}
-
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", openRouterAPIURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+p.apiKey)
-	req.Header.Set("HTTP-Referer", "https://github.com/imemir/gitext")
-	req.Header.Set("X-Title", "gitext")
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		var errorResp struct {
-			Error struct {
-				Message string `json:"message"`
-				Type    string `json:"type"`
-			} `json:"error"`
-		}
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			return "", fmt.Errorf("OpenRouter API error: %s", errorResp.Error.Message)
-		}
-		return "", fmt.Errorf("OpenRouter API error: status %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	var response struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
-	}
-
-	message := response.Choices[0].Message.Content
-	// Clean up the message (remove quotes, trim whitespace)
-	message = trimMessage(message)
-
-	return message, nil
-}
@@ -0,0 +1,31 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenRouterSendsHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": "a.go: fix typo"}}},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewOpenRouterProvider("secret", "")
+	provider.url = server.URL
+	if _, err := provider.SummarizeDiff("diff"); err != nil {
+		t.Fatalf("SummarizeDiff() error = %v", err)
+	}
+	if got.Get("Authorization") != "Bearer secret" || got.Get("X-Title") != "gitext" || got.Get("HTTP-Referer") == "" {
+		t.Errorf("unexpected headers: %v", got)
+	}
+	if provider.Name() != "OpenRouter" {
+		t.Errorf("Name() = %q", provider.Name())
+	}
+}
@@ -3,9 +3,14 @@ package ai
 // Provider defines the interface for AI providers
 type Provider interface {
 	// GenerateCommitMessage generates a commit message based on the git diff
-	// The message should follow Conventional Commits format: type(scope): description
-	GenerateCommitMessage(diff string) (string, error)
-	
+	// The message follows Conventional Commits: type(scope): subject, an
+	// optional body, and footers including BREAKING CHANGE when flagged
+	GenerateCommitMessage(request CommitRequest) (*CommitMessage, error)
+
+	// SummarizeDiff describes part of a diff too large to send at once, for
+	// a commit message to be generated from the summaries
+	SummarizeDiff(diff string) (string, error)
+
 	// Name returns the name of the provider
 	Name() string
 }
@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"fmt"
+
+	"github.com/imemir/gitext/pkg/aiconfig"
+)
+
+// Providers
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenRouter       = "openrouter"
+	ProviderAnthropic        = "anthropic"
+	ProviderOllama           = "ollama"            // a local Ollama server
+	ProviderOpenAICompatible = "openai-compatible" // any server with the OpenAI chat completions API
+)
+
+// ModelField is the field ProviderInfo.Models offers choices for
+const ModelField = "model"
+
+// ProviderInfo declares a provider: its settings, how gitext ai setup asks
+// for them, and how to create and test it
+type ProviderInfo struct {
+	aiconfig.Schema
+
+	Label       string // e.g., OpenAI
+	Description string // shown when choosing a provider
+
+	// Models lists the models gitext ai setup offers for the model field,
+	// given the fields asked before it; nil to ask for a name
+	Models func(settings aiconfig.Settings) ([]Model, error)
+
+	// New creates the provider from validated settings
+	New func(settings aiconfig.Settings) (Provider, error)
+
+	// Test checks the connection before the configuration is saved; nil to
+	// generate a commit message for a small diff
+	Test func(provider Provider, settings aiconfig.Settings) error
+}
+
+// registry holds the providers in the order gitext ai setup offers them
+var registry []*ProviderInfo
+
+// Register adds a provider; registering a name again replaces it
+func Register(info ProviderInfo) {
+	aiconfig.RegisterSchema(info.Schema)
+	info.Schema, _ = aiconfig.LookupSchema(info.Name)
+	for i, registered := range registry {
+		if registered.Name == info.Name {
+			registry[i] = &info
+			return
+		}
+	}
+	registry = append(registry, &info)
+}
+
+// Providers returns the registered providers
+func Providers() []*ProviderInfo {
+	return registry
+}
+
+// LookupProvider returns a registered provider by name
+func LookupProvider(name string) (*ProviderInfo, bool) {
+	for _, info := range registry {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+func init() {
+	Register(openAIProvider)
+	Register(openRouterProvider)
+	Register(anthropicProvider)
+	Register(ollamaProvider)
+	Register(openAICompatibleProvider)
+}
+
+// testDiff is the small diff the default connection test sends
+const testDiff = `diff --git a/test.go b/test.go
+index 1234567..abcdefg 100644
+--- a/test.go
++++ b/test.go
+@@ -1,3 +1,5 @@
+ package main
+
++func newFunction() {
++}
+`
+
+// TestConnection checks that the configured provider answers
+func TestConnection(cfg *aiconfig.Config) error {
+	info, ok := LookupProvider(cfg.Provider)
+	if !ok {
+		return fmt.Errorf("unknown provider: %s", cfg.Provider)
+	}
+	service, err := NewService(cfg)
+	if err != nil {
+		return err
+	}
+	if info.Test != nil {
+		return info.Test(service.provider, cfg.Settings())
+	}
+	return testCommitMessage(service.provider)
+}
+
+// testCommitMessage generates a commit message for testDiff. The lint
+// rules are not applied: any message proves the connection works.
+func testCommitMessage(provider Provider) error {
+	_, err := provider.GenerateCommitMessage(CommitRequest{Diff: testDiff})
+	return err
+}
@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/aiconfig"
+)
+
+func TestRegistry(t *testing.T) {
+	var names []string
+	for _, info := range Providers() {
+		names = append(names, info.Name)
+		if info.Label == "" || info.Description == "" || info.New == nil {
+			t.Errorf("provider %s is not fully declared", info.Name)
+		}
+		if _, ok := aiconfig.LookupSchema(info.Name); !ok {
+			t.Errorf("schema of %s is not registered", info.Name)
+		}
+	}
+	if got, want := strings.Join(names, ","), "openai,openrouter,anthropic,ollama,openai-compatible"; got != want {
+		t.Errorf("Providers() = %s, want %s", got, want)
+	}
+}
+
+func TestRegisteredProvidersValidate(t *testing.T) {
+	tests := []struct {
+		provider string
+		settings aiconfig.Settings
+		wantErr  string
+		want     aiconfig.Settings
+	}{
+		{ProviderOpenAI, aiconfig.Settings{"api_key": "key"}, "", aiconfig.Settings{"model": "gpt-4o"}},
+		{ProviderOpenAI, aiconfig.Settings{}, "openai.api_key is required", nil},
+		{ProviderOpenRouter, aiconfig.Settings{"api_key": "sk-or-key"}, "", aiconfig.Settings{"use_free_model": "true", "model": FreeModels[0].ID}},
+		{ProviderAnthropic, aiconfig.Settings{"api_key": "sk-ant-key"}, "", aiconfig.Settings{"base_url": anthropicAPIURL}},
+		{ProviderOllama, aiconfig.Settings{"model": "llama3.1"}, "", aiconfig.Settings{"base_url": DefaultOllamaURL}},
+		{ProviderOllama, aiconfig.Settings{}, "ollama.model is required", nil},
+		{ProviderOpenAICompatible, aiconfig.Settings{"model": "qwen2.5-coder"}, "openai_compatible.base_url is required", nil},
+		{ProviderOpenAICompatible, aiconfig.Settings{"base_url": "gpu-box:8000", "model": "qwen2.5-coder"}, "must be an http:// or https:// URL", nil},
+	}
+
+	for _, tt := range tests {
+		cfg := &aiconfig.Config{Provider: tt.provider}
+		for name, value := range tt.settings {
+			cfg.Settings()[name] = value
+		}
+		err := cfg.Validate()
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s %v: Validate() error = %v, want %q", tt.provider, tt.settings, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %v: Validate() error = %v", tt.provider, tt.settings, err)
+			continue
+		}
+		for name, value := range tt.want {
+			if got := cfg.Settings()[name]; got != value {
+				t.Errorf("%s: expected %s = %q, got %q", tt.provider, name, value, got)
+			}
+		}
+		if _, err := NewService(cfg); err != nil {
+			t.Errorf("%s: NewService() error = %v", tt.provider, err)
+		}
+	}
+}
+
+func TestTestConnectionOllamaModelNotPulled(t *testing.T) {
+	server, requests := ollamaServer(t, "", "mistral:latest")
+
+	cfg := &aiconfig.Config{Provider: ProviderOllama}
+	cfg.Settings()["base_url"] = server.URL
+	cfg.Settings()["model"] = "llama3.1"
+
+	err := TestConnection(cfg)
+	if err == nil || !strings.Contains(err.Error(), "ollama pull llama3.1") {
+		t.Errorf("expected a hint to pull the model, got %v", err)
+	}
+	if len(*requests) != 0 {
+		t.Errorf("expected no chat request, got %d", len(*requests))
+	}
+}
+
+func TestTestConnection(t *testing.T) {
+	server, _ := ollamaServer(t, `{"type": "feat", "subject": "add newFunction"}`, "llama3.1:latest")
+
+	cfg := &aiconfig.Config{Provider: ProviderOllama}
+	cfg.Settings()["base_url"] = server.URL
+	cfg.Settings()["model"] = "llama3.1"
+
+	if err := TestConnection(cfg); err != nil {
+		t.Errorf("TestConnection() error = %v", err)
+	}
+}
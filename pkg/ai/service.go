@@ -2,8 +2,10 @@ package ai
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/imemir/gitext/pkg/aiconfig"
+	"github.com/imemir/gitext/pkg/conventional"
 )
 
 // Service manages AI providers and generates commit messages
@@ -14,39 +16,103 @@ type Service struct {
 
 // NewService creates a new AI service from configuration
 func NewService(cfg *aiconfig.Config) (*Service, error) {
-	var provider Provider
-
-	switch cfg.Provider {
-	case "openai":
-		provider = NewOpenAIProvider(cfg.OpenAI.APIKey, cfg.OpenAI.Model)
-	case "openrouter":
-		model := cfg.OpenRouter.Model
-		if cfg.OpenRouter.UseFreeModel && model == "" {
-			model = FreeModels[0].ID
-		}
-		provider = NewOpenRouterProvider(cfg.OpenRouter.APIKey, model, cfg.OpenRouter.UseFreeModel)
-	default:
+	info, ok := LookupProvider(cfg.Provider)
+	if !ok {
 		return nil, fmt.Errorf("unknown provider: %s", cfg.Provider)
 	}
 
+	provider, err := info.New(cfg.Settings())
+	if err != nil {
+		return nil, err
+	}
+
 	return &Service{
 		provider: provider,
 		config:   cfg,
 	}, nil
 }
 
-// GenerateCommitMessage generates a commit message from a git diff
-func (s *Service) GenerateCommitMessage(diff string) (string, error) {
-	if diff == "" {
-		return "", fmt.Errorf("diff is empty")
+// maxCommitAttempts is how often the model is asked for a commit message
+// that passes the lint rules
+const maxCommitAttempts = 3
+
+// GenerateCommitMessage generates a commit message from a git diff, with the
+// ticket as a Refs footer. Messages that break the lint rules are asked for
+// again, telling the model what was wrong.
+func (s *Service) GenerateCommitMessage(request CommitRequest) (*CommitMessage, error) {
+	if request.Diff == "" {
+		return nil, fmt.Errorf("diff is empty")
 	}
 
-	message, err := s.provider.GenerateCommitMessage(diff)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate commit message: %w", err)
+	for attempt := 1; ; attempt++ {
+		message, err := s.provider.GenerateCommitMessage(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate commit message: %w", err)
+		}
+		if request.Ticket != "" {
+			message.SetFooter("Refs", request.Ticket)
+		}
+
+		problems := conventional.Lint(message.String(), request.Rules)
+		if len(problems) == 0 {
+			return message, nil
+		}
+		if attempt == maxCommitAttempts {
+			return message, &InvalidMessageError{Message: message, Problems: problems}
+		}
+		request.Problems = problems
+	}
+}
+
+// InvalidMessageError is returned with the last generated message when none
+// passed the lint rules
+type InvalidMessageError struct {
+	Message  *CommitMessage
+	Problems []conventional.Problem
+}
+
+func (e *InvalidMessageError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.String()
+	}
+	return fmt.Sprintf("generated commit message is not valid after %d attempts: %s", maxCommitAttempts, strings.Join(problems, "; "))
+}
+
+// PrepareDiff filters a diff and fits it into the token budget (see
+// PrepareDiff). In DiffSummarized mode, the model summarizes each chunk of
+// files first and the prepared text is made of the summaries.
+func (s *Service) PrepareDiff(diff string, opts DiffOptions) (*PreparedDiff, error) {
+	prepared := PrepareDiff(diff, opts)
+	if prepared.Mode != DiffSummarized {
+		return prepared, nil
+	}
+
+	var text strings.Builder
+	text.WriteString("The diff is too large to show. Changed files:\n")
+	for _, file := range prepared.Files {
+		text.WriteString(file.Stat() + "\n")
+	}
+	text.WriteString("\nSummaries of the changes:\n")
+
+	for i, chunk := range prepared.Chunks {
+		if i == maxSummaryChunks {
+			text.WriteString(fmt.Sprintf("(%d more groups of files not summarized)\n", len(prepared.Chunks)-i))
+			break
+		}
+		diffs := make([]string, len(chunk))
+		for j, file := range chunk {
+			diffs[j] = file.Text()
+		}
+		summary, err := s.provider.SummarizeDiff(strings.Join(diffs, "\n"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize diff: %w", err)
+		}
+		text.WriteString(summary + "\n")
 	}
 
-	return message, nil
+	prepared.Text = text.String()
+	return prepared, nil
 }
 
 // GetProviderName returns the name of the current provider
@@ -0,0 +1,112 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/conventional"
+)
+
+// fakeProvider answers with the given messages in turn and records the
+// requests and diffs to summarize it was sent
+type fakeProvider struct {
+	answers   []string
+	requests  []CommitRequest
+	summaries []string
+}
+
+func (p *fakeProvider) GenerateCommitMessage(request CommitRequest) (*CommitMessage, error) {
+	p.requests = append(p.requests, request)
+	return parseCommitMessage(p.answers[len(p.requests)-1])
+}
+
+func (p *fakeProvider) SummarizeDiff(diff string) (string, error) {
+	p.summaries = append(p.summaries, diff)
+	return fmt.Sprintf("summary %d", len(p.summaries)), nil
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func TestGenerateCommitMessageRetries(t *testing.T) {
+	provider := &fakeProvider{answers: []string{
+		`{"type": "feature", "subject": "add login"}`,
+		`{"type": "feat", "scope": "auth", "subject": "add login"}`,
+	}}
+	service := &Service{provider: provider}
+
+	message, err := service.GenerateCommitMessage(CommitRequest{
+		Diff:   "diff",
+		Rules:  conventional.Rules{RequireTicket: true},
+		Ticket: "KWS-1",
+	})
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage() error = %v", err)
+	}
+	if got, want := message.String(), "feat(auth): add login\n\nRefs: KWS-1"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("expected one retry, got %d request(s)", len(provider.requests))
+	}
+	retry := provider.requests[1]
+	if len(retry.Problems) != 1 || retry.Problems[0].Rule != "type" {
+		t.Errorf("expected the retry to carry the type problem, got %v", retry.Problems)
+	}
+	if prompt := commitMessagePrompt(retry); !strings.Contains(prompt, `type "feature" is not one of`) {
+		t.Errorf("expected the retry prompt to explain the problem:\n%s", prompt)
+	}
+}
+
+func TestGenerateCommitMessageGivesUp(t *testing.T) {
+	provider := &fakeProvider{answers: []string{"Added login", "Added login", "Added login"}}
+	service := &Service{provider: provider}
+
+	message, err := service.GenerateCommitMessage(CommitRequest{Diff: "diff"})
+	var invalid *InvalidMessageError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected an InvalidMessageError, got %v", err)
+	}
+	if len(provider.requests) != maxCommitAttempts {
+		t.Errorf("expected %d attempts, got %d", maxCommitAttempts, len(provider.requests))
+	}
+	if message == nil || invalid.Message != message || invalid.Problems[0].Rule != "header" {
+		t.Errorf("expected the last message and its problems, got %+v", invalid)
+	}
+}
+
+func TestPrepareDiffSummarizesChunks(t *testing.T) {
+	provider := &fakeProvider{}
+	service := &Service{provider: provider}
+
+	var files []string
+	for i := 0; i < 40; i++ {
+		files = append(files, fileDiff(fmt.Sprintf("pkg/file%d.go", i), 8))
+	}
+	prepared, err := service.PrepareDiff(strings.Join(files, "\n"), DiffOptions{TokenBudget: 250})
+	if err != nil {
+		t.Fatalf("PrepareDiff() error = %v", err)
+	}
+	if prepared.Mode != DiffSummarized {
+		t.Fatalf("expected mode %s, got %s", DiffSummarized, prepared.Mode)
+	}
+	if len(prepared.Chunks) <= maxSummaryChunks {
+		t.Fatalf("expected more than %d chunks, got %d", maxSummaryChunks, len(prepared.Chunks))
+	}
+	if len(provider.summaries) != maxSummaryChunks {
+		t.Errorf("expected %d summaries, got %d", maxSummaryChunks, len(provider.summaries))
+	}
+	if !strings.Contains(provider.summaries[0], "+\tline 0 of pkg/file0.go") {
+		t.Errorf("expected the first chunk to carry the diff of file0.go:\n%s", provider.summaries[0])
+	}
+	more := fmt.Sprintf("(%d more groups of files not summarized)", len(prepared.Chunks)-maxSummaryChunks)
+	for _, want := range []string{"pkg/file0.go | +8 -0", "pkg/file39.go | +8 -0", "summary 1", "summary 8", more} {
+		if !strings.Contains(prepared.Text, want) {
+			t.Errorf("expected %q in the prepared diff:\n%s", want, prepared.Text)
+		}
+	}
+}
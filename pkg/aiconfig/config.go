@@ -2,62 +2,85 @@ package aiconfig
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
+// DefaultProvider is the provider used when none is configured
+const DefaultProvider = "openai"
+
 // Config represents the AI configuration stored in ~/.gitext/config.yaml
 type Config struct {
-	Provider string `yaml:"provider"` // "openai" or "openrouter"
-	OpenAI   struct {
-		APIKey string `yaml:"api_key"`
-		Model  string `yaml:"model"` // default: "gpt-4o"
-	} `yaml:"openai"`
-	OpenRouter struct {
-		APIKey     string `yaml:"api_key"`
-		Model      string `yaml:"model"`
-		UseFreeModel bool `yaml:"use_free_model"` // if true, use predefined free models
-	} `yaml:"openrouter"`
+	Provider string `yaml:"provider"` // one of the registered providers, e.g. "openai"
+
+	// Sections hold the settings of each provider under its section key
+	// (e.g., openai, openai_compatible), so switching providers keeps the
+	// settings of the others
+	Sections map[string]Settings `yaml:",inline"`
+}
+
+// Settings are the values of a provider's fields, by field name
+type Settings map[string]string
+
+// Bool returns a boolean field, false when unset or invalid
+func (s Settings) Bool(name string) bool {
+	value, _ := strconv.ParseBool(s[name])
+	return value
 }
 
 // DefaultConfig returns a config with default values
 func DefaultConfig() *Config {
-	cfg := &Config{}
-	cfg.Provider = "openai"
-	cfg.OpenAI.Model = "gpt-4o"
-	cfg.OpenRouter.UseFreeModel = true
-	cfg.OpenRouter.Model = "google/gemini-flash-1.5-8b"
-	return cfg
+	return &Config{Provider: DefaultProvider}
 }
 
-// Validate validates the configuration
-func (c *Config) Validate() error {
-	if c.Provider != "openai" && c.Provider != "openrouter" {
-		return fmt.Errorf("provider must be 'openai' or 'openrouter', got: %s", c.Provider)
+// Settings returns the settings of the selected provider, creating its
+// section when missing
+func (c *Config) Settings() Settings {
+	section := c.Provider
+	if schema, ok := LookupSchema(c.Provider); ok {
+		section = schema.Section
+	}
+	if c.Sections == nil {
+		c.Sections = make(map[string]Settings)
 	}
+	if c.Sections[section] == nil {
+		c.Sections[section] = make(Settings)
+	}
+	return c.Sections[section]
+}
 
-	if c.Provider == "openai" {
-		if c.OpenAI.APIKey == "" {
-			return fmt.Errorf("openai.api_key is required")
-		}
-		if !strings.HasPrefix(c.OpenAI.APIKey, "sk-") {
-			return fmt.Errorf("openai.api_key must start with 'sk-'")
-		}
-		if c.OpenAI.Model == "" {
-			c.OpenAI.Model = "gpt-4o"
-		}
+// Validate checks the selected provider's settings against its schema and
+// fills in defaults
+func (c *Config) Validate() error {
+	schema, ok := LookupSchema(c.Provider)
+	if !ok {
+		return fmt.Errorf("provider must be one of %s, got: %s", strings.Join(Providers(), ", "), c.Provider)
 	}
 
-	if c.Provider == "openrouter" {
-		if c.OpenRouter.APIKey == "" {
-			return fmt.Errorf("openrouter.api_key is required")
+	settings := c.Settings()
+	for _, field := range schema.Fields {
+		if settings[field.Name] == "" && field.Default != "" {
+			settings[field.Name] = field.Default
+		}
+		value := settings[field.Name]
+		if value == "" {
+			if field.Required {
+				return fmt.Errorf("%s.%s is required", schema.Section, field.Name)
+			}
+			continue
 		}
-		if !strings.HasPrefix(c.OpenRouter.APIKey, "sk-") {
-			return fmt.Errorf("openrouter.api_key must start with 'sk-'")
+		if field.URL {
+			if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("%s.%s must be an http:// or https:// URL, got: %s", schema.Section, field.Name, value)
+			}
 		}
-		if c.OpenRouter.Model == "" {
-			c.OpenRouter.Model = "google/gemini-flash-1.5-8b"
+		if field.Bool {
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("%s.%s must be true or false, got: %s", schema.Section, field.Name, value)
+			}
 		}
 	}
 
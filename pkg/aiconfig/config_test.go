@@ -0,0 +1,130 @@
+package aiconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func init() {
+	RegisterSchema(Schema{
+		Name:    "test-server",
+		Section: "test_server",
+		Fields: []Field{
+			{Name: "base_url", Label: "URL", Default: "http://localhost:1234", URL: true},
+			{Name: "model", Label: "Model", Required: true},
+			{Name: "api_key", Label: "API Key", Secret: true},
+			{Name: "stream", Label: "Stream", Bool: true},
+		},
+	})
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		settings Settings
+		wantErr  string
+	}{
+		{"valid", "test-server", Settings{"model": "llama3.1"}, ""},
+		{"missing required", "test-server", Settings{}, "test_server.model is required"},
+		{"bad URL", "test-server", Settings{"model": "llama3.1", "base_url": "localhost:1234"}, "test_server.base_url must be an http:// or https:// URL"},
+		{"bad bool", "test-server", Settings{"model": "llama3.1", "stream": "maybe"}, "test_server.stream must be true or false"},
+		{"unknown provider", "nope", Settings{}, "provider must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Provider: tt.provider, Sections: map[string]Settings{"test_server": tt.settings}}
+			err := cfg.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAppliesDefaults(t *testing.T) {
+	cfg := &Config{Provider: "test-server"}
+	cfg.Settings()["model"] = "llama3.1"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := cfg.Sections["test_server"]["base_url"]; got != "http://localhost:1234" {
+		t.Errorf("expected the default URL, got %q", got)
+	}
+}
+
+func TestManagerKeepsOtherSections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "provider: test-server\nopenrouter:\n  api_key: sk-or-key\n  use_free_model: true\ntest_server:\n  model: llama3.1\n  stream: true\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := &Manager{configPath: path}
+	cfg, err := manager.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Settings().Bool("stream") || cfg.Settings()["model"] != "llama3.1" {
+		t.Errorf("unexpected settings: %v", cfg.Settings())
+	}
+	if got := cfg.Sections["openrouter"]["api_key"]; got != "sk-or-key" {
+		t.Errorf("expected the openrouter section to be kept, got %v", cfg.Sections)
+	}
+
+	if err := manager.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"provider: test-server", "openrouter:", "api_key: sk-or-key", "base_url: http://localhost:1234"} {
+		if !strings.Contains(string(saved), want) {
+			t.Errorf("expected %q in the saved config:\n%s", want, saved)
+		}
+	}
+}
+
+func TestLoadOrDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	manager := &Manager{configPath: path}
+
+	cfg, err := manager.LoadOrDefault()
+	if err != nil || cfg.Provider != DefaultProvider {
+		t.Fatalf("LoadOrDefault() without a file = %+v, %v; want the defaults", cfg, err)
+	}
+
+	// The selected provider lacks its required key: setup is about to fix it
+	data := "provider: openrouter\nopenrouter:\n  use_free_model: true\ntest_server:\n  model: llama3.1\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = manager.LoadOrDefault()
+	if err != nil {
+		t.Fatalf("LoadOrDefault() error = %v", err)
+	}
+
+	// Switching providers, as ai setup does, keeps the other sections
+	cfg.Provider = "test-server"
+	cfg.Settings()["model"] = "qwen2.5"
+	if err := manager.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	saved, err := manager.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := saved.Settings()["model"]; got != "qwen2.5" {
+		t.Errorf("expected the new model, got %q", got)
+	}
+	if !saved.Sections["openrouter"].Bool("use_free_model") {
+		t.Errorf("expected the openrouter section to be kept, got %v", saved.Sections)
+	}
+}
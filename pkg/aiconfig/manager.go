@@ -29,6 +29,31 @@ func (m *Manager) Load() (*Config, error) {
 		return nil, fmt.Errorf("AI configuration not found. Run 'gitext ai setup' to configure")
 	}
 
+	cfg, err := m.read()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate config, which also applies defaults for missing values
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// LoadOrDefault loads the AI configuration to change it, or returns the
+// defaults when there is none. It is not validated: the settings of the
+// selected provider may be what is about to be fixed.
+func (m *Manager) LoadOrDefault() (*Config, error) {
+	if !m.Exists() {
+		return DefaultConfig(), nil
+	}
+	return m.read()
+}
+
+// read parses the configuration file
+func (m *Manager) read() (*Config, error) {
 	data, err := os.ReadFile(m.configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -39,22 +64,9 @@ func (m *Manager) Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	// Apply defaults for missing values
 	if cfg.Provider == "" {
-		cfg.Provider = "openai"
-	}
-	if cfg.OpenAI.Model == "" {
-		cfg.OpenAI.Model = "gpt-4o"
-	}
-	if cfg.OpenRouter.Model == "" {
-		cfg.OpenRouter.Model = "google/gemini-flash-1.5-8b"
+		cfg.Provider = DefaultProvider
 	}
-
-	// Validate config
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
-	}
-
 	return cfg, nil
 }
 
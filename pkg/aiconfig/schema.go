@@ -0,0 +1,60 @@
+package aiconfig
+
+import "sort"
+
+// Schema describes the settings of a provider
+type Schema struct {
+	// Name selects the provider in the provider setting (e.g., openai-compatible)
+	Name string
+
+	// Section is the key of its settings in the config file (e.g.,
+	// openai_compatible); the name when empty
+	Section string
+
+	// Fields in the order gitext ai setup asks for them
+	Fields []Field
+}
+
+// Field is one setting of a provider
+type Field struct {
+	Name     string // key in the config file, e.g. api_key
+	Label    string // shown by gitext ai config, e.g. "API Key"
+	Prompt   string // asked by gitext ai setup; Label when empty
+	Default  string
+	Required bool
+	Secret   bool // hidden when typed and masked when shown
+	Bool     bool // true or false, asked as a yes/no question
+	URL      bool // an http(s) URL
+
+	// Advanced fields are only set in the config file, not asked by
+	// gitext ai setup
+	Advanced bool
+}
+
+// schemas are the registered providers' schemas, by name
+var schemas = map[string]Schema{}
+
+// RegisterSchema makes a provider's schema known to Validate; providers
+// register through the ai package
+func RegisterSchema(schema Schema) {
+	if schema.Section == "" {
+		schema.Section = schema.Name
+	}
+	schemas[schema.Name] = schema
+}
+
+// LookupSchema returns the schema of a registered provider
+func LookupSchema(name string) (Schema, bool) {
+	schema, ok := schemas[name]
+	return schema, ok
+}
+
+// Providers returns the names of the registered providers, sorted
+func Providers() []string {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,82 @@
+package ci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/imemir/gitext/pkg/config"
+)
+
+// Cache records CI steps that passed for a tree, so unchanged trees do not
+// rerun them
+type Cache struct {
+	dir string
+}
+
+// cacheEntry is the record stored for a passed step
+type cacheEntry struct {
+	Tree       string        `json:"tree"`
+	Step       string        `json:"step"`
+	Command    string        `json:"command"`
+	Duration   time.Duration `json:"duration"`
+	RecordedAt time.Time     `json:"recordedAt"`
+}
+
+// NewCache creates a cache stored in dir (e.g., .git/gitext/ci-cache)
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// StepKey identifies a step run against a tree. It covers everything that
+// affects the outcome: the tree hash and the step's command, directory,
+// environment and timeout.
+func StepKey(tree string, step config.CIStep) string {
+	envKeys := make([]string, 0, len(step.Env))
+	for key := range step.Env {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "tree\x00%s\x00command\x00%s\x00dir\x00%s\x00timeout\x00%s\x00", tree, step.Command, step.Dir, step.Timeout)
+	for _, key := range envKeys {
+		fmt.Fprintf(h, "env\x00%s=%s\x00", key, step.Env[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Passed reports whether the step already passed for the tree
+func (c *Cache) Passed(tree string, step config.CIStep) bool {
+	_, err := os.Stat(c.path(StepKey(tree, step)))
+	return err == nil
+}
+
+// Record stores a passed step for the tree
+func (c *Cache) Record(tree string, result Result) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create CI cache: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cacheEntry{
+		Tree:       tree,
+		Step:       result.Step.DisplayName(),
+		Command:    result.Step.Command,
+		Duration:   result.Duration,
+		RecordedAt: time.Now(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal CI cache entry: %w", err)
+	}
+
+	return os.WriteFile(c.path(StepKey(tree, result.Step)), data, 0644)
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package ci
+
+import (
+	"os/exec"
+)
+
+// killProcessGroup is a no-op on Windows; WaitDelay bounds the wait instead
+func killProcessGroup(cmd *exec.Cmd) {}
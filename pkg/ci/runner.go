@@ -0,0 +1,249 @@
+package ci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/imemir/gitext/pkg/config"
+)
+
+// waitDelay bounds how long a cancelled step may keep its output open
+const waitDelay = 2 * time.Second
+
+// Status is the outcome of a CI step
+type Status string
+
+const (
+	StatusPassed  Status = "passed"
+	StatusFailed  Status = "failed"
+	StatusTimeout Status = "timeout"
+	StatusSkipped Status = "skipped"
+	StatusCached  Status = "cached" // passed earlier for the same tree
+)
+
+// Result is the outcome of running one CI step
+type Result struct {
+	Step     config.CIStep
+	Status   Status
+	Duration time.Duration
+	Err      error
+}
+
+// Failed reports whether the step failed or timed out
+func (r *Result) Failed() bool {
+	return r.Status == StatusFailed || r.Status == StatusTimeout
+}
+
+// MarshalJSON renders the result for --output json
+func (r Result) MarshalJSON() ([]byte, error) {
+	doc := struct {
+		Name       string `json:"name"`
+		Command    string `json:"command"`
+		Status     Status `json:"status"`
+		DurationMS int64  `json:"durationMs"`
+		Ignored    bool   `json:"ignored,omitempty"`
+		Error      string `json:"error,omitempty"`
+	}{
+		Name:       r.Step.DisplayName(),
+		Command:    r.Step.Command,
+		Status:     r.Status,
+		DurationMS: r.Duration.Milliseconds(),
+		Ignored:    r.Failed() && r.Step.ContinueOnError,
+	}
+	if r.Err != nil {
+		doc.Error = r.Err.Error()
+	}
+	return json.Marshal(doc)
+}
+
+// Runner runs CI steps through the shell
+type Runner struct {
+	Dir    string    // base directory for steps (the repository root)
+	Stdout io.Writer // receives step output
+	Stderr io.Writer
+
+	// Cache, when set, skips steps that already passed for Tree and
+	// records newly passed ones
+	Cache *Cache
+	Tree  string
+}
+
+// NewRunner creates a runner that streams step output to the terminal;
+// set Stdout to send it elsewhere
+func NewRunner(dir string) *Runner {
+	return &Runner{
+		Dir:    dir,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+}
+
+// Run runs the steps in order. Consecutive steps sharing a parallel group
+// run concurrently. After a failing step without continueOnError the
+// remaining steps are skipped. The returned error reports the first
+// failure that stopped the run.
+func (r *Runner) Run(steps []config.CIStep) ([]Result, error) {
+	results := make([]Result, len(steps))
+	var stopErr error
+
+	for start := 0; start < len(steps); {
+		end := start + 1
+		if group := steps[start].Parallel; group != "" {
+			for end < len(steps) && steps[end].Parallel == group {
+				end++
+			}
+		}
+
+		if stopErr != nil {
+			for i := start; i < end; i++ {
+				results[i] = Result{Step: steps[i], Status: StatusSkipped}
+			}
+			start = end
+			continue
+		}
+
+		if end-start == 1 {
+			results[start] = r.runStep(steps[start], r.Stdout, r.Stderr)
+		} else {
+			r.runParallel(steps[start:end], results[start:end])
+		}
+
+		for i := start; i < end; i++ {
+			if results[i].Failed() && !steps[i].ContinueOnError {
+				stopErr = fmt.Errorf("%s: %w", steps[i].DisplayName(), results[i].Err)
+				break
+			}
+		}
+		start = end
+	}
+
+	return results, stopErr
+}
+
+// runParallel runs a group of steps concurrently, buffering each step's
+// output and writing it once the step finishes so output does not interleave
+func (r *Runner) runParallel(steps []config.CIStep, results []Result) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for i := range steps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var buf bytes.Buffer
+			results[i] = r.runStep(steps[i], &buf, &buf)
+
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Fprintf(r.Stdout, "--- %s (%s)\n", steps[i].DisplayName(), results[i].Status)
+			_, _ = r.Stdout.Write(buf.Bytes())
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// runStep runs a single step through the shell
+func (r *Runner) runStep(step config.CIStep, stdout, stderr io.Writer) Result {
+	result := Result{Step: step}
+
+	if r.Cache != nil && r.Cache.Passed(r.Tree, step) {
+		result.Status = StatusCached
+		return result
+	}
+
+	timeout, err := step.TimeoutDuration()
+	if err != nil {
+		result.Status = StatusFailed
+		result.Err = err
+		return result
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := shellCommand(ctx, step.Command)
+	cmd.Dir = r.Dir
+	if step.Dir != "" {
+		cmd.Dir = step.Dir
+		if !filepath.IsAbs(step.Dir) {
+			cmd.Dir = filepath.Join(r.Dir, step.Dir)
+		}
+	}
+	cmd.Env = os.Environ()
+	for key, value := range step.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	killProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+
+	started := time.Now()
+	err = cmd.Run()
+	result.Duration = time.Since(started)
+
+	switch {
+	case err == nil:
+		result.Status = StatusPassed
+		if r.Cache != nil {
+			// Caching is best-effort; a failed write only costs a rerun
+			_ = r.Cache.Record(r.Tree, result)
+		}
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Status = StatusTimeout
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	default:
+		result.Status = StatusFailed
+		result.Err = err
+	}
+
+	return result
+}
+
+// shellCommand runs command through the platform shell so pipes, quotes,
+// && and variable expansion work as written
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// Summary renders a pass/fail table with per-step durations
+func Summary(results []Result) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "STEP\tSTATUS\tDURATION")
+	for _, result := range results {
+		status := string(result.Status)
+		if result.Failed() && result.Step.ContinueOnError {
+			status += " (ignored)"
+		}
+		duration := "-"
+		if result.Status != StatusSkipped && result.Status != StatusCached {
+			duration = result.Duration.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Step.DisplayName(), status, duration)
+	}
+	w.Flush()
+
+	return strings.TrimRight(buf.String(), "\n")
+}
@@ -0,0 +1,146 @@
+package ci
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imemir/gitext/pkg/config"
+)
+
+func newTestRunner(t *testing.T) (*Runner, *bytes.Buffer) {
+	var out bytes.Buffer
+	return &Runner{Dir: t.TempDir(), Stdout: &out, Stderr: &out}, &out
+}
+
+func TestRunShellFeatures(t *testing.T) {
+	runner, out := newTestRunner(t)
+	if err := os.Mkdir(filepath.Join(runner.Dir, "sub dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := runner.Run([]config.CIStep{
+		{Command: `echo "a b" | tr a-z A-Z && echo "$GREETING"`, Env: map[string]string{"GREETING": "hello"}},
+		{Command: "pwd", Dir: "sub dir"},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, result := range results {
+		if result.Status != StatusPassed {
+			t.Errorf("%s: status %s", result.Step.DisplayName(), result.Status)
+		}
+	}
+
+	got := out.String()
+	for _, want := range []string{"A B\n", "hello\n", "sub dir\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output %q missing %q", got, want)
+		}
+	}
+}
+
+func TestRunStopsOnFailure(t *testing.T) {
+	runner, _ := newTestRunner(t)
+
+	results, err := runner.Run([]config.CIStep{
+		{Name: "flaky", Command: "exit 1", ContinueOnError: true},
+		{Name: "broken", Command: "exit 2"},
+		{Name: "never", Command: "true"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("expected failure of 'broken', got %v", err)
+	}
+
+	want := []Status{StatusFailed, StatusFailed, StatusSkipped}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("%s: status %s, want %s", result.Step.Name, result.Status, want[i])
+		}
+	}
+
+	summary := Summary(results)
+	if !strings.Contains(summary, "failed (ignored)") || !strings.Contains(summary, "skipped") {
+		t.Errorf("unexpected summary:\n%s", summary)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	runner, _ := newTestRunner(t)
+
+	results, err := runner.Run([]config.CIStep{{Name: "slow", Command: "sleep 5", Timeout: "100ms"}})
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if results[0].Status != StatusTimeout {
+		t.Errorf("status %s, want %s", results[0].Status, StatusTimeout)
+	}
+}
+
+func TestRunParallelGroup(t *testing.T) {
+	runner, out := newTestRunner(t)
+
+	// Each step waits for the other's marker file, so they only pass when run concurrently
+	results, err := runner.Run([]config.CIStep{
+		{Name: "a", Command: "touch a; for i in $(seq 50); do [ -f b ] && exit 0; sleep 0.1; done; exit 1", Parallel: "checks"},
+		{Name: "b", Command: "touch b; for i in $(seq 50); do [ -f a ] && exit 0; sleep 0.1; done; exit 1", Parallel: "checks"},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v\n%s", err, out.String())
+	}
+	for _, result := range results {
+		if result.Status != StatusPassed {
+			t.Errorf("%s: status %s", result.Step.Name, result.Status)
+		}
+	}
+	if !strings.Contains(out.String(), "--- a (passed)") || !strings.Contains(out.String(), "--- b (passed)") {
+		t.Errorf("expected grouped output headers, got %q", out.String())
+	}
+}
+
+func TestRunWithCache(t *testing.T) {
+	runner, _ := newTestRunner(t)
+	runner.Cache = NewCache(filepath.Join(t.TempDir(), "ci-cache"))
+	runner.Tree = "tree-1"
+
+	// Each run appends to a counter file so reruns are observable
+	counter := filepath.Join(runner.Dir, "count")
+	steps := []config.CIStep{
+		{Name: "count", Command: "echo x >> " + counter},
+		{Name: "fail", Command: "exit 1", ContinueOnError: true},
+	}
+
+	if _, err := runner.Run(steps); err != nil {
+		t.Fatalf("first Run() error = %v", err)
+	}
+	results, err := runner.Run(steps)
+	if err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if results[0].Status != StatusCached {
+		t.Errorf("expected passed step to be cached, got %s", results[0].Status)
+	}
+	if results[1].Status != StatusFailed {
+		t.Errorf("expected failed step to rerun, got %s", results[1].Status)
+	}
+
+	// A different tree or a changed step misses the cache
+	runner.Tree = "tree-2"
+	if results, _ := runner.Run(steps[:1]); results[0].Status != StatusPassed {
+		t.Errorf("expected rerun for a new tree, got %s", results[0].Status)
+	}
+	steps[0].Env = map[string]string{"X": "1"}
+	if results, _ := runner.Run(steps[:1]); results[0].Status != StatusPassed {
+		t.Errorf("expected rerun for a changed step, got %s", results[0].Status)
+	}
+
+	data, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := strings.Count(string(data), "x"); runs != 3 {
+		t.Errorf("expected 3 runs, got %d", runs)
+	}
+}
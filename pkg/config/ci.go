@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CIStep is a check run before a PR into an environment
+type CIStep struct {
+	Name            string            `yaml:"name,omitempty"`
+	Command         string            `yaml:"command"`                   // run through the shell
+	Dir             string            `yaml:"dir,omitempty"`             // relative to the repository root
+	Env             map[string]string `yaml:"env,omitempty"`             // added to the inherited environment
+	Timeout         string            `yaml:"timeout,omitempty"`         // e.g., "5m"; default: no timeout
+	ContinueOnError bool              `yaml:"continueOnError,omitempty"` // report failure without stopping
+	Parallel        string            `yaml:"parallel,omitempty"`        // consecutive steps in the same group run concurrently
+}
+
+// UnmarshalYAML accepts either a plain command string or a step mapping
+func (s *CIStep) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*s = CIStep{Command: value.Value}
+		return nil
+	}
+
+	// Decode into an alias type to avoid recursing into this method
+	type plain CIStep
+	var step plain
+	if err := value.Decode(&step); err != nil {
+		return err
+	}
+	*s = CIStep(step)
+	return nil
+}
+
+// DisplayName returns the step name, or its command when unnamed
+func (s *CIStep) DisplayName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Command
+}
+
+// TimeoutDuration parses the step timeout; zero means no timeout
+func (s *CIStep) TimeoutDuration() (time.Duration, error) {
+	if s.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout '%s': %w", s.Timeout, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("timeout cannot be negative")
+	}
+	return d, nil
+}
+
+// validateCISteps checks that every step has a command and a valid timeout
+func validateCISteps(env string, steps []CIStep) error {
+	for i, step := range steps {
+		if step.Command == "" {
+			return fmt.Errorf("environment '%s' ci[%d]: command cannot be empty", env, i)
+		}
+		if _, err := step.TimeoutDuration(); err != nil {
+			return fmt.Errorf("environment '%s' ci[%d]: %w", env, i, err)
+		}
+	}
+	return nil
+}
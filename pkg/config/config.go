@@ -4,33 +4,77 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/imemir/gitext/pkg/conventional"
+	"github.com/imemir/gitext/pkg/naming"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the .gitext configuration file
 type Config struct {
-	Branch struct {
-		Production string `yaml:"production"`
-		Stage      string `yaml:"stage"`
-	} `yaml:"branch"`
+	// Environments are ordered from the integration branch to production
+	Environments []Environment `yaml:"environments"`
+
+	// Branch and CI are the legacy stage/production layout; they are converted
+	// into Environments on load
+	Branch *legacyBranchConfig `yaml:"branch,omitempty"`
+	CI     *legacyCIConfig     `yaml:"ci,omitempty"`
+
 	Naming struct {
-		Feature string `yaml:"feature"`
-		Hotfix  string `yaml:"hotfix"`
+		Feature   string            `yaml:"feature"`
+		Hotfix    string            `yaml:"hotfix"`
+		Template  string            `yaml:"template"`
+		Templates map[string]string `yaml:"templates,omitempty"`
+		Slug      struct {
+			MaxLength     int  `yaml:"maxLength"`
+			Lowercase     bool `yaml:"lowercase"`
+			Transliterate bool `yaml:"transliterate"`
+		} `yaml:"slug"`
 	} `yaml:"naming"`
 	Merge struct {
 		RequireRetargetForProdFromStage bool `yaml:"requireRetargetForProdFromStage"`
 	} `yaml:"merge"`
-	CI struct {
-		Stage      []string `yaml:"stage"`
-		Production []string `yaml:"production"`
-	} `yaml:"ci"`
 	PR struct {
 		TemplatePath string `yaml:"templatePath"`
 	} `yaml:"pr"`
+	Commits struct {
+		Types            []string `yaml:"types,omitempty"`  // allowed types; conventional.DefaultTypes when empty
+		Scopes           []string `yaml:"scopes,omitempty"` // allowed scopes; any when empty
+		MaxSubjectLength int      `yaml:"maxSubjectLength"` // limit for the first line, 0 for none
+		RequireTicket    bool     `yaml:"requireTicket"`
+		Diff             struct {
+			Exclude      []string `yaml:"exclude,omitempty"` // globs left out, in addition to the built-in ones
+			MaxHunkLines int      `yaml:"maxHunkLines"`      // longer hunks are cut, 0 for no limit
+			TokenBudget  int      `yaml:"tokenBudget"`       // estimated tokens the diff may use, 0 for no limit
+		} `yaml:"diff"` // what of the staged diff gitext commit sends to the AI
+	} `yaml:"commits"`
 	Remote struct {
-		Name string `yaml:"name"`
+		Name        string `yaml:"name"`
+		Integration string `yaml:"integrationRemote,omitempty"` // remote environment branches are read from; defaults to name
+		Push        string `yaml:"pushRemote,omitempty"`        // remote feature branches are pushed to; defaults to name
 	} `yaml:"remote"`
+	Git struct {
+		Backend string `yaml:"backend"` // exec (default) or native
+	} `yaml:"git"`
+	Workflow struct {
+		Autostash bool `yaml:"autostash"` // default for --autostash
+	} `yaml:"workflow"`
+	Worktree struct {
+		Dir string `yaml:"dir"` // where start --worktree creates worktrees, relative to the repository root
+	} `yaml:"worktree"`
+	Fetch struct {
+		Policy string `yaml:"policy"` // always (default), stale or never
+		MaxAge string `yaml:"maxAge"` // stale policy: fetch when the last fetch is older (e.g., "15m")
+	} `yaml:"fetch"`
+	Forge struct {
+		Type      string   `yaml:"type"`     // github, gitlab or gitea; detected from the remote when empty
+		BaseURL   string   `yaml:"baseURL"`  // API base URL override (e.g., self-hosted or mock servers)
+		TokenEnv  string   `yaml:"tokenEnv"` // environment variable holding the API token
+		Labels    []string `yaml:"labels"`
+		Reviewers []string `yaml:"reviewers"`
+	} `yaml:"forge"`
 }
 
 // Load loads the .gitext configuration file from the repository root
@@ -45,11 +89,13 @@ func Load() (*Config, error) {
 	config := &Config{}
 
 	// Set defaults
-	config.Branch.Production = DefaultProductionBranch
-	config.Branch.Stage = DefaultStageBranch
 	config.Remote.Name = DefaultRemoteName
-	config.Naming.Feature = DefaultFeaturePattern
-	config.Naming.Hotfix = DefaultHotfixPattern
+	config.Naming.Slug.MaxLength = DefaultSlugMaxLength
+	config.Naming.Slug.Lowercase = true
+	config.Naming.Slug.Transliterate = true
+	config.Commits.MaxSubjectLength = conventional.DefaultMaxSubjectLength
+	config.Commits.Diff.MaxHunkLines = DefaultDiffMaxHunkLines
+	config.Commits.Diff.TokenBudget = DefaultDiffTokenBudget
 
 	// Load config file if it exists
 	if _, err := os.Stat(configPath); err == nil {
@@ -64,20 +110,43 @@ func Load() (*Config, error) {
 	}
 
 	// Apply defaults for missing values
-	if config.Branch.Production == "" {
-		config.Branch.Production = DefaultProductionBranch
-	}
-	if config.Branch.Stage == "" {
-		config.Branch.Stage = DefaultStageBranch
+	if len(config.Environments) == 0 {
+		config.Environments = defaultEnvironments(config.Branch, config.CI)
+	} else if config.Branch != nil || config.CI != nil {
+		return nil, fmt.Errorf("invalid .gitext: use either environments or the legacy branch/ci sections, not both")
 	}
+	config.Branch = nil
+	config.CI = nil
 	if config.Remote.Name == "" {
 		config.Remote.Name = DefaultRemoteName
 	}
+	if config.Fetch.Policy == "" {
+		config.Fetch.Policy = FetchAlways
+	}
+	if config.Fetch.MaxAge == "" {
+		config.Fetch.MaxAge = DefaultFetchMaxAge
+	}
+	// Patterns default to the glob of a custom template so generated names always validate
+	customFeature := config.Naming.Template != "" || config.Naming.Templates["feature"] != ""
+	customHotfix := config.Naming.Template != "" || config.Naming.Templates["hotfix"] != ""
+	if config.Naming.Template == "" {
+		config.Naming.Template = DefaultBranchTemplate
+	}
 	if config.Naming.Feature == "" {
 		config.Naming.Feature = DefaultFeaturePattern
+		if customFeature {
+			if tmpl, err := config.BranchTemplate("feature"); err == nil {
+				config.Naming.Feature = tmpl.Glob("feature")
+			}
+		}
 	}
 	if config.Naming.Hotfix == "" {
 		config.Naming.Hotfix = DefaultHotfixPattern
+		if customHotfix {
+			if tmpl, err := config.BranchTemplate("hotfix"); err == nil {
+				config.Naming.Hotfix = tmpl.Glob("hotfix")
+			}
+		}
 	}
 
 	// Validate config
@@ -90,27 +159,139 @@ func Load() (*Config, error) {
 
 // Validate validates the configuration values
 func (c *Config) Validate() error {
-	if c.Branch.Production == "" {
-		return fmt.Errorf("branch.production cannot be empty")
-	}
-	if c.Branch.Stage == "" {
-		return fmt.Errorf("branch.stage cannot be empty")
-	}
-	if c.Branch.Production == c.Branch.Stage {
-		return fmt.Errorf("branch.production and branch.stage must be different")
+	if err := c.validateEnvironments(); err != nil {
+		return err
 	}
 	if c.Remote.Name == "" {
 		return fmt.Errorf("remote.name cannot be empty")
 	}
+	if c.Naming.Template != "" {
+		if _, err := naming.Parse(c.Naming.Template); err != nil {
+			return fmt.Errorf("naming.template: %w", err)
+		}
+	}
+	for branchType, tmpl := range c.Naming.Templates {
+		if _, err := naming.Parse(tmpl); err != nil {
+			return fmt.Errorf("naming.templates.%s: %w", branchType, err)
+		}
+	}
+	if c.Naming.Slug.MaxLength < 0 {
+		return fmt.Errorf("naming.slug.maxLength cannot be negative")
+	}
+	switch c.Git.Backend {
+	case "", "exec", "native":
+	default:
+		return fmt.Errorf("git.backend must be exec or native, got '%s'", c.Git.Backend)
+	}
+	switch c.Fetch.Policy {
+	case "", FetchAlways, FetchStale, FetchNever:
+	default:
+		return fmt.Errorf("fetch.policy must be always, stale or never, got '%s'", c.Fetch.Policy)
+	}
+	if _, err := c.FetchMaxAge(); err != nil {
+		return fmt.Errorf("fetch.maxAge: %w", err)
+	}
+	switch c.Forge.Type {
+	case "", "github", "gitlab", "gitea":
+	default:
+		return fmt.Errorf("forge.type must be github, gitlab or gitea, got '%s'", c.Forge.Type)
+	}
 	return nil
 }
 
+// FetchMaxAge parses fetch.maxAge, the age after which the stale policy
+// fetches again
+func (c *Config) FetchMaxAge() (time.Duration, error) {
+	if c.Fetch.MaxAge == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.Fetch.MaxAge)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration '%s': %w", c.Fetch.MaxAge, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration cannot be negative")
+	}
+	return d, nil
+}
+
+// IntegrationRemote returns the remote environment branches are fetched
+// from and compared against (e.g., upstream when working from a fork)
+func (c *Config) IntegrationRemote() string {
+	if c.Remote.Integration != "" {
+		return c.Remote.Integration
+	}
+	return c.Remote.Name
+}
+
+// PushRemote returns the remote feature branches are pushed to (e.g., the
+// fork at origin)
+func (c *Config) PushRemote() string {
+	if c.Remote.Push != "" {
+		return c.Remote.Push
+	}
+	return c.Remote.Name
+}
+
+// IsFork reports whether feature branches are pushed to a different remote
+// than the one they are integrated into
+func (c *Config) IsFork() bool {
+	return c.IntegrationRemote() != c.PushRemote()
+}
+
+// WorktreeDir returns the directory start --worktree creates worktrees in,
+// given the root of the main worktree. It defaults to <repo>.worktrees next
+// to the repository, so worktrees never end up inside the checkout.
+func (c *Config) WorktreeDir(root string) string {
+	if c.Worktree.Dir == "" {
+		return filepath.Join(filepath.Dir(root), filepath.Base(root)+".worktrees")
+	}
+	if filepath.IsAbs(c.Worktree.Dir) {
+		return filepath.Clean(c.Worktree.Dir)
+	}
+	return filepath.Join(root, c.Worktree.Dir)
+}
+
+// BranchTemplate returns the branch-name template for a branch type,
+// falling back to naming.template when no type-specific template is set
+func (c *Config) BranchTemplate(branchType string) (*naming.Template, error) {
+	tmpl := c.Naming.Templates[branchType]
+	if tmpl == "" {
+		tmpl = c.Naming.Template
+	}
+	if tmpl == "" {
+		tmpl = DefaultBranchTemplate
+	}
+	return naming.Parse(tmpl)
+}
+
+// CommitRules returns the rules commit messages are linted with
+func (c *Config) CommitRules() conventional.Rules {
+	return conventional.Rules{
+		Types:            c.Commits.Types,
+		Scopes:           c.Commits.Scopes,
+		MaxSubjectLength: c.Commits.MaxSubjectLength,
+		RequireTicket:    c.Commits.RequireTicket,
+	}
+}
+
+// SlugOptions returns the slug normalisation options from naming.slug
+func (c *Config) SlugOptions() naming.SlugOptions {
+	return naming.SlugOptions{
+		MaxLength:     c.Naming.Slug.MaxLength,
+		Lowercase:     c.Naming.Slug.Lowercase,
+		Transliterate: c.Naming.Slug.Transliterate,
+	}
+}
+
 // GetGitRoot returns the git repository root directory
 func GetGitRoot() (string, error) {
 	return findGitRoot()
 }
 
-// findGitRoot walks up from the current directory to find .git
+// findGitRoot walks up from the current directory to find .git: a
+// directory in a regular checkout, or a file pointing at the git directory
+// in linked worktrees and submodules
 func findGitRoot() (string, error) {
 	dir, err := os.Getwd()
 	if err != nil {
@@ -118,8 +299,7 @@ func findGitRoot() (string, error) {
 	}
 
 	for {
-		gitDir := filepath.Join(dir, ".git")
-		if info, err := os.Stat(gitDir); err == nil && info.IsDir() {
+		if isGitDir(filepath.Join(dir, ".git")) {
 			return dir, nil
 		}
 
@@ -131,6 +311,20 @@ func findGitRoot() (string, error) {
 	}
 }
 
+// isGitDir reports whether path is a .git directory, or a .git file
+// ("gitdir: <path>") as git writes in linked worktrees and submodules
+func isGitDir(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		return true
+	}
+	data, err := os.ReadFile(path)
+	return err == nil && strings.HasPrefix(string(data), "gitdir:")
+}
+
 // Save writes the configuration to .gitext in the repository root
 func (c *Config) Save() error {
 	gitRoot, err := findGitRoot()
@@ -146,4 +340,3 @@ func (c *Config) Save() error {
 
 	return os.WriteFile(configPath, data, 0644)
 }
-
@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/imemir/gitext/pkg/conventional"
 )
 
 func TestLoadDefaults(t *testing.T) {
@@ -31,15 +34,27 @@ func TestLoadDefaults(t *testing.T) {
 	}
 
 	// Check defaults
-	if cfg.Branch.Production != DefaultProductionBranch {
-		t.Errorf("Expected production branch %s, got %s", DefaultProductionBranch, cfg.Branch.Production)
+	if len(cfg.Environments) != 2 {
+		t.Fatalf("Expected 2 default environments, got %d", len(cfg.Environments))
+	}
+	if cfg.ProductionEnvironment().Branch != DefaultProductionBranch {
+		t.Errorf("Expected production branch %s, got %s", DefaultProductionBranch, cfg.ProductionEnvironment().Branch)
 	}
-	if cfg.Branch.Stage != DefaultStageBranch {
-		t.Errorf("Expected stage branch %s, got %s", DefaultStageBranch, cfg.Branch.Stage)
+	if cfg.IntegrationEnvironment().Branch != DefaultStageBranch {
+		t.Errorf("Expected stage branch %s, got %s", DefaultStageBranch, cfg.IntegrationEnvironment().Branch)
 	}
 	if cfg.Remote.Name != DefaultRemoteName {
 		t.Errorf("Expected remote name %s, got %s", DefaultRemoteName, cfg.Remote.Name)
 	}
+	if cfg.Fetch.Policy != FetchAlways {
+		t.Errorf("Expected fetch policy %s, got %s", FetchAlways, cfg.Fetch.Policy)
+	}
+	if rules := cfg.CommitRules(); rules.MaxSubjectLength != conventional.DefaultMaxSubjectLength || len(rules.AllowedTypes()) != len(conventional.DefaultTypes) || rules.RequireTicket {
+		t.Errorf("Expected default commit rules, got %+v", rules)
+	}
+	if diff := cfg.Commits.Diff; diff.MaxHunkLines != DefaultDiffMaxHunkLines || diff.TokenBudget != DefaultDiffTokenBudget {
+		t.Errorf("Expected default diff limits, got %+v", diff)
+	}
 }
 
 func TestLoadWithConfig(t *testing.T) {
@@ -78,39 +93,283 @@ remote:
 		t.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Check loaded values
-	if cfg.Branch.Production != "main" {
-		t.Errorf("Expected production branch 'main', got %s", cfg.Branch.Production)
+	// Check loaded values (legacy branch section is converted into environments)
+	production, err := cfg.Environment("production")
+	if err != nil {
+		t.Fatalf("Expected production environment: %v", err)
 	}
-	if cfg.Branch.Stage != "develop" {
-		t.Errorf("Expected stage branch 'develop', got %s", cfg.Branch.Stage)
+	if production.Branch != "main" {
+		t.Errorf("Expected production branch 'main', got %s", production.Branch)
+	}
+	stage, err := cfg.Environment("stage")
+	if err != nil {
+		t.Fatalf("Expected stage environment: %v", err)
+	}
+	if stage.Branch != "develop" {
+		t.Errorf("Expected stage branch 'develop', got %s", stage.Branch)
+	}
+	if cfg.Branch != nil {
+		t.Error("Expected legacy branch section to be cleared after conversion")
 	}
 	if cfg.Remote.Name != "upstream" {
 		t.Errorf("Expected remote name 'upstream', got %s", cfg.Remote.Name)
 	}
+	if cfg.IntegrationRemote() != "upstream" || cfg.PushRemote() != "upstream" || cfg.IsFork() {
+		t.Errorf("Expected both remotes to default to remote.name, got %s and %s", cfg.IntegrationRemote(), cfg.PushRemote())
+	}
+}
+
+func TestForkRemotes(t *testing.T) {
+	cfg := &Config{}
+	cfg.Remote.Name = "origin"
+	cfg.Remote.Integration = "upstream"
+
+	if cfg.IntegrationRemote() != "upstream" {
+		t.Errorf("Expected integration remote 'upstream', got %s", cfg.IntegrationRemote())
+	}
+	if cfg.PushRemote() != "origin" {
+		t.Errorf("Expected push remote to default to remote.name, got %s", cfg.PushRemote())
+	}
+	if !cfg.IsFork() {
+		t.Error("Expected a fork when the integration and push remotes differ")
+	}
+
+	cfg.Remote.Push = "upstream"
+	if cfg.IsFork() {
+		t.Error("Expected no fork when both remotes are upstream")
+	}
 }
 
 func TestValidate(t *testing.T) {
 	cfg := &Config{}
-	cfg.Branch.Production = "production"
-	cfg.Branch.Stage = "stage"
+	cfg.Environments = []Environment{
+		{Name: "stage", Branch: "stage"},
+		{Name: "production", Branch: "production"},
+	}
 	cfg.Remote.Name = "origin"
 
 	if err := cfg.Validate(); err != nil {
 		t.Errorf("Valid config should not error: %v", err)
 	}
 
+	// Test fetch policy
+	cfg.Fetch.Policy = "sometimes"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown fetch policy")
+	}
+	cfg.Fetch.Policy = FetchStale
+	cfg.Fetch.MaxAge = "soon"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for invalid fetch.maxAge")
+	}
+	cfg.Fetch.MaxAge = "30m"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Valid fetch policy should not error: %v", err)
+	}
+
 	// Test empty production branch
-	cfg.Branch.Production = ""
+	cfg.Environments[1].Branch = ""
 	if err := cfg.Validate(); err == nil {
 		t.Error("Expected error for empty production branch")
 	}
 
 	// Test same branches
-	cfg.Branch.Production = "main"
-	cfg.Branch.Stage = "main"
+	cfg.Environments[0].Branch = "main"
+	cfg.Environments[1].Branch = "main"
 	if err := cfg.Validate(); err == nil {
 		t.Error("Expected error for same production and stage branches")
 	}
+
+	// Test unknown promotion source
+	cfg.Environments[1].Branch = "production"
+	cfg.Environments[1].PromoteFrom = []string{"qa"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown promoteFrom environment")
+	}
+
+	// Test no environments
+	cfg.Environments = nil
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for missing environments")
+	}
 }
 
+func TestLoadWithEnvironments(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gitext-test-")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+
+	configContent := `environments:
+  - name: dev
+    branch: develop
+    protected: false
+  - name: qa
+    branch: qa
+    ci:
+      - "go test ./..."
+      - name: lint
+        command: "golangci-lint run | tee lint.txt"
+        dir: tools
+        env:
+          GOFLAGS: "-mod=mod"
+        timeout: 5m
+        continueOnError: true
+        parallel: checks
+  - name: stage
+    branch: stage
+  - name: production
+    branch: main
+    promoteFrom: [stage, qa]
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".gitext"), []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldDir)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if names := strings.Join(cfg.EnvironmentNames(), ","); names != "dev,qa,stage,production" {
+		t.Errorf("Expected environments in order, got %s", names)
+	}
+	if cfg.IntegrationEnvironment().Name != "dev" || cfg.ProductionEnvironment().Name != "production" {
+		t.Errorf("Unexpected integration/production environments")
+	}
+	if env := cfg.EnvironmentForBranch("main"); env == nil || env.Name != "production" {
+		t.Errorf("Expected branch 'main' to map to production")
+	}
+	if prev := cfg.PreviousEnvironment("stage"); prev == nil || prev.Name != "qa" {
+		t.Errorf("Expected qa before stage")
+	}
+	if sources := strings.Join(cfg.PromotionSources("stage"), ","); sources != "qa" {
+		t.Errorf("Expected stage to promote from qa by default, got %s", sources)
+	}
+	if sources := strings.Join(cfg.PromotionSources("production"), ","); sources != "stage,qa" {
+		t.Errorf("Expected explicit promoteFrom, got %s", sources)
+	}
+	if protected := strings.Join(cfg.ProtectedBranches(), ","); protected != "qa,stage,main" {
+		t.Errorf("Expected dev to be unprotected, got %s", protected)
+	}
+	if _, err := cfg.Environment("uat"); err == nil {
+		t.Error("Expected error for unknown environment")
+	}
+
+	qa, _ := cfg.Environment("qa")
+	if len(qa.CI) != 2 {
+		t.Fatalf("Expected 2 CI steps for qa, got %d", len(qa.CI))
+	}
+	if qa.CI[0].Command != "go test ./..." || qa.CI[0].DisplayName() != "go test ./..." {
+		t.Errorf("Expected plain string step to become a command, got %+v", qa.CI[0])
+	}
+	lint := qa.CI[1]
+	if lint.DisplayName() != "lint" || lint.Dir != "tools" || lint.Env["GOFLAGS"] != "-mod=mod" || !lint.ContinueOnError || lint.Parallel != "checks" {
+		t.Errorf("Unexpected structured step: %+v", lint)
+	}
+	if d, err := lint.TimeoutDuration(); err != nil || d.Minutes() != 5 {
+		t.Errorf("Expected 5m timeout, got %v (%v)", d, err)
+	}
+}
+
+func TestLoadWithNamingTemplate(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "gitext-test-")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+
+	configContent := `naming:
+  template: "{type}/{ticket}_{slug}"
+  templates:
+    feature: "feat/{user}/{ticket}/{slug}"
+  slug:
+    maxLength: 30
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".gitext"), []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldDir)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Patterns are derived from the templates when not set explicitly
+	if cfg.Naming.Feature != "feat/*/*/*" {
+		t.Errorf("Expected feature pattern 'feat/*/*/*', got %s", cfg.Naming.Feature)
+	}
+	if cfg.Naming.Hotfix != "hotfix/*_*" {
+		t.Errorf("Expected hotfix pattern 'hotfix/*_*', got %s", cfg.Naming.Hotfix)
+	}
+
+	tmpl, err := cfg.BranchTemplate("hotfix")
+	if err != nil {
+		t.Fatalf("Failed to get hotfix template: %v", err)
+	}
+	if tmpl.String() != "{type}/{ticket}_{slug}" {
+		t.Errorf("Expected fallback template, got %s", tmpl.String())
+	}
+
+	opts := cfg.SlugOptions()
+	if opts.MaxLength != 30 || !opts.Lowercase || !opts.Transliterate {
+		t.Errorf("Unexpected slug options: %+v", opts)
+	}
+}
+
+func TestFindGitRootInWorktree(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Linked worktrees and submodules have a .git file pointing at the git directory
+	worktree := filepath.Join(tmpDir, "app.worktrees", "feature-KWS-1-login")
+	if err := os.MkdirAll(filepath.Join(worktree, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	gitFile := "gitdir: " + filepath.Join(tmpDir, "app", ".git", "worktrees", "feature-KWS-1-login") + "\n"
+	if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte(gitFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(filepath.Join(worktree, "sub"))
+	root, err := GetGitRoot()
+	if err != nil {
+		t.Fatalf("GetGitRoot() error = %v", err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(worktree); root != worktree && root != resolved {
+		t.Errorf("GetGitRoot() = %s, want %s", root, worktree)
+	}
+}
+
+func TestWorktreeDir(t *testing.T) {
+	cfg := &Config{}
+	root := filepath.Join("/src", "app")
+
+	if dir := cfg.WorktreeDir(root); dir != filepath.Join("/src", "app.worktrees") {
+		t.Errorf("Expected worktrees next to the repository by default, got %s", dir)
+	}
+	cfg.Worktree.Dir = "../trees"
+	if dir := cfg.WorktreeDir(root); dir != filepath.Join("/src", "trees") {
+		t.Errorf("Expected worktree.dir relative to the repository root, got %s", dir)
+	}
+	cfg.Worktree.Dir = filepath.Join("/var", "trees")
+	if dir := cfg.WorktreeDir(root); dir != filepath.Join("/var", "trees") {
+		t.Errorf("Expected an absolute worktree.dir to be kept, got %s", dir)
+	}
+}
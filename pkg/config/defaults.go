@@ -4,8 +4,26 @@ package config
 const (
 	DefaultProductionBranch = "production"
 	DefaultStageBranch      = "stage"
-	DefaultRemoteName       = "origin"
-	DefaultFeaturePattern   = "feature/*"
-	DefaultHotfixPattern    = "hotfix/*"
+
+	DefaultProductionEnvironment = "production"
+	DefaultStageEnvironment      = "stage"
+
+	DefaultRemoteName      = "origin"
+	DefaultFeaturePattern  = "feature/*"
+	DefaultHotfixPattern   = "hotfix/*"
+	DefaultBackmergePrefix = "backmerge/"
+	DefaultReleasePrefix   = "release/"
+	DefaultBranchTemplate  = "{type}/{ticket}-{slug}"
+	DefaultSlugMaxLength   = 50
+	DefaultFetchMaxAge     = "15m"
+
+	DefaultDiffMaxHunkLines = 200
+	DefaultDiffTokenBudget  = 8000
 )
 
+// Fetch policies: when commands refresh remote-tracking refs before using them
+const (
+	FetchAlways = "always" // fetch every time
+	FetchStale  = "stale"  // fetch when the last fetch is older than fetch.maxAge
+	FetchNever  = "never"  // use the remote-tracking refs as they are
+)
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Environment is a named long-lived branch in the promotion chain
+// (e.g., dev -> qa -> stage -> production)
+type Environment struct {
+	Name        string   `yaml:"name"`
+	Branch      string   `yaml:"branch"`
+	CI          []CIStep `yaml:"ci,omitempty"`
+	Protected   *bool    `yaml:"protected,omitempty"`   // default: true
+	PromoteFrom []string `yaml:"promoteFrom,omitempty"` // default: the previous environment
+}
+
+// IsProtected reports whether direct pushes to the environment branch are blocked
+func (e *Environment) IsProtected() bool {
+	return e.Protected == nil || *e.Protected
+}
+
+// legacyBranchConfig is the original two-environment branch section
+type legacyBranchConfig struct {
+	Production string `yaml:"production"`
+	Stage      string `yaml:"stage"`
+}
+
+// legacyCIConfig is the original two-environment ci section
+type legacyCIConfig struct {
+	Stage      []CIStep `yaml:"stage"`
+	Production []CIStep `yaml:"production"`
+}
+
+// defaultEnvironments builds the stage -> production chain, optionally from
+// the legacy branch and ci sections
+func defaultEnvironments(branch *legacyBranchConfig, ci *legacyCIConfig) []Environment {
+	stage := Environment{Name: DefaultStageEnvironment, Branch: DefaultStageBranch}
+	production := Environment{
+		Name:        DefaultProductionEnvironment,
+		Branch:      DefaultProductionBranch,
+		PromoteFrom: []string{DefaultStageEnvironment},
+	}
+
+	if branch != nil {
+		if branch.Stage != "" {
+			stage.Branch = branch.Stage
+		}
+		if branch.Production != "" {
+			production.Branch = branch.Production
+		}
+	}
+	if ci != nil {
+		stage.CI = ci.Stage
+		production.CI = ci.Production
+	}
+
+	return []Environment{stage, production}
+}
+
+// Environment looks up an environment by name
+func (c *Config) Environment(name string) (*Environment, error) {
+	for i := range c.Environments {
+		if c.Environments[i].Name == name {
+			return &c.Environments[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown environment '%s' (expected one of: %s)", name, strings.Join(c.EnvironmentNames(), ", "))
+}
+
+// EnvironmentForBranch returns the environment whose branch is branch, or nil
+func (c *Config) EnvironmentForBranch(branch string) *Environment {
+	for i := range c.Environments {
+		if c.Environments[i].Branch == branch {
+			return &c.Environments[i]
+		}
+	}
+	return nil
+}
+
+// EnvironmentNames returns environment names in promotion order
+func (c *Config) EnvironmentNames() []string {
+	names := make([]string, len(c.Environments))
+	for i, env := range c.Environments {
+		names[i] = env.Name
+	}
+	return names
+}
+
+// IntegrationEnvironment returns the first environment, where features are integrated
+func (c *Config) IntegrationEnvironment() *Environment {
+	if len(c.Environments) == 0 {
+		return nil
+	}
+	return &c.Environments[0]
+}
+
+// ProductionEnvironment returns the last environment in the promotion chain
+func (c *Config) ProductionEnvironment() *Environment {
+	if len(c.Environments) == 0 {
+		return nil
+	}
+	return &c.Environments[len(c.Environments)-1]
+}
+
+// PreviousEnvironment returns the environment before name in the promotion chain,
+// or nil if name is the first environment
+func (c *Config) PreviousEnvironment(name string) *Environment {
+	for i := range c.Environments {
+		if c.Environments[i].Name == name {
+			if i == 0 {
+				return nil
+			}
+			return &c.Environments[i-1]
+		}
+	}
+	return nil
+}
+
+// PromotionSources returns the environments allowed to be promoted into name
+func (c *Config) PromotionSources(name string) []string {
+	env, err := c.Environment(name)
+	if err != nil {
+		return nil
+	}
+	if len(env.PromoteFrom) > 0 {
+		return env.PromoteFrom
+	}
+	if prev := c.PreviousEnvironment(name); prev != nil {
+		return []string{prev.Name}
+	}
+	return nil
+}
+
+// IsEnvironmentBranch reports whether branch belongs to any environment
+func (c *Config) IsEnvironmentBranch(branch string) bool {
+	return c.EnvironmentForBranch(branch) != nil
+}
+
+// ProtectedBranches returns the branches of all protected environments
+func (c *Config) ProtectedBranches() []string {
+	var branches []string
+	for _, env := range c.Environments {
+		if env.IsProtected() {
+			branches = append(branches, env.Branch)
+		}
+	}
+	return branches
+}
+
+// validateEnvironments checks names, branches and promotion sources
+func (c *Config) validateEnvironments() error {
+	if len(c.Environments) == 0 {
+		return fmt.Errorf("at least one environment must be configured")
+	}
+
+	names := make(map[string]bool)
+	branches := make(map[string]string)
+	for i, env := range c.Environments {
+		if env.Name == "" {
+			return fmt.Errorf("environments[%d].name cannot be empty", i)
+		}
+		if env.Branch == "" {
+			return fmt.Errorf("environment '%s' branch cannot be empty", env.Name)
+		}
+		if names[env.Name] {
+			return fmt.Errorf("environment '%s' is defined more than once", env.Name)
+		}
+		if other, ok := branches[env.Branch]; ok {
+			return fmt.Errorf("environments '%s' and '%s' must use different branches", other, env.Name)
+		}
+		if err := validateCISteps(env.Name, env.CI); err != nil {
+			return err
+		}
+		names[env.Name] = true
+		branches[env.Branch] = env.Name
+	}
+
+	for _, env := range c.Environments {
+		for _, source := range env.PromoteFrom {
+			if !names[source] {
+				return fmt.Errorf("environment '%s' promoteFrom references unknown environment '%s'", env.Name, source)
+			}
+			if source == env.Name {
+				return fmt.Errorf("environment '%s' cannot promote from itself", env.Name)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+// Package conventional parses and lints commit messages following the
+// Conventional Commits specification (https://www.conventionalcommits.org/en/v1.0.0/)
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BreakingChangeToken is the footer token describing a breaking change
+const BreakingChangeToken = "BREAKING CHANGE"
+
+// Message is a parsed commit message
+type Message struct {
+	Header  string
+	Type    string
+	Scope   string
+	Subject string
+	Body    string
+
+	// Breaking is set by a "!" in the header or a BREAKING CHANGE footer
+	Breaking bool
+
+	// Footers are the trailers in the last paragraph, in order
+	Footers []Footer
+}
+
+// Footer is a "Token: value" or "Token #value" trailer
+type Footer struct {
+	Token string
+	Value string
+}
+
+// BreakingChange returns the description in the BREAKING CHANGE footer, if any
+func (m *Message) BreakingChange() string {
+	for _, footer := range m.Footers {
+		if IsBreakingChangeToken(footer.Token) {
+			return footer.Value
+		}
+	}
+	return ""
+}
+
+// IsBreakingChangeToken reports whether a footer token marks a breaking
+// change; the specification allows BREAKING-CHANGE as a synonym
+func IsBreakingChangeToken(token string) bool {
+	token = strings.ToUpper(token)
+	return token == BreakingChangeToken || token == "BREAKING-CHANGE"
+}
+
+// headerRegex matches a header: type(scope)!: subject
+var headerRegex = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+
+// footerRegex matches a trailer line: "Token: value" or "Token #value"
+var footerRegex = regexp.MustCompile(`^(BREAKING CHANGE|[A-Za-z][\w-]*)(?::\s+(.+)|\s+(#.+))$`)
+
+// scissors marks where git cuts the message edited with commit --verbose
+const scissors = "# ------------------------ >8 ------------------------"
+
+// Clean removes what git strips from an edited commit message: comment
+// lines, everything below the scissors line and surrounding blank lines
+func Clean(text string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if line == scissors {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// Parse splits a cleaned commit message into header, body and footers. It
+// fails when the header is not type(scope)!: subject.
+func Parse(text string) (*Message, error) {
+	header, rest, _ := strings.Cut(text, "\n")
+	message := &Message{Header: header}
+
+	match := headerRegex.FindStringSubmatch(header)
+	if match == nil {
+		return message, fmt.Errorf("header %q is not in the form type(scope): subject", header)
+	}
+	message.Type, message.Scope, message.Breaking, message.Subject = match[1], match[2], match[3] == "!", strings.TrimSpace(match[4])
+
+	paragraphs := strings.Split(strings.Trim(rest, "\n"), "\n\n")
+	if last := paragraphs[len(paragraphs)-1]; last != "" {
+		if footers := parseFooters(last); footers != nil {
+			message.Footers = footers
+			paragraphs = paragraphs[:len(paragraphs)-1]
+		}
+	}
+	message.Body = strings.Join(paragraphs, "\n\n")
+
+	if message.BreakingChange() != "" {
+		message.Breaking = true
+	}
+	return message, nil
+}
+
+// parseFooters parses a paragraph made only of trailers, or returns nil.
+// Lines that do not start a trailer continue the previous one.
+func parseFooters(paragraph string) []Footer {
+	var footers []Footer
+	for _, line := range strings.Split(paragraph, "\n") {
+		if match := footerRegex.FindStringSubmatch(line); match != nil {
+			footers = append(footers, Footer{Token: match[1], Value: match[2] + match[3]})
+			continue
+		}
+		if len(footers) == 0 {
+			return nil
+		}
+		footers[len(footers)-1].Value += "\n" + line
+	}
+	return footers
+}
+
+// exemptPrefixes start the subjects of commits git writes itself, which
+// are not expected to follow the specification
+var exemptPrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+// IsExempt reports whether a commit message was written by git (merges,
+// reverts and autosquash commits) and is not linted
+func IsExempt(text string) bool {
+	for _, prefix := range exemptPrefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
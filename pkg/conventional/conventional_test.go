@@ -0,0 +1,110 @@
+package conventional
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	message, err := Parse("feat(api)!: remove v1 endpoints\n\nThe v1 API was deprecated.\n\nBREAKING CHANGE: clients must\n  use /v2\nRefs #42\nReviewed-by: Alice")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := &Message{
+		Header:   "feat(api)!: remove v1 endpoints",
+		Type:     "feat",
+		Scope:    "api",
+		Subject:  "remove v1 endpoints",
+		Body:     "The v1 API was deprecated.",
+		Breaking: true,
+		Footers: []Footer{
+			{Token: "BREAKING CHANGE", Value: "clients must\n  use /v2"},
+			{Token: "Refs", Value: "#42"},
+			{Token: "Reviewed-by", Value: "Alice"},
+		},
+	}
+	if !reflect.DeepEqual(message, want) {
+		t.Errorf("Parse() = %+v, want %+v", message, want)
+	}
+	if got := message.BreakingChange(); got != "clients must\n  use /v2" {
+		t.Errorf("BreakingChange() = %q", got)
+	}
+}
+
+func TestParseBreakingFooterOnly(t *testing.T) {
+	message, err := Parse("fix: rename flag\n\nBREAKING-CHANGE: --to is now --into")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !message.Breaking || message.Body != "" {
+		t.Errorf("expected a breaking change without body, got %+v", message)
+	}
+}
+
+func TestParseInvalidHeader(t *testing.T) {
+	for _, text := range []string{"KWS-1 add login", "feat add login", "feat(api: add login", "feat:add login"} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) expected an error", text)
+		}
+	}
+}
+
+func TestClean(t *testing.T) {
+	text := "fix: handle nil   \n# Please enter the commit message\n\nBody.\n\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n"
+	if got, want := Clean(text), "fix: handle nil\n\nBody."; got != want {
+		t.Errorf("Clean() = %q, want %q", got, want)
+	}
+}
+
+func TestLint(t *testing.T) {
+	rules := Rules{Scopes: []string{"api", "ui"}, MaxSubjectLength: 30, RequireTicket: true}
+
+	tests := []struct {
+		name  string
+		text  string
+		rules Rules
+		want  []string
+	}{
+		{"valid", "feat(api): add login\n\nRefs: KWS-1", rules, nil},
+		{"no scope", "fix: handle nil\n\nRefs: KWS-1", rules, nil},
+		{"not conventional", "KWS-1 add login", rules, []string{"header"}},
+		{"unknown type", "feature(api): add login KWS-1", rules, []string{"type"}},
+		{"uppercase type", "Feat: add login KWS-1", rules, []string{"type"}},
+		{"unknown scope", "feat(db): add login KWS-1", rules, []string{"scope"}},
+		{"empty subject", "feat: \n\nKWS-1", rules, []string{"subject-empty"}},
+		{"too long", "feat(api): add a login page with remember me KWS-1", rules, []string{"subject-length"}},
+		{"no blank line", "feat: add login\nKWS-1", rules, []string{"body-leading-blank"}},
+		{"no ticket", "feat: add login", rules, []string{"ticket"}},
+		{"custom types", "feat: add login", Rules{Types: []string{"story"}}, []string{"type"}},
+		{"no limits", "docs: " + strings.Repeat("x", 100), Rules{}, nil},
+		{"empty", "", rules, []string{"empty"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, problem := range Lint(tt.text, tt.rules) {
+				got = append(got, problem.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsExempt(t *testing.T) {
+	for text, want := range map[string]bool{
+		"Merge branch 'stage' into feature/KWS-1-login": true,
+		"Revert \"feat: add login\"":                    true,
+		"fixup! feat: add login":                        true,
+		"feat: add login":                               false,
+		"Update readme":                                 false,
+	} {
+		if got := IsExempt(text); got != want {
+			t.Errorf("IsExempt(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package conventional
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/imemir/gitext/pkg/naming"
+)
+
+// DefaultTypes are the commit types allowed when none are configured
+var DefaultTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// DefaultMaxSubjectLength is the default limit for the first line
+const DefaultMaxSubjectLength = 72
+
+// Rules configure what Lint accepts
+type Rules struct {
+	// Types allowed in the header; DefaultTypes when empty
+	Types []string
+
+	// Scopes allowed in the header; any scope (or none) when empty
+	Scopes []string
+
+	// MaxSubjectLength limits the first line, in characters; 0 for no limit
+	MaxSubjectLength int
+
+	// RequireTicket requires a ticket key (e.g., KWS-123) in the message
+	RequireTicket bool
+}
+
+// AllowedTypes returns the configured types, or DefaultTypes
+func (r Rules) AllowedTypes() []string {
+	if len(r.Types) == 0 {
+		return DefaultTypes
+	}
+	return r.Types
+}
+
+// Problem is a rule a commit message breaks
+type Problem struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s (%s)", p.Message, p.Rule)
+}
+
+// Lint checks a cleaned commit message against rules and returns the
+// problems found, or nil when it is valid
+func Lint(text string, rules Rules) []Problem {
+	var problems []Problem
+	add := func(rule, format string, args ...interface{}) {
+		problems = append(problems, Problem{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(text) == "" {
+		add("empty", "commit message is empty")
+		return problems
+	}
+
+	message, err := Parse(text)
+	if err != nil {
+		add("header", "%v", err)
+	} else {
+		if types := rules.AllowedTypes(); !contains(types, message.Type) {
+			add("type", "type %q is not one of %s", message.Type, strings.Join(types, ", "))
+		}
+		if len(rules.Scopes) > 0 && message.Scope != "" && !contains(rules.Scopes, message.Scope) {
+			add("scope", "scope %q is not one of %s", message.Scope, strings.Join(rules.Scopes, ", "))
+		}
+		if message.Subject == "" {
+			add("subject-empty", "subject is empty")
+		}
+	}
+
+	if length := utf8.RuneCountInString(message.Header); rules.MaxSubjectLength > 0 && length > rules.MaxSubjectLength {
+		add("subject-length", "first line is %d characters long, the limit is %d", length, rules.MaxSubjectLength)
+	}
+	if _, rest, ok := strings.Cut(text, "\n"); ok && !strings.HasPrefix(rest, "\n") {
+		add("body-leading-blank", "the first line must be followed by a blank line")
+	}
+	if rules.RequireTicket && naming.FindTicket(text) == "" {
+		add("ticket", "no ticket referenced (e.g., Refs: KWS-123)")
+	}
+
+	return problems
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
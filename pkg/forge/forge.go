@@ -0,0 +1,271 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const forgeTimeout = 30 * time.Second
+
+// Supported forge types
+const (
+	TypeGitHub = "github"
+	TypeGitLab = "gitlab"
+	TypeGitea  = "gitea"
+)
+
+// PullRequest describes a pull/merge request to open
+type PullRequest struct {
+	Title     string
+	Body      string
+	Head      string      // source branch
+	HeadRepo  *Repository // fork the source branch is pushed to; nil when it is in the target repository
+	Base      string      // target branch
+	Labels    []string
+	Reviewers []string
+}
+
+// HeadRef returns the source branch as owner:branch when it is on a fork
+func (pr *PullRequest) HeadRef() string {
+	if pr.HeadRepo == nil {
+		return pr.Head
+	}
+	return pr.HeadRepo.Owner + ":" + pr.Head
+}
+
+// Created describes a pull/merge request that was opened
+type Created struct {
+	Number int
+	URL    string
+}
+
+// Forge opens pull/merge requests on a hosting service
+type Forge interface {
+	// CreatePullRequest opens a pull/merge request and applies labels and reviewers
+	CreatePullRequest(pr *PullRequest) (*Created, error)
+
+	// Name returns the name of the forge
+	Name() string
+}
+
+// Repository identifies a repository on a forge
+type Repository struct {
+	Host  string // host (and port for HTTP remotes)
+	Owner string // owner, organisation or (nested) group
+	Name  string
+}
+
+// Path returns owner/name
+func (r *Repository) Path() string {
+	return r.Owner + "/" + r.Name
+}
+
+// Options configures how a forge client is created
+type Options struct {
+	Type    string // github, gitlab or gitea; detected from the host when empty
+	BaseURL string // API base URL; derived from the host when empty
+	Token   string
+}
+
+// New creates a forge client for the repository
+func New(repo *Repository, opts Options) (Forge, error) {
+	forgeType := opts.Type
+	if forgeType == "" {
+		forgeType = DetectType(repo.Host)
+	}
+	if forgeType == "" {
+		return nil, fmt.Errorf("cannot detect forge type for host '%s' (set forge.type in .gitext)", repo.Host)
+	}
+	if opts.Token == "" {
+		return nil, fmt.Errorf("no API token for %s (set %s)", forgeType, strings.Join(TokenEnvVars(forgeType), " or "))
+	}
+
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+	switch forgeType {
+	case TypeGitHub:
+		if baseURL == "" {
+			baseURL = "https://api.github.com"
+			if repo.Host != "github.com" {
+				baseURL = fmt.Sprintf("https://%s/api/v3", repo.Host)
+			}
+		}
+		return NewGitHub(baseURL, opts.Token, repo), nil
+	case TypeGitLab:
+		if baseURL == "" {
+			baseURL = fmt.Sprintf("https://%s/api/v4", repo.Host)
+		}
+		return NewGitLab(baseURL, opts.Token, repo), nil
+	case TypeGitea:
+		if baseURL == "" {
+			baseURL = fmt.Sprintf("https://%s/api/v1", repo.Host)
+		}
+		return NewGitea(baseURL, opts.Token, repo), nil
+	default:
+		return nil, fmt.Errorf("unknown forge type: %s", forgeType)
+	}
+}
+
+// DetectType guesses the forge type from a remote host
+func DetectType(host string) string {
+	host = strings.ToLower(host)
+	switch {
+	case host == "github.com" || strings.Contains(host, "github"):
+		return TypeGitHub
+	case strings.Contains(host, "gitlab"):
+		return TypeGitLab
+	case strings.Contains(host, "gitea") || host == "codeberg.org":
+		return TypeGitea
+	}
+	return ""
+}
+
+// TokenEnvVars returns the environment variables checked for a forge API token
+func TokenEnvVars(forgeType string) []string {
+	switch forgeType {
+	case TypeGitHub:
+		return []string{"GITHUB_TOKEN", "GH_TOKEN"}
+	case TypeGitLab:
+		return []string{"GITLAB_TOKEN"}
+	case TypeGitea:
+		return []string{"GITEA_TOKEN"}
+	}
+	return nil
+}
+
+// TokenFromEnv returns the first non-empty token from the given environment
+// variable, or from the default variables for the forge type
+func TokenFromEnv(forgeType, envVar string) string {
+	vars := TokenEnvVars(forgeType)
+	if envVar != "" {
+		vars = []string{envVar}
+	}
+	for _, name := range vars {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// ParseRemoteURL parses a git remote URL such as git@github.com:owner/repo.git
+// or https://gitlab.example.com/group/sub/repo.git
+func ParseRemoteURL(remoteURL string) (*Repository, error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	var host, path string
+
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote URL '%s': %w", remoteURL, err)
+		}
+		host = u.Host
+		if u.Scheme == "ssh" || u.Scheme == "git" {
+			// SSH ports are not API ports
+			host = u.Hostname()
+		}
+		path = u.Path
+	} else if idx := strings.Index(remoteURL, ":"); idx > 0 {
+		// scp-like syntax: [user@]host:path
+		host = remoteURL[:idx]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		path = remoteURL[idx+1:]
+	} else {
+		return nil, fmt.Errorf("unsupported remote URL '%s'", remoteURL)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	idx := strings.LastIndex(path, "/")
+	if host == "" || idx <= 0 || idx == len(path)-1 {
+		return nil, fmt.Errorf("remote URL '%s' does not point to a repository", remoteURL)
+	}
+
+	return &Repository{
+		Host:  host,
+		Owner: path[:idx],
+		Name:  path[idx+1:],
+	}, nil
+}
+
+// apiClient is a minimal JSON REST client shared by the forge implementations
+type apiClient struct {
+	name    string
+	baseURL string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newAPIClient(name, baseURL string, headers map[string]string) *apiClient {
+	return &apiClient{
+		name:    name,
+		baseURL: baseURL,
+		headers: headers,
+		client: &http.Client{
+			Timeout: forgeTimeout,
+		},
+	}
+}
+
+// do sends a JSON request and decodes the JSON response into out (if non-nil)
+func (c *apiClient) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		jsonData, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errorResp struct {
+			Message interface{} `json:"message"`
+			Error   string      `json:"error"`
+		}
+		if err := json.Unmarshal(respBody, &errorResp); err == nil && (errorResp.Message != nil || errorResp.Error != "") {
+			message := errorResp.Error
+			if errorResp.Message != nil {
+				message = fmt.Sprint(errorResp.Message)
+			}
+			return fmt.Errorf("%s API error: %s (status %d)", c.name, message, resp.StatusCode)
+		}
+		return fmt.Errorf("%s API error: status %d, body: %s", c.name, resp.StatusCode, string(respBody))
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
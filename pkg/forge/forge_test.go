@@ -0,0 +1,315 @@
+package forge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		host  string
+		owner string
+		name  string
+	}{
+		{"git@github.com:acme/widgets.git", "github.com", "acme", "widgets"},
+		{"https://github.com/acme/widgets", "github.com", "acme", "widgets"},
+		{"https://token@gitlab.example.com/group/sub/widgets.git", "gitlab.example.com", "group/sub", "widgets"},
+		{"ssh://git@gitea.example.com:2222/acme/widgets.git", "gitea.example.com", "acme", "widgets"},
+		{"http://localhost:3000/acme/widgets.git", "localhost:3000", "acme", "widgets"},
+	}
+
+	for _, tt := range tests {
+		repo, err := ParseRemoteURL(tt.url)
+		if err != nil {
+			t.Errorf("ParseRemoteURL(%q) error = %v", tt.url, err)
+			continue
+		}
+		if repo.Host != tt.host || repo.Owner != tt.owner || repo.Name != tt.name {
+			t.Errorf("ParseRemoteURL(%q) = %+v, want %s %s/%s", tt.url, repo, tt.host, tt.owner, tt.name)
+		}
+	}
+
+	for _, bad := range []string{"/tmp/repo", "git@github.com:widgets", ""} {
+		if _, err := ParseRemoteURL(bad); err == nil {
+			t.Errorf("ParseRemoteURL(%q) should fail", bad)
+		}
+	}
+}
+
+func TestDetectType(t *testing.T) {
+	tests := map[string]string{
+		"github.com":         TypeGitHub,
+		"gitlab.com":         TypeGitLab,
+		"gitlab.example.com": TypeGitLab,
+		"gitea.example.com":  TypeGitea,
+		"codeberg.org":       TypeGitea,
+		"git.example.com":    "",
+	}
+	for host, want := range tests {
+		if got := DetectType(host); got != want {
+			t.Errorf("DetectType(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+// recordedRequest is a request received by the mock forge server
+type recordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// mockServer serves canned JSON responses keyed by "METHOD path" and records requests
+func mockServer(t *testing.T, responses map[string]string) (*httptest.Server, *[]recordedRequest) {
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := recordedRequest{Method: r.Method, Path: r.URL.RequestURI(), Header: r.Header}
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&rec.Body)
+		}
+		requests = append(requests, rec)
+
+		response, ok := responses[r.Method+" "+r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func testPullRequest() *PullRequest {
+	return &PullRequest{
+		Title:     "KWS-1: Add widgets",
+		Body:      "## Branch: feature/KWS-1-add-widgets",
+		Head:      "feature/KWS-1-add-widgets",
+		Base:      "stage",
+		Labels:    []string{"feature"},
+		Reviewers: []string{"alice"},
+	}
+}
+
+func TestGitHubCreatePullRequest(t *testing.T) {
+	server, requests := mockServer(t, map[string]string{
+		"POST /repos/acme/widgets/pulls":                       `{"number":7,"html_url":"https://github.com/acme/widgets/pull/7"}`,
+		"POST /repos/acme/widgets/issues/7/labels":             `[]`,
+		"POST /repos/acme/widgets/pulls/7/requested_reviewers": `{}`,
+	})
+
+	repo := &Repository{Host: "github.com", Owner: "acme", Name: "widgets"}
+	f, err := New(repo, Options{Type: TypeGitHub, BaseURL: server.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	created, err := f.CreatePullRequest(testPullRequest())
+	if err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if created.Number != 7 || created.URL != "https://github.com/acme/widgets/pull/7" {
+		t.Errorf("unexpected result: %+v", created)
+	}
+
+	if len(*requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(*requests))
+	}
+	create := (*requests)[0]
+	if create.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("unexpected Authorization header: %q", create.Header.Get("Authorization"))
+	}
+	if create.Body["head"] != "feature/KWS-1-add-widgets" || create.Body["base"] != "stage" || create.Body["title"] != "KWS-1: Add widgets" {
+		t.Errorf("unexpected create body: %v", create.Body)
+	}
+	if !reflect.DeepEqual((*requests)[1].Body["labels"], []interface{}{"feature"}) {
+		t.Errorf("unexpected labels body: %v", (*requests)[1].Body)
+	}
+	if !reflect.DeepEqual((*requests)[2].Body["reviewers"], []interface{}{"alice"}) {
+		t.Errorf("unexpected reviewers body: %v", (*requests)[2].Body)
+	}
+}
+
+func TestGitLabCreatePullRequest(t *testing.T) {
+	server, requests := mockServer(t, map[string]string{
+		"GET /users?username=alice":                           `[{"id":42}]`,
+		"POST /projects/group%2Fsub%2Fwidgets/merge_requests": `{"iid":3,"web_url":"https://gitlab.example.com/group/sub/widgets/-/merge_requests/3"}`,
+	})
+
+	repo := &Repository{Host: "gitlab.example.com", Owner: "group/sub", Name: "widgets"}
+	f, err := New(repo, Options{BaseURL: server.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	created, err := f.CreatePullRequest(testPullRequest())
+	if err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if created.Number != 3 {
+		t.Errorf("unexpected result: %+v", created)
+	}
+
+	create := (*requests)[len(*requests)-1]
+	if create.Header.Get("PRIVATE-TOKEN") != "secret" {
+		t.Errorf("unexpected PRIVATE-TOKEN header: %q", create.Header.Get("PRIVATE-TOKEN"))
+	}
+	if create.Body["source_branch"] != "feature/KWS-1-add-widgets" || create.Body["target_branch"] != "stage" {
+		t.Errorf("unexpected create body: %v", create.Body)
+	}
+	if create.Body["labels"] != "feature" || !reflect.DeepEqual(create.Body["reviewer_ids"], []interface{}{float64(42)}) {
+		t.Errorf("unexpected labels/reviewers: %v", create.Body)
+	}
+}
+
+func TestGiteaCreatePullRequest(t *testing.T) {
+	server, requests := mockServer(t, map[string]string{
+		"GET /repos/acme/widgets/labels?page=1&limit=50":       `[{"id":5,"name":"feature"},{"id":6,"name":"bug"}]`,
+		"POST /repos/acme/widgets/pulls":                       `{"number":9,"html_url":"https://gitea.example.com/acme/widgets/pulls/9"}`,
+		"POST /repos/acme/widgets/pulls/9/requested_reviewers": `[]`,
+	})
+
+	repo := &Repository{Host: "gitea.example.com", Owner: "acme", Name: "widgets"}
+	f, err := New(repo, Options{BaseURL: server.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	created, err := f.CreatePullRequest(testPullRequest())
+	if err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if created.Number != 9 {
+		t.Errorf("unexpected result: %+v", created)
+	}
+
+	create := (*requests)[1]
+	if create.Header.Get("Authorization") != "token secret" {
+		t.Errorf("unexpected Authorization header: %q", create.Header.Get("Authorization"))
+	}
+	if !reflect.DeepEqual(create.Body["labels"], []interface{}{float64(5)}) {
+		t.Errorf("unexpected labels: %v", create.Body)
+	}
+	if !reflect.DeepEqual((*requests)[2].Body["reviewers"], []interface{}{"alice"}) {
+		t.Errorf("unexpected reviewers body: %v", (*requests)[2].Body)
+	}
+}
+
+func TestGiteaLabelsArePaged(t *testing.T) {
+	server, requests := mockServer(t, map[string]string{
+		"GET /repos/acme/widgets/labels?page=1&limit=50": `[{"id":5,"name":"feature"},{"id":6,"name":"bug"}]`,
+		"GET /repos/acme/widgets/labels?page=2&limit=50": `[{"id":7,"name":"urgent"}]`,
+		"GET /repos/acme/widgets/labels?page=3&limit=50": `[]`,
+		"POST /repos/acme/widgets/pulls":                 `{"number":9,"html_url":"https://gitea.example.com/acme/widgets/pulls/9"}`,
+	})
+
+	repo := &Repository{Host: "gitea.example.com", Owner: "acme", Name: "widgets"}
+	f, err := New(repo, Options{BaseURL: server.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	pr := testPullRequest()
+	pr.Labels = []string{"urgent", "feature"}
+	pr.Reviewers = nil
+	if _, err := f.CreatePullRequest(pr); err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	create := (*requests)[len(*requests)-1]
+	if !reflect.DeepEqual(create.Body["labels"], []interface{}{float64(7), float64(5)}) {
+		t.Errorf("unexpected labels: %v", create.Body)
+	}
+
+	// A missing label is looked for on every page, and no PR is opened
+	*requests = nil
+	pr.Labels = []string{"missing"}
+	if _, err := f.CreatePullRequest(pr); err == nil || !strings.Contains(err.Error(), "label 'missing' not found") {
+		t.Fatalf("expected a missing label error, got %v", err)
+	}
+	if len(*requests) != 3 {
+		t.Errorf("expected all 3 label pages to be read, got %d requests", len(*requests))
+	}
+}
+
+func TestCreatePullRequestFromFork(t *testing.T) {
+	server, requests := mockServer(t, map[string]string{
+		"POST /repos/acme/widgets/pulls": `{"number":8,"html_url":"https://github.com/acme/widgets/pull/8"}`,
+	})
+
+	repo := &Repository{Host: "github.com", Owner: "acme", Name: "widgets"}
+	f, err := New(repo, Options{Type: TypeGitHub, BaseURL: server.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	pr := testPullRequest()
+	pr.Labels, pr.Reviewers = nil, nil
+	pr.HeadRepo = &Repository{Host: "github.com", Owner: "alice", Name: "widgets"}
+	if _, err := f.CreatePullRequest(pr); err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if head := (*requests)[0].Body["head"]; head != "alice:feature/KWS-1-add-widgets" {
+		t.Errorf("expected fork head, got %v", head)
+	}
+}
+
+func TestGitLabCreateMergeRequestFromFork(t *testing.T) {
+	server, requests := mockServer(t, map[string]string{
+		"GET /projects/group%2Fwidgets":                 `{"id":17}`,
+		"POST /projects/alice%2Fwidgets/merge_requests": `{"iid":4,"web_url":"https://gitlab.example.com/group/widgets/-/merge_requests/4"}`,
+	})
+
+	repo := &Repository{Host: "gitlab.example.com", Owner: "group", Name: "widgets"}
+	f, err := New(repo, Options{BaseURL: server.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	pr := testPullRequest()
+	pr.Reviewers = nil
+	pr.HeadRepo = &Repository{Host: "gitlab.example.com", Owner: "alice", Name: "widgets"}
+	created, err := f.CreatePullRequest(pr)
+	if err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if created.Number != 4 {
+		t.Errorf("unexpected result: %+v", created)
+	}
+
+	create := (*requests)[len(*requests)-1]
+	if create.Body["source_branch"] != "feature/KWS-1-add-widgets" || create.Body["target_project_id"] != float64(17) {
+		t.Errorf("unexpected create body: %v", create.Body)
+	}
+}
+
+func TestCreatePullRequestAPIError(t *testing.T) {
+	server, _ := mockServer(t, map[string]string{})
+
+	repo := &Repository{Host: "github.com", Owner: "acme", Name: "widgets"}
+	f, err := New(repo, Options{BaseURL: server.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := f.CreatePullRequest(&PullRequest{Title: "x", Head: "a", Base: "b"}); err == nil {
+		t.Error("expected error from API")
+	}
+}
+
+func TestNewRequiresToken(t *testing.T) {
+	repo := &Repository{Host: "github.com", Owner: "acme", Name: "widgets"}
+	if _, err := New(repo, Options{}); err == nil {
+		t.Error("expected error without token")
+	}
+	if _, err := New(&Repository{Host: "git.example.com", Owner: "a", Name: "b"}, Options{Token: "x"}); err == nil {
+		t.Error("expected error for undetectable forge type")
+	}
+}
@@ -0,0 +1,115 @@
+package forge
+
+import (
+	"fmt"
+)
+
+// Gitea implements the Forge interface for Gitea and Forgejo
+type Gitea struct {
+	api  *apiClient
+	repo *Repository
+}
+
+// NewGitea creates a new Gitea client
+func NewGitea(baseURL, token string, repo *Repository) *Gitea {
+	return &Gitea{
+		api: newAPIClient("Gitea", baseURL, map[string]string{
+			"Authorization": "token " + token,
+		}),
+		repo: repo,
+	}
+}
+
+// Name returns the forge name
+func (f *Gitea) Name() string {
+	return "Gitea"
+}
+
+// CreatePullRequest opens a pull request
+func (f *Gitea) CreatePullRequest(pr *PullRequest) (*Created, error) {
+	repoPath := fmt.Sprintf("/repos/%s/%s", f.repo.Owner, f.repo.Name)
+
+	request := map[string]interface{}{
+		"title": pr.Title,
+		"body":  pr.Body,
+		"head":  pr.HeadRef(),
+		"base":  pr.Base,
+	}
+
+	// Gitea assigns labels by ID
+	if len(pr.Labels) > 0 {
+		ids, err := f.labelIDs(repoPath, pr.Labels)
+		if err != nil {
+			return nil, err
+		}
+		request["labels"] = ids
+	}
+
+	var response struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := f.api.do("POST", repoPath+"/pulls", request, &response); err != nil {
+		return nil, err
+	}
+
+	created := &Created{Number: response.Number, URL: response.HTMLURL}
+
+	if len(pr.Reviewers) > 0 {
+		path := fmt.Sprintf("%s/pulls/%d/requested_reviewers", repoPath, response.Number)
+		if err := f.api.do("POST", path, map[string]interface{}{"reviewers": pr.Reviewers}, nil); err != nil {
+			return created, fmt.Errorf("pull request created but reviewers failed: %w", err)
+		}
+	}
+
+	return created, nil
+}
+
+// giteaLabelPageSize is how many labels are requested per page; servers
+// may return fewer
+const giteaLabelPageSize = 50
+
+// labelIDs resolves label names to Gitea label IDs, paging through the
+// repository's labels until all are found or an empty page comes back
+func (f *Gitea) labelIDs(repoPath string, names []string) ([]int, error) {
+	byName := make(map[string]int)
+	for page := 1; ; page++ {
+		var labels []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		path := fmt.Sprintf("%s/labels?page=%d&limit=%d", repoPath, page, giteaLabelPageSize)
+		if err := f.api.do("GET", path, nil, &labels); err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		if len(labels) == 0 {
+			break
+		}
+		for _, label := range labels {
+			byName[label.Name] = label.ID
+		}
+		if hasAllLabels(byName, names) {
+			break
+		}
+	}
+
+	var ids []int
+	for _, name := range names {
+		id, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("label '%s' not found on Gitea", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// hasAllLabels reports whether every name has been resolved
+func hasAllLabels(byName map[string]int, names []string) bool {
+	for _, name := range names {
+		if _, ok := byName[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
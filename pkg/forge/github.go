@@ -0,0 +1,65 @@
+package forge
+
+import (
+	"fmt"
+)
+
+// GitHub implements the Forge interface for GitHub and GitHub Enterprise
+type GitHub struct {
+	api  *apiClient
+	repo *Repository
+}
+
+// NewGitHub creates a new GitHub client
+func NewGitHub(baseURL, token string, repo *Repository) *GitHub {
+	return &GitHub{
+		api: newAPIClient("GitHub", baseURL, map[string]string{
+			"Authorization": "Bearer " + token,
+			"Accept":        "application/vnd.github+json",
+		}),
+		repo: repo,
+	}
+}
+
+// Name returns the forge name
+func (f *GitHub) Name() string {
+	return "GitHub"
+}
+
+// CreatePullRequest opens a pull request
+func (f *GitHub) CreatePullRequest(pr *PullRequest) (*Created, error) {
+	repoPath := fmt.Sprintf("/repos/%s/%s", f.repo.Owner, f.repo.Name)
+
+	var response struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	request := map[string]interface{}{
+		"title": pr.Title,
+		"body":  pr.Body,
+		"head":  pr.HeadRef(),
+		"base":  pr.Base,
+	}
+	if err := f.api.do("POST", repoPath+"/pulls", request, &response); err != nil {
+		return nil, err
+	}
+
+	created := &Created{Number: response.Number, URL: response.HTMLURL}
+
+	// Pull requests share labels with issues
+	if len(pr.Labels) > 0 {
+		path := fmt.Sprintf("%s/issues/%d/labels", repoPath, response.Number)
+		if err := f.api.do("POST", path, map[string]interface{}{"labels": pr.Labels}, nil); err != nil {
+			return created, fmt.Errorf("pull request created but labels failed: %w", err)
+		}
+	}
+
+	if len(pr.Reviewers) > 0 {
+		path := fmt.Sprintf("%s/pulls/%d/requested_reviewers", repoPath, response.Number)
+		if err := f.api.do("POST", path, map[string]interface{}{"reviewers": pr.Reviewers}, nil); err != nil {
+			return created, fmt.Errorf("pull request created but reviewers failed: %w", err)
+		}
+	}
+
+	return created, nil
+}
@@ -0,0 +1,101 @@
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GitLab implements the Forge interface for GitLab merge requests
+type GitLab struct {
+	api  *apiClient
+	repo *Repository
+}
+
+// NewGitLab creates a new GitLab client
+func NewGitLab(baseURL, token string, repo *Repository) *GitLab {
+	return &GitLab{
+		api: newAPIClient("GitLab", baseURL, map[string]string{
+			"PRIVATE-TOKEN": token,
+		}),
+		repo: repo,
+	}
+}
+
+// Name returns the forge name
+func (f *GitLab) Name() string {
+	return "GitLab"
+}
+
+// CreatePullRequest opens a merge request
+func (f *GitLab) CreatePullRequest(pr *PullRequest) (*Created, error) {
+	request := map[string]interface{}{
+		"source_branch": pr.Head,
+		"target_branch": pr.Base,
+		"title":         pr.Title,
+		"description":   pr.Body,
+	}
+	if len(pr.Labels) > 0 {
+		request["labels"] = strings.Join(pr.Labels, ",")
+	}
+
+	// GitLab assigns reviewers by user ID
+	if len(pr.Reviewers) > 0 {
+		ids, err := f.userIDs(pr.Reviewers)
+		if err != nil {
+			return nil, err
+		}
+		request["reviewer_ids"] = ids
+	}
+
+	// A merge request from a fork is opened on the fork, targeting this project
+	project := f.repo.Path()
+	if pr.HeadRepo != nil {
+		id, err := f.projectID(project)
+		if err != nil {
+			return nil, err
+		}
+		request["target_project_id"] = id
+		project = pr.HeadRepo.Path()
+	}
+
+	var response struct {
+		IID    int    `json:"iid"`
+		WebURL string `json:"web_url"`
+	}
+	path := fmt.Sprintf("/projects/%s/merge_requests", url.PathEscape(project))
+	if err := f.api.do("POST", path, request, &response); err != nil {
+		return nil, err
+	}
+
+	return &Created{Number: response.IID, URL: response.WebURL}, nil
+}
+
+// projectID resolves a project path to its GitLab project ID
+func (f *GitLab) projectID(path string) (int, error) {
+	var project struct {
+		ID int `json:"id"`
+	}
+	if err := f.api.do("GET", "/projects/"+url.PathEscape(path), nil, &project); err != nil {
+		return 0, fmt.Errorf("failed to look up project '%s': %w", path, err)
+	}
+	return project.ID, nil
+}
+
+// userIDs resolves usernames to GitLab user IDs
+func (f *GitLab) userIDs(usernames []string) ([]int, error) {
+	var ids []int
+	for _, username := range usernames {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		if err := f.api.do("GET", "/users?username="+url.QueryEscape(username), nil, &users); err != nil {
+			return nil, fmt.Errorf("failed to look up reviewer '%s': %w", username, err)
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("reviewer '%s' not found on GitLab", username)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
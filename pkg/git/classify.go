@@ -0,0 +1,138 @@
+package git
+
+import (
+	"strings"
+)
+
+// readOnlyCommands are git subcommands that never change refs, the index,
+// the working tree or a remote
+var readOnlyCommands = map[string]bool{
+	"blame":              true,
+	"cat-file":           true,
+	"check-ref-format":   true,
+	"cherry":             true,
+	"describe":           true,
+	"diff":               true,
+	"for-each-ref":       true,
+	"grep":               true,
+	"log":                true,
+	"ls-files":           true,
+	"ls-remote":          true,
+	"ls-tree":            true,
+	"merge-base":         true,
+	"name-rev":           true,
+	"rev-list":           true,
+	"rev-parse":          true,
+	"shortlog":           true,
+	"show":               true,
+	"show-ref":           true,
+	"status":             true,
+	"var":                true,
+	"version":            true,
+	"--version":          true,
+	"check-ignore":       true,
+	"count-objects":      true,
+	"verify-commit":      true,
+	"interpret-trailers": true,
+}
+
+// IsReadOnly reports whether a git invocation only reads repository state.
+// Unknown subcommands are treated as mutating, so dry-run errs on the side
+// of not running them.
+func IsReadOnly(args []string) bool {
+	args = skipGlobalOptions(args)
+	if len(args) == 0 {
+		return true
+	}
+
+	command, rest := args[0], args[1:]
+	if readOnlyCommands[command] {
+		return true
+	}
+
+	switch command {
+	case "branch":
+		// Listing forms only: --list, --merged, --contains, -r, -a, --format, ...
+		for _, arg := range rest {
+			switch arg {
+			case "-d", "-D", "--delete", "-m", "-M", "--move", "-c", "-C", "--copy",
+				"-f", "--force", "-u", "--set-upstream-to", "--unset-upstream", "--edit-description":
+				return false
+			}
+		}
+		for _, arg := range rest {
+			if arg == "--list" || arg == "-l" {
+				return true
+			}
+		}
+		return !hasPositional(rest, "--format", "--merged", "--no-merged", "--contains", "--no-contains", "--points-at", "--sort")
+	case "config":
+		for _, arg := range rest {
+			switch arg {
+			case "--get", "--get-all", "--get-regexp", "--list", "-l":
+				return true
+			}
+		}
+		return false
+	case "remote":
+		return len(rest) == 0 || rest[0] == "-v" || rest[0] == "get-url" || rest[0] == "show"
+	case "stash":
+		return len(rest) > 0 && (rest[0] == "list" || rest[0] == "show")
+	case "worktree":
+		return len(rest) > 0 && rest[0] == "list"
+	case "tag":
+		return len(rest) == 0 || rest[0] == "-l" || rest[0] == "--list"
+	case "symbolic-ref":
+		for _, arg := range rest {
+			if arg == "-d" || arg == "--delete" {
+				return false
+			}
+		}
+		return len(positionals(rest)) < 2
+	case "reflog":
+		return len(rest) == 0 || (rest[0] != "expire" && rest[0] != "delete")
+	}
+
+	return false
+}
+
+// skipGlobalOptions drops options given before the subcommand (e.g., -C <dir>)
+func skipGlobalOptions(args []string) []string {
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && !readOnlyCommands[args[0]] {
+		if args[0] == "-C" || args[0] == "-c" {
+			args = args[1:]
+		}
+		if len(args) > 0 {
+			args = args[1:]
+		}
+	}
+	return args
+}
+
+// hasPositional reports whether args contain a non-option argument, skipping
+// the values of the given options
+func hasPositional(args []string, optionsWithValue ...string) bool {
+	return len(positionals(args, optionsWithValue...)) > 0
+}
+
+// positionals returns the non-option arguments, skipping the values of the
+// given options
+func positionals(args []string, optionsWithValue ...string) []string {
+	takesValue := make(map[string]bool)
+	for _, option := range optionsWithValue {
+		takesValue[option] = true
+	}
+
+	var result []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if takesValue[arg] {
+			i++
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			result = append(result, arg)
+		}
+	}
+	return result
+}
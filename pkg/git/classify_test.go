@@ -0,0 +1,50 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsReadOnly(t *testing.T) {
+	readOnly := []string{
+		"rev-parse --abbrev-ref HEAD",
+		"status --porcelain",
+		"log --oneline a..b",
+		"branch --merged stage --format %(refname:short)",
+		"branch -r --format %(refname:short)",
+		"config --get user.email",
+		"remote get-url origin",
+		"stash list",
+		"worktree list --porcelain",
+		"symbolic-ref HEAD",
+		"-C /tmp/repo rev-parse HEAD",
+		"merge-base --is-ancestor a b",
+	}
+	for _, cmd := range readOnly {
+		if !IsReadOnly(strings.Fields(cmd)) {
+			t.Errorf("expected read-only: git %s", cmd)
+		}
+	}
+
+	mutating := []string{
+		"checkout -b feature/x origin/stage",
+		"fetch origin",
+		"pull --ff-only origin stage",
+		"push -u origin feature/x",
+		"branch -D feature/x",
+		"branch feature/x",
+		"config user.email a@b",
+		"remote add origin url",
+		"stash push --include-untracked",
+		"worktree add ../x",
+		"commit -m msg",
+		"cherry-pick -x abc",
+		"symbolic-ref HEAD refs/heads/x",
+		"some-unknown-command",
+	}
+	for _, cmd := range mutating {
+		if IsReadOnly(strings.Fields(cmd)) {
+			t.Errorf("expected mutating: git %s", cmd)
+		}
+	}
+}
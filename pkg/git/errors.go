@@ -0,0 +1,179 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrorKind classifies why a git command failed
+type ErrorKind string
+
+// Kinds of git failure that commands can react to
+const (
+	ErrorUnknown        ErrorKind = ""
+	ErrorConflict       ErrorKind = "conflict"
+	ErrorNonFastForward ErrorKind = "non-fast-forward"
+	ErrorAuth           ErrorKind = "auth"
+	ErrorNetwork        ErrorKind = "network"
+	ErrorLockHeld       ErrorKind = "lock-held"
+	ErrorNotFound       ErrorKind = "not-found"
+)
+
+// Error is a failed git command
+type Error struct {
+	Args     []string
+	ExitCode int // -1 when git did not exit (e.g., it was not found or timed out)
+	Stdout   string
+	Stderr   string
+	Kind     ErrorKind
+	Err      error // the error from running the process
+}
+
+func (e *Error) Error() string {
+	detail := e.Stderr
+	if detail == "" {
+		detail = e.Stdout
+	}
+	return fmt.Sprintf("git %s: %v\n%s", strings.Join(e.Args, " "), e.Err, detail)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Summary returns the most relevant line git printed, or the process error
+// when git printed nothing
+func (e *Error) Summary() string {
+	for _, output := range []string{e.Stderr, e.Stdout} {
+		var fallback string
+		for _, line := range strings.Split(output, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "hint:") {
+				continue
+			}
+			if fallback == "" {
+				fallback = line
+			}
+			if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") {
+				return line
+			}
+		}
+		if fallback != "" {
+			return fallback
+		}
+	}
+	return e.Err.Error()
+}
+
+// newError builds the Error for a git command that failed with err
+func newError(args []string, stdout, stderr string, err error) *Error {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+	return &Error{
+		Args:     args,
+		ExitCode: exitCode,
+		Stdout:   stdout,
+		Stderr:   stderr,
+		Kind:     classifyError(stdout + "\n" + stderr),
+		Err:      err,
+	}
+}
+
+// KindOf returns the kind of a git failure anywhere in err's chain
+func KindOf(err error) ErrorKind {
+	var gitErr *Error
+	if errors.As(err, &gitErr) {
+		return gitErr.Kind
+	}
+	return ErrorUnknown
+}
+
+// errorPatterns maps what git prints to the kind of failure, checked in
+// order: a rejected push over a dead connection is a network error first
+var errorPatterns = []struct {
+	kind     ErrorKind
+	patterns []string
+}{
+	{ErrorLockHeld, []string{
+		".lock': file exists",
+		"another git process seems to be running",
+		"cannot lock ref",
+	}},
+	// Only what remotes and transports print: a local "permission denied"
+	// (e.g., on a hook or the index) is not a credentials problem
+	{ErrorAuth, []string{
+		"authentication failed",
+		"permission denied (publickey",
+		"remote: permission to ",
+		"error: permission to ",
+		"could not read username",
+		"could not read password",
+		"terminal prompts disabled",
+		"invalid username or password",
+		"host key verification failed",
+		"the requested url returned error: 401",
+		"the requested url returned error: 403",
+	}},
+	{ErrorNetwork, []string{
+		"could not resolve host",
+		"could not resolve hostname",
+		"connection refused",
+		"connection timed out",
+		"operation timed out",
+		"network is unreachable",
+		"failed to connect",
+		"connection reset",
+		"the remote end hung up unexpectedly",
+		"early eof",
+	}},
+	{ErrorNonFastForward, []string{
+		"non-fast-forward",
+		"not possible to fast-forward",
+		"cannot fast-forward",
+		"diverging branches can't be fast-forwarded",
+		"(fetch first)",
+		"(stale info)",
+		"updates were rejected",
+	}},
+	{ErrorConflict, []string{
+		"conflict (",
+		"automatic merge failed",
+		"could not apply",
+		"fix conflicts",
+		"resolve all conflicts",
+		"you have unmerged paths",
+		"needs merge",
+		"is unmerged",
+	}},
+	{ErrorNotFound, []string{
+		"not a valid object name",
+		"unknown revision",
+		"bad revision",
+		"invalid reference",
+		"did not match any",
+		"couldn't find remote ref",
+		"does not appear to be a git repository",
+		"repository not found",
+		"fatal: repository '",
+		"the requested url returned error: 404",
+		"no such remote",
+	}},
+}
+
+// classifyError returns the kind of failure described by git's output
+func classifyError(output string) ErrorKind {
+	output = strings.ToLower(output)
+	for _, group := range errorPatterns {
+		for _, pattern := range group.patterns {
+			if strings.Contains(output, pattern) {
+				return group.kind
+			}
+		}
+	}
+	return ErrorUnknown
+}
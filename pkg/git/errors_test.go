@@ -0,0 +1,67 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		output string
+		want   ErrorKind
+	}{
+		{"CONFLICT (content): Merge conflict in login.txt\nAutomatic merge failed; fix conflicts and then commit the result.", ErrorConflict},
+		{"error: could not apply 1a2b3c4... KWS-1 add login", ErrorConflict},
+		{"fatal: Not possible to fast-forward, aborting.", ErrorNonFastForward},
+		{" ! [rejected]        feature/KWS-1 -> feature/KWS-1 (non-fast-forward)\nerror: failed to push some refs", ErrorNonFastForward},
+		{" ! [rejected]        stage -> stage (fetch first)", ErrorNonFastForward},
+		{"remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/org/repo.git/'", ErrorAuth},
+		{"git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.", ErrorAuth},
+		{"fatal: unable to access 'https://github.com/org/repo.git/': Could not resolve host: github.com", ErrorNetwork},
+		{"ssh: connect to host github.com port 22: Connection refused", ErrorNetwork},
+		{"fatal: Unable to create '/repo/.git/index.lock': File exists.\n\nAnother git process seems to be running in this repository", ErrorLockHeld},
+		{"fatal: couldn't find remote ref feature/KWS-9", ErrorNotFound},
+		{"error: pathspec 'qa' did not match any file(s) known to git", ErrorNotFound},
+		{"fatal: ambiguous argument 'origin/qa': unknown revision or path not in the working tree.", ErrorNotFound},
+		{"remote: Permission to org/repo.git denied to bob.\nfatal: unable to access 'https://github.com/org/repo.git/': The requested URL returned error: 403", ErrorAuth},
+		{"ERROR: Permission to org/repo.git denied to deploy key\nfatal: Could not read from remote repository.", ErrorAuth},
+		{"remote: Repository not found.\nfatal: repository 'https://github.com/org/gone.git/' not found", ErrorNotFound},
+		{"error: Your local changes to the following files would be overwritten by checkout", ErrorUnknown},
+
+		// Local failures are not credentials or missing refs
+		{"fatal: Unable to create '/repo/.git/index.lock': Permission denied", ErrorUnknown},
+		{"error: cannot run .git/hooks/pre-push: Permission denied", ErrorUnknown},
+		{"error: open(\"build/out.txt\"): Permission denied", ErrorUnknown},
+		{".git/hooks/pre-push: line 2: golangci-lint: command not found\nerror: failed to push some refs", ErrorUnknown},
+		{"sh: 1: make: not found", ErrorUnknown},
+	}
+	for _, tt := range tests {
+		if got := classifyError(tt.output); got != tt.want {
+			t.Errorf("classifyError(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestRunReturnsError(t *testing.T) {
+	newTestRepo(t)
+	g := NewGit(false, false)
+
+	_, err := g.RunWithTimeout("checkout", "feature/KWS-9-missing")
+	var gitErr *Error
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("expected a *git.Error, got %T: %v", err, err)
+	}
+	if gitErr.ExitCode != 1 || gitErr.Kind != ErrorNotFound {
+		t.Errorf("expected exit code 1 and kind %q, got %d and %q", ErrorNotFound, gitErr.ExitCode, gitErr.Kind)
+	}
+	if gitErr.Stdout != "" || gitErr.Stderr == "" {
+		t.Errorf("expected the message on stderr only, got stdout %q, stderr %q", gitErr.Stdout, gitErr.Stderr)
+	}
+	if got := gitErr.Summary(); got != "error: pathspec 'feature/KWS-9-missing' did not match any file(s) known to git" {
+		t.Errorf("unexpected summary %q", got)
+	}
+	if wrapped := fmt.Errorf("checkout failed: %w", err); KindOf(wrapped) != ErrorNotFound {
+		t.Errorf("expected the kind through wrapping, got %q", KindOf(wrapped))
+	}
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FakeRepository is an in-memory Repository for unit tests of the command
+// layer. The zero value is a clean repository on an unnamed branch.
+type FakeRepository struct {
+	Branch     string
+	Dirty      bool
+	StagedDiff string
+
+	// Commits lists the commits unique to the current branch
+	Commits []CommitIdentity
+
+	// Branches lists local branches; RemoteBranches lists branches per
+	// remote, and its keys are the configured remotes
+	Branches       []string
+	RemoteBranches map[string][]string
+
+	// Merged lists the local branches merged into each branch
+	Merged map[string][]string
+
+	// Worktrees lists the worktrees, the main one first
+	Worktrees []Worktree
+
+	// AheadBehind holds {ahead, behind} of HEAD per remote branch ("origin/stage")
+	AheadBehind map[string][2]int
+
+	// InProgress is the operation git has in progress (e.g., "rebase")
+	InProgress string
+}
+
+// GetCurrentBranch returns the configured branch
+func (f *FakeRepository) GetCurrentBranch() (string, error) {
+	return f.Branch, nil
+}
+
+// IsWorkingTreeClean reports whether the fake is not dirty
+func (f *FakeRepository) IsWorkingTreeClean() (bool, error) {
+	return !f.Dirty, nil
+}
+
+// GetAheadBehind returns the configured counts for remote/branch
+func (f *FakeRepository) GetAheadBehind(remote, branch string) (ahead, behind int, err error) {
+	counts, ok := f.AheadBehind[remote+"/"+branch]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown revision %s/%s", remote, branch)
+	}
+	return counts[0], counts[1], nil
+}
+
+// GetMergedBranches returns the configured merged branches, sorted
+func (f *FakeRepository) GetMergedBranches(intoBranch string) ([]string, error) {
+	branches := append([]string{}, f.Merged[intoBranch]...)
+	sort.Strings(branches)
+	return branches, nil
+}
+
+// GetStagedDiff returns the configured diff
+func (f *FakeRepository) GetStagedDiff() (string, error) {
+	return f.StagedDiff, nil
+}
+
+// HasStagedChanges reports whether a staged diff is configured
+func (f *FakeRepository) HasStagedChanges() (bool, error) {
+	return f.StagedDiff != "", nil
+}
+
+// GetUniqueCommits returns the configured commits of the branch
+func (f *FakeRepository) GetUniqueCommits(base string) ([]CommitIdentity, error) {
+	return f.Commits, nil
+}
+
+// BranchExists checks the configured local branches
+func (f *FakeRepository) BranchExists(branch string) (bool, error) {
+	return containsBranch(f.Branches, branch), nil
+}
+
+// RemoteBranchExists checks the configured remote branches
+func (f *FakeRepository) RemoteBranchExists(remote, branch string) (bool, error) {
+	return containsBranch(f.RemoteBranches[remote], branch), nil
+}
+
+// TrackingBranchExists checks the configured remote branches
+func (f *FakeRepository) TrackingBranchExists(remote, branch string) (bool, error) {
+	return f.RemoteBranchExists(remote, branch)
+}
+
+func containsBranch(branches []string, branch string) bool {
+	for _, b := range branches {
+		if b == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRemote checks the remote is one of the configured remotes
+func (f *FakeRepository) ValidateRemote(remote string) error {
+	if _, ok := f.RemoteBranches[remote]; !ok {
+		return errRemoteNotFound(remote)
+	}
+	return nil
+}
+
+// GetInProgressOperation returns the configured operation
+func (f *FakeRepository) GetInProgressOperation() (string, error) {
+	return f.InProgress, nil
+}
+
+// ListWorktrees returns the configured worktrees
+func (f *FakeRepository) ListWorktrees() ([]Worktree, error) {
+	return append([]Worktree{}, f.Worktrees...), nil
+}
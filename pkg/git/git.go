@@ -1,8 +1,11 @@
 package git
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
@@ -16,6 +19,24 @@ const (
 type Git struct {
 	dryRun  bool
 	verbose bool
+	offline bool
+	plan    *Plan
+	log     io.Writer // receives dry-run steps and, when verbose, git calls
+}
+
+// Plan is the ordered list of mutating steps skipped during a dry run
+type Plan struct {
+	steps []string
+}
+
+// Add records a step that would have been performed
+func (p *Plan) Add(step string) {
+	p.steps = append(p.steps, step)
+}
+
+// Steps returns the recorded steps in order
+func (p *Plan) Steps() []string {
+	return p.steps
 }
 
 // NewGit creates a new Git instance
@@ -23,9 +44,37 @@ func NewGit(dryRun, verbose bool) *Git {
 	return &Git{
 		dryRun:  dryRun,
 		verbose: verbose,
+		plan:    &Plan{},
+		log:     os.Stdout,
 	}
 }
 
+// DryRun reports whether mutating commands are skipped
+func (g *Git) DryRun() bool {
+	return g.dryRun
+}
+
+// SetOffline makes remote branch queries use the remote-tracking refs from
+// the last fetch instead of contacting the remote
+func (g *Git) SetOffline(offline bool) {
+	g.offline = offline
+}
+
+// SetLog sets where dry-run steps and verbose git calls are printed
+func (g *Git) SetLog(w io.Writer) {
+	g.log = w
+}
+
+// Offline reports whether the remote is not contacted for queries
+func (g *Git) Offline() bool {
+	return g.offline
+}
+
+// Plan returns the mutating steps skipped so far in dry-run mode
+func (g *Git) Plan() *Plan {
+	return g.plan
+}
+
 // Run executes a git command and returns the output
 func (g *Git) Run(ctx context.Context, args ...string) (string, error) {
 	return g.RunWithDir(ctx, "", args...)
@@ -38,20 +87,31 @@ func (g *Git) RunWithDir(ctx context.Context, dir string, args ...string) (strin
 		cmd.Dir = dir
 	}
 
-	if g.dryRun {
-		fmt.Printf("[DRY RUN] git %s\n", strings.Join(args, " "))
+	// Read-only commands always run so dry runs take the same decisions as
+	// real runs; mutating commands are only recorded
+	if g.dryRun && !IsReadOnly(args) {
+		step := "git " + strings.Join(args, " ")
+		if dir != "" {
+			step = fmt.Sprintf("git -C %s %s", dir, strings.Join(args, " "))
+		}
+		fmt.Fprintf(g.log, "[DRY RUN] %s\n", step)
+		g.plan.Add(step)
 		return "", nil
 	}
 
-	output, err := cmd.CombinedOutput()
-	outputStr := strings.TrimSpace(string(output))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	outputStr := strings.TrimSpace(stdout.String())
+	errorStr := strings.TrimSpace(stderr.String())
 
 	if g.verbose {
-		fmt.Printf("$ git %s\n%s\n", strings.Join(args, " "), outputStr)
+		fmt.Fprintf(g.log, "$ git %s\n%s\n", strings.Join(args, " "), strings.TrimSpace(outputStr+"\n"+errorStr))
 	}
 
 	if err != nil {
-		return outputStr, fmt.Errorf("git %s: %w\n%s", strings.Join(args, " "), err, outputStr)
+		return outputStr, newError(args, outputStr, errorStr, err)
 	}
 
 	return outputStr, nil
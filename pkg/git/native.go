@@ -0,0 +1,138 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Native answers Repository queries in-process with go-git, without
+// spawning git. Remote branches are read from the remote-tracking refs
+// (refs/remotes/<remote>/*), so they reflect the last fetch rather than a
+// live ls-remote. Working tree and index status still run git: go-git
+// ignores core.excludesFile and core.autocrlf and hashes every file, so it
+// would report ignored or line-ending-only changes, slowly. History walks
+// (ahead/behind, merged branches, unique commits) run git too, since go-git
+// cannot stop at the merge base and would walk the whole history.
+type Native struct {
+	repo *gogit.Repository
+
+	// exec runs the queries go-git cannot answer, or answers slowly (status,
+	// the staged diff, history walks, linked worktrees and operations in
+	// progress)
+	exec *Git
+}
+
+// OpenNative opens the repository containing dir, including linked worktrees
+func OpenNative(dir string, exec *Git) (*Native, error) {
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return &Native{repo: repo, exec: exec}, nil
+}
+
+// GetCurrentBranch returns the current branch name, or HEAD when detached
+func (n *Native) GetCurrentBranch() (string, error) {
+	head, err := n.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	if !head.Name().IsBranch() {
+		return "HEAD", nil
+	}
+	return head.Name().Short(), nil
+}
+
+// IsWorkingTreeClean runs git, which honours core.excludesFile and
+// core.autocrlf unlike go-git's worktree status
+func (n *Native) IsWorkingTreeClean() (bool, error) {
+	return n.exec.IsWorkingTreeClean()
+}
+
+// GetAheadBehind runs git: rev-list stops walking at the merge base, while
+// go-git would walk the whole history of both sides
+func (n *Native) GetAheadBehind(remote, branch string) (ahead, behind int, err error) {
+	return n.exec.GetAheadBehind(remote, branch)
+}
+
+// GetMergedBranches runs git, as go-git would walk the whole history of
+// intoBranch to answer it
+func (n *Native) GetMergedBranches(intoBranch string) ([]string, error) {
+	return n.exec.GetMergedBranches(intoBranch)
+}
+
+// GetStagedDiff returns the diff of staged changes; go-git cannot diff the
+// index, so this runs git
+func (n *Native) GetStagedDiff() (string, error) {
+	return n.exec.GetStagedDiff()
+}
+
+// ListWorktrees runs git, as go-git does not know about linked worktrees
+func (n *Native) ListWorktrees() ([]Worktree, error) {
+	return n.exec.ListWorktrees()
+}
+
+// ValidateRemote checks if a remote is configured
+func (n *Native) ValidateRemote(remote string) error {
+	r, err := n.repo.Remote(remote)
+	if errors.Is(err, gogit.ErrRemoteNotFound) {
+		return errRemoteNotFound(remote)
+	}
+	if err != nil {
+		return err
+	}
+	if len(r.Config().URLs) == 0 {
+		return errRemoteNoURL(remote)
+	}
+	return nil
+}
+
+// GetInProgressOperation runs git, which knows where each worktree keeps
+// its rebase and merge state
+func (n *Native) GetInProgressOperation() (string, error) {
+	return n.exec.GetInProgressOperation()
+}
+
+// HasStagedChanges runs git, as go-git's status scans the whole working
+// tree to answer it
+func (n *Native) HasStagedChanges() (bool, error) {
+	return n.exec.HasStagedChanges()
+}
+
+// GetUniqueCommits runs git, as go-git would walk the whole history of base
+// to exclude it
+func (n *Native) GetUniqueCommits(base string) ([]CommitIdentity, error) {
+	return n.exec.GetUniqueCommits(base)
+}
+
+// BranchExists checks if a branch exists locally
+func (n *Native) BranchExists(branch string) (bool, error) {
+	return n.refExists(plumbing.NewBranchReferenceName(branch))
+}
+
+// RemoteBranchExists checks if the remote-tracking branch exists
+func (n *Native) RemoteBranchExists(remote, branch string) (bool, error) {
+	return n.refExists(plumbing.NewRemoteReferenceName(remote, branch))
+}
+
+// TrackingBranchExists checks if the remote-tracking branch exists
+func (n *Native) TrackingBranchExists(remote, branch string) (bool, error) {
+	return n.RemoteBranchExists(remote, branch)
+}
+
+func (n *Native) refExists(name plumbing.ReferenceName) (bool, error) {
+	_, err := n.repo.Reference(name, false)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
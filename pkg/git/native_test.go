@@ -0,0 +1,291 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a repository with a local remote and switches to it
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	dir := filepath.Join(root, "work")
+	remote := filepath.Join(root, "remote.git")
+	run := func(author string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL="+author+"@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	for _, args := range [][]string{{"--bare", remote}, {dir}} {
+		cmd := exec.Command("git", append([]string{"init", "-q", "-b", "production"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git init: %v\n%s", err, out)
+		}
+	}
+	run("Alice", "remote", "add", "origin", remote)
+	run("Alice", "commit", "-q", "--allow-empty", "-m", "Initial commit")
+	run("Alice", "branch", "stage")
+	run("Alice", "branch", "feature/KWS-1-merged")
+	run("Alice", "push", "-q", "origin", "production", "stage")
+	run("Alice", "checkout", "-q", "-b", "feature/KWS-2-login")
+	run("Alice", "commit", "-q", "--allow-empty", "-m", "KWS-2 first")
+	run("Bob", "commit", "-q", "--allow-empty", "-m", "KWS-2 second")
+	run("Alice", "push", "-q", "origin", "feature/KWS-2-login")
+	run("Alice", "commit", "-q", "--allow-empty", "-m", "KWS-2 unpushed")
+
+	if err := os.WriteFile(filepath.Join(dir, "staged.txt"), []byte("staged\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("Alice", "add", "staged.txt")
+
+	t.Chdir(dir)
+	return dir
+}
+
+func TestNativeMatchesExec(t *testing.T) {
+	dir := newTestRepo(t)
+
+	g := NewGit(false, false)
+	native, err := OpenNative(dir, g)
+	if err != nil {
+		t.Fatalf("OpenNative failed: %v", err)
+	}
+
+	backends := map[string]Repository{BackendExec: g, BackendNative: native}
+	results := make(map[string][]interface{})
+	for name, repo := range backends {
+		branch, err := repo.GetCurrentBranch()
+		if err != nil {
+			t.Fatalf("%s: GetCurrentBranch failed: %v", name, err)
+		}
+		clean, err := repo.IsWorkingTreeClean()
+		if err != nil {
+			t.Fatalf("%s: IsWorkingTreeClean failed: %v", name, err)
+		}
+		ahead, behind, err := repo.GetAheadBehind("origin", "stage")
+		if err != nil {
+			t.Fatalf("%s: GetAheadBehind failed: %v", name, err)
+		}
+		pushedAhead, pushedBehind, err := repo.GetAheadBehind("origin", "feature/KWS-2-login")
+		if err != nil {
+			t.Fatalf("%s: GetAheadBehind failed: %v", name, err)
+		}
+		merged, err := repo.GetMergedBranches("stage")
+		if err != nil {
+			t.Fatalf("%s: GetMergedBranches failed: %v", name, err)
+		}
+		staged, err := repo.HasStagedChanges()
+		if err != nil {
+			t.Fatalf("%s: HasStagedChanges failed: %v", name, err)
+		}
+		commits, err := repo.GetUniqueCommits("origin/stage")
+		if err != nil {
+			t.Fatalf("%s: GetUniqueCommits failed: %v", name, err)
+		}
+		var authors []string
+		for _, commit := range commits {
+			authors = append(authors, commit.AuthorEmail)
+		}
+		sort.Strings(authors)
+		exists, err := repo.BranchExists("stage")
+		if err != nil {
+			t.Fatalf("%s: BranchExists failed: %v", name, err)
+		}
+		missing, err := repo.BranchExists("feature/KWS-9-missing")
+		if err != nil {
+			t.Fatalf("%s: BranchExists failed: %v", name, err)
+		}
+		remoteExists, err := repo.RemoteBranchExists("origin", "feature/KWS-2-login")
+		if err != nil {
+			t.Fatalf("%s: RemoteBranchExists failed: %v", name, err)
+		}
+		remoteMissing, err := repo.RemoteBranchExists("origin", "feature/KWS-1-merged")
+		if err != nil {
+			t.Fatalf("%s: RemoteBranchExists failed: %v", name, err)
+		}
+
+		inProgress, err := repo.GetInProgressOperation()
+		if err != nil {
+			t.Fatalf("%s: GetInProgressOperation failed: %v", name, err)
+		}
+
+		results[name] = []interface{}{
+			branch, clean, ahead, behind, pushedAhead, pushedBehind, merged,
+			staged, authors, exists, missing, remoteExists, remoteMissing,
+			repo.ValidateRemote("origin") == nil, repo.ValidateRemote("upstream") == nil, inProgress,
+		}
+	}
+
+	want := []interface{}{
+		"feature/KWS-2-login", false, 3, 0, 1, 0, []string{"feature/KWS-1-merged", "production"},
+		true, []string{"Alice@example.com", "Alice@example.com", "Bob@example.com"}, true, false, true, false,
+		true, false, "",
+	}
+	for name, got := range results {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s backend:\n got %v\nwant %v", name, got, want)
+		}
+	}
+}
+
+func TestNativeWorkingTreeHonorsExcludesFile(t *testing.T) {
+	dir := newTestRepo(t)
+	g := NewGit(false, false)
+	t.Setenv("GIT_AUTHOR_NAME", "Alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "Alice@example.com")
+	if _, err := g.RunWithTimeout("commit", "-q", "-m", "KWS-2 add staged file"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ignored only by the global excludes file, which go-git does not read
+	excludes := filepath.Join(dir, "..", "excludes")
+	if err := os.WriteFile(excludes, []byte("*.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.RunWithTimeout("config", "core.excludesFile", excludes); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "debug.log"), []byte("debug\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	native, err := OpenNative(dir, g)
+	if err != nil {
+		t.Fatalf("OpenNative failed: %v", err)
+	}
+	for name, repo := range map[string]Repository{BackendExec: g, BackendNative: native} {
+		if clean, err := repo.IsWorkingTreeClean(); err != nil || !clean {
+			t.Errorf("%s backend: IsWorkingTreeClean() = %v, %v; want true", name, clean, err)
+		}
+	}
+}
+
+func TestNativeLongHistory(t *testing.T) {
+	dir := newTestRepo(t)
+
+	// 5000 shared commits; the local branch adds one commit and merges a side
+	// branch forked early in the history, the remote one adds three commits
+	const shared = 5000
+	var stream strings.Builder
+	commit := func(ref string, mark int, from string, merge string) {
+		fmt.Fprintf(&stream, "commit %s\nmark :%d\ncommitter Test <test@example.com> %d +0000\ndata 0\n", ref, mark, 1700000000+mark)
+		if from != "" {
+			fmt.Fprintf(&stream, "from %s\n", from)
+		}
+		if merge != "" {
+			fmt.Fprintf(&stream, "merge %s\n", merge)
+		}
+		stream.WriteString("\n")
+	}
+	for i := 1; i <= shared; i++ {
+		commit("refs/heads/feature/KWS-3-long", i, "", "")
+	}
+	commit("refs/heads/feature/KWS-4-side", shared+1, ":100", "")
+	commit("refs/remotes/origin/feature/KWS-3-long", shared+2, fmt.Sprintf(":%d", shared), "")
+	commit("refs/remotes/origin/feature/KWS-3-long", shared+3, "", "")
+	commit("refs/remotes/origin/feature/KWS-3-long", shared+4, "", "")
+	commit("refs/heads/feature/KWS-3-long", shared+5, fmt.Sprintf(":%d", shared), "")
+	commit("refs/heads/feature/KWS-3-long", shared+6, "", fmt.Sprintf(":%d", shared+1))
+
+	cmd := exec.Command("git", "fast-import", "--quiet")
+	cmd.Stdin = strings.NewReader(stream.String())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git fast-import: %v\n%s", err, out)
+	}
+
+	g := NewGit(false, false)
+	if _, err := g.RunWithTimeout("checkout", "-q", "feature/KWS-3-long"); err != nil {
+		t.Fatal(err)
+	}
+	native, err := OpenNative(dir, g)
+	if err != nil {
+		t.Fatalf("OpenNative failed: %v", err)
+	}
+
+	for name, repo := range map[string]Repository{BackendExec: g, BackendNative: native} {
+		ahead, behind, err := repo.GetAheadBehind("origin", "feature/KWS-3-long")
+		if err != nil || ahead != 3 || behind != 3 {
+			t.Errorf("%s backend: GetAheadBehind() = %d, %d, %v; want 3, 3", name, ahead, behind, err)
+		}
+		merged, err := repo.GetMergedBranches("feature/KWS-3-long")
+		if err != nil || !reflect.DeepEqual(merged, []string{"feature/KWS-4-side"}) {
+			t.Errorf("%s backend: GetMergedBranches() = %v, %v; want [feature/KWS-4-side]", name, merged, err)
+		}
+		commits, err := repo.GetUniqueCommits("origin/feature/KWS-3-long")
+		if err != nil || len(commits) != 3 {
+			t.Errorf("%s backend: GetUniqueCommits() returned %d commits, %v; want 3", name, len(commits), err)
+		}
+	}
+}
+
+func TestOpenRepository(t *testing.T) {
+	dir := newTestRepo(t)
+	g := NewGit(false, false)
+
+	for _, backend := range []string{"", BackendExec} {
+		repo, err := OpenRepository(backend, dir, g)
+		if err != nil || repo != Repository(g) {
+			t.Errorf("backend %q: expected the exec backend, got %T (%v)", backend, repo, err)
+		}
+	}
+	if repo, err := OpenRepository(BackendNative, dir, g); err != nil {
+		t.Errorf("native backend failed: %v", err)
+	} else if _, ok := repo.(*Native); !ok {
+		t.Errorf("expected *Native, got %T", repo)
+	}
+	if _, err := OpenRepository("libgit2", dir, g); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
+
+func TestOfflineRemoteBranchExists(t *testing.T) {
+	newTestRepo(t)
+	g := NewGit(false, false)
+
+	// The branch is on the remote, but was never fetched
+	if _, err := g.RunWithTimeout("update-ref", "-d", "refs/remotes/origin/stage"); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, err := g.RemoteBranchExists("origin", "stage"); err != nil || !exists {
+		t.Errorf("expected the remote to have stage, got %v (%v)", exists, err)
+	}
+	g.SetOffline(true)
+	if exists, err := g.RemoteBranchExists("origin", "stage"); err != nil || exists {
+		t.Errorf("expected no remote-tracking stage offline, got %v (%v)", exists, err)
+	}
+	if exists, err := g.RemoteBranchExists("origin", "feature/KWS-2-login"); err != nil || !exists {
+		t.Errorf("expected remote-tracking feature/KWS-2-login offline, got %v (%v)", exists, err)
+	}
+
+	last, err := g.GetLastFetch()
+	if err != nil || !last.IsZero() {
+		t.Errorf("expected no previous fetch, got %v (%v)", last, err)
+	}
+	if _, err := g.RunWithTimeout("fetch", "origin"); err != nil {
+		t.Fatal(err)
+	}
+	if last, err := g.GetLastFetch(); err != nil || last.IsZero() {
+		t.Errorf("expected the fetch time, got %v (%v)", last, err)
+	}
+}
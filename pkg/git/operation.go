@@ -0,0 +1,103 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Operations git can leave in progress when it stops on conflicts
+const (
+	OperationRebase     = "rebase"
+	OperationMerge      = "merge"
+	OperationCherryPick = "cherry-pick"
+	OperationRevert     = "revert"
+)
+
+// operationMarkers are the files git keeps in the git directory while an
+// operation is in progress
+var operationMarkers = []struct {
+	path      string
+	operation string
+}{
+	{"rebase-merge", OperationRebase},
+	{"rebase-apply", OperationRebase},
+	{"MERGE_HEAD", OperationMerge},
+	{"CHERRY_PICK_HEAD", OperationCherryPick},
+	{"REVERT_HEAD", OperationRevert},
+}
+
+// GetGitPath resolves a path inside the git directory of the current
+// worktree (e.g., MERGE_HEAD)
+func (g *Git) GetGitPath(name string) (string, error) {
+	output, err := g.RunWithTimeout("rev-parse", "--path-format=absolute", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// GetInProgressOperation returns the operation git has in progress, or an
+// empty string if there is none
+func (g *Git) GetInProgressOperation() (string, error) {
+	for _, marker := range operationMarkers {
+		path, err := g.GetGitPath(marker.path)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(path); err == nil {
+			return marker.operation, nil
+		}
+	}
+	return "", nil
+}
+
+// GetRebaseBranch returns the branch being rebased while HEAD is detached
+// by a rebase, or an empty string if it is unknown
+func (g *Git) GetRebaseBranch() (string, error) {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		path, err := g.GetGitPath(dir + "/head-name")
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return strings.TrimPrefix(strings.TrimSpace(string(data)), "refs/heads/"), nil
+		}
+	}
+	return "", nil
+}
+
+// GetConflictedFiles returns the files with unresolved conflicts
+func (g *Git) GetConflictedFiles() ([]string, error) {
+	output, err := g.RunWithTimeout("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ContinueOperation resumes an operation once conflicts are resolved,
+// keeping the commit messages git prepared
+func (g *Git) ContinueOperation(operation string) (string, error) {
+	switch operation {
+	case OperationRebase, OperationMerge, OperationCherryPick, OperationRevert:
+		return g.RunWithTimeout("-c", "core.editor=true", operation, "--continue")
+	}
+	return "", fmt.Errorf("unknown operation %q", operation)
+}
+
+// AbortOperation stops an operation and returns to the state before it started
+func (g *Git) AbortOperation(operation string) (string, error) {
+	switch operation {
+	case OperationRebase, OperationMerge, OperationCherryPick, OperationRevert:
+		return g.RunWithTimeout(operation, "--abort")
+	}
+	return "", fmt.Errorf("unknown operation %q", operation)
+}
@@ -1,8 +1,13 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
 // GetCurrentBranch returns the current branch name
@@ -56,8 +61,13 @@ func (g *Git) BranchExists(branch string) (bool, error) {
 	return strings.TrimSpace(output) != "", nil
 }
 
-// RemoteBranchExists checks if a remote branch exists
+// RemoteBranchExists asks the remote whether a branch exists. Offline, it
+// checks the remote-tracking branch instead. Prefer TrackingBranchExists
+// after a fetch, which needs no network round trip.
 func (g *Git) RemoteBranchExists(remote, branch string) (bool, error) {
+	if g.offline {
+		return g.TrackingBranchExists(remote, branch)
+	}
 	output, err := g.RunWithTimeout("ls-remote", "--heads", remote, branch)
 	if err != nil {
 		return false, err
@@ -65,6 +75,33 @@ func (g *Git) RemoteBranchExists(remote, branch string) (bool, error) {
 	return strings.TrimSpace(output) != "", nil
 }
 
+// TrackingBranchExists checks if the remote-tracking branch
+// refs/remotes/<remote>/<branch> exists, as of the last fetch
+func (g *Git) TrackingBranchExists(remote, branch string) (bool, error) {
+	tip, err := g.ResolveRef(fmt.Sprintf("refs/remotes/%s/%s", remote, branch))
+	if err != nil {
+		return false, err
+	}
+	return tip != "", nil
+}
+
+// GetLastFetch returns when the repository was last fetched, from the
+// modification time of FETCH_HEAD; zero if it was never fetched
+func (g *Git) GetLastFetch() (time.Time, error) {
+	path, err := g.GetGitPath("FETCH_HEAD")
+	if err != nil {
+		return time.Time{}, err
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
 // GetAheadBehind returns the ahead/behind counts for the current branch vs a remote branch
 func (g *Git) GetAheadBehind(remote, branch string) (ahead, behind int, err error) {
 	currentBranch, err := g.GetCurrentBranch()
@@ -96,29 +133,106 @@ func (g *Git) GetAheadBehind(remote, branch string) (ahead, behind int, err erro
 	return ahead, behind, nil
 }
 
-// GetCommitAuthors returns unique authors from recent commits (last N commits)
-func (g *Git) GetCommitAuthors(count int) ([]string, error) {
-	output, err := g.RunWithTimeout("log", "-n", fmt.Sprintf("%d", count), "--format=%an")
+// GetConfigValue returns a git config value, or an empty string if it is not set
+func (g *Git) GetConfigValue(key string) (string, error) {
+	output, err := g.RunWithTimeout("config", "--get", key)
 	if err != nil {
-		return nil, err
+		// git config exits with status 1 when the key is not set
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", err
 	}
+	return strings.TrimSpace(output), nil
+}
 
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	authorMap := make(map[string]bool)
-	var authors []string
+// ResolveRef returns the commit a ref points to, or an empty string if the
+// ref does not exist
+func (g *Git) ResolveRef(ref string) (string, error) {
+	output, err := g.RunWithTimeout("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		// rev-parse --verify --quiet exits with status 1 for missing refs
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+// GetSymbolicHEAD returns the branch HEAD points to, or an empty string
+// when HEAD is detached
+func (g *Git) GetSymbolicHEAD() (string, error) {
+	output, err := g.RunWithTimeout("symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		// symbolic-ref --quiet exits with status 1 for a detached HEAD
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
 		}
-		if !authorMap[line] {
-			authorMap[line] = true
-			authors = append(authors, line)
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// GetTreeHash returns the tree hash of HEAD
+func (g *Git) GetTreeHash() (string, error) {
+	output, err := g.RunWithTimeout("rev-parse", "HEAD^{tree}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// GetGitextDir returns the directory where gitext keeps repository state
+// (.git/gitext), shared by all worktrees
+func (g *Git) GetGitextDir() (string, error) {
+	output, err := g.RunWithTimeout("rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(strings.TrimSpace(output), "gitext"), nil
+}
+
+// GetRemoteURL returns the fetch URL of a remote
+func (g *Git) GetRemoteURL(remote string) (string, error) {
+	output, err := g.RunWithTimeout("remote", "get-url", remote)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// IsAncestor checks if commit is an ancestor of (or equal to) descendant
+func (g *Git) IsAncestor(commit, descendant string) (bool, error) {
+	if _, err := g.RunWithTimeout("merge-base", "--is-ancestor", commit, descendant); err != nil {
+		// merge-base exits with status 1 when commit is not an ancestor
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
 		}
+		return false, err
 	}
+	return true, nil
+}
 
-	return authors, nil
+// IsPatchMerged reports whether every commit of head that upstream lacks
+// has an equivalent change in upstream, as git cherry compares them. This
+// holds after a rebase merge, or a squash merge of a single commit, which
+// IsAncestor does not see.
+func (g *Git) IsPatchMerged(head, upstream string) (bool, error) {
+	output, err := g.RunWithTimeout("cherry", upstream, head)
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "+") {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 // GetMergedBranches returns local branches that are merged into the given branch
@@ -179,3 +293,48 @@ func parseInt(s string) (int, error) {
 	return n, err
 }
 
+// Commit is a commit as listed by GetCommits
+type Commit struct {
+	SHA     string
+	Parents []string
+	Subject string
+	Body    string
+}
+
+// IsMerge reports whether the commit has more than one parent
+func (c Commit) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// GetCommits returns the commits selected by the given git log arguments
+// (e.g., a revision range such as origin/production..origin/stage)
+func (g *Git) GetCommits(args ...string) ([]Commit, error) {
+	logArgs := append([]string{"log", "--format=%H%x1f%P%x1f%s%x1f%b%x1e"}, args...)
+	output, err := g.RunWithTimeout(logArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x1f", 4)
+		if len(fields) < 3 {
+			continue
+		}
+		commit := Commit{
+			SHA:     fields[0],
+			Parents: strings.Fields(fields[1]),
+			Subject: fields[2],
+		}
+		if len(fields) == 4 {
+			commit.Body = strings.TrimSpace(fields[3])
+		}
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
@@ -0,0 +1,170 @@
+package git
+
+import (
+	"sort"
+	"strings"
+)
+
+// CommitIdentity is who authored and who committed a commit
+type CommitIdentity struct {
+	Hash           string
+	AuthorName     string
+	AuthorEmail    string
+	CommitterName  string
+	CommitterEmail string
+}
+
+// RefUpdate is an entry of a ref's reflog
+type RefUpdate struct {
+	Hash           string // the value the ref was set to
+	Message        string // e.g., "update by push" or "fetch: fast-forward"
+	CommitterName  string // committer of the commit the ref was set to
+	CommitterEmail string
+}
+
+// IsPush reports whether the update was made by pushing from this repository
+func (u RefUpdate) IsPush() bool {
+	return strings.HasPrefix(u.Message, "update by push")
+}
+
+// Contributor is someone other than the local user who worked on a branch
+type Contributor struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Commits int    `json:"commits"` // commits on the branch they authored or committed
+	Pushes  int    `json:"pushes"`  // updates of the remote branch fetched rather than pushed from here
+}
+
+// Ownership is who, besides the local user, worked on a branch
+type Ownership struct {
+	Contributors []Contributor `json:"contributors"`
+}
+
+// Shared reports whether anyone else worked on the branch
+func (o *Ownership) Shared() bool {
+	return len(o.Contributors) > 0
+}
+
+// AnalyzeOwnership finds who other than the user (identified by any of
+// self's emails) contributed to a branch: through the commits unique to
+// the branch, and through updates of its remote-tracking branch that were
+// fetched instead of pushed from this repository
+func AnalyzeOwnership(self []string, commits []CommitIdentity, updates []RefUpdate) *Ownership {
+	isSelf := make(map[string]bool)
+	for _, email := range self {
+		if email != "" {
+			isSelf[strings.ToLower(email)] = true
+		}
+	}
+
+	contributors := make(map[string]*Contributor)
+	contributor := func(name, email string) *Contributor {
+		key := strings.ToLower(email)
+		if isSelf[key] {
+			return nil
+		}
+		if c, ok := contributors[key]; ok {
+			return c
+		}
+		c := &Contributor{Name: name, Email: email}
+		contributors[key] = c
+		return c
+	}
+
+	for _, commit := range commits {
+		author := contributor(commit.AuthorName, commit.AuthorEmail)
+		if author != nil {
+			author.Commits++
+		}
+		// A commit rewritten by someone else (e.g., rebased) counts for them too
+		if committer := contributor(commit.CommitterName, commit.CommitterEmail); committer != nil && committer != author {
+			committer.Commits++
+		}
+	}
+	for _, update := range updates {
+		if update.IsPush() {
+			continue
+		}
+		if pusher := contributor(update.CommitterName, update.CommitterEmail); pusher != nil {
+			pusher.Pushes++
+		}
+	}
+
+	ownership := &Ownership{Contributors: []Contributor{}}
+	for _, c := range contributors {
+		ownership.Contributors = append(ownership.Contributors, *c)
+	}
+	sort.Slice(ownership.Contributors, func(i, j int) bool {
+		return ownership.Contributors[i].Email < ownership.Contributors[j].Email
+	})
+	return ownership
+}
+
+// GetUserEmails returns the emails git records for the local user as author
+// and as committer, honoring user.email and the GIT_*_EMAIL variables
+func (g *Git) GetUserEmails() ([]string, error) {
+	var emails []string
+	for _, variable := range []string{"GIT_AUTHOR_IDENT", "GIT_COMMITTER_IDENT"} {
+		ident, err := g.RunWithTimeout("var", variable)
+		if err != nil {
+			return nil, err
+		}
+		// Name <email> timestamp timezone
+		if start, end := strings.Index(ident, "<"), strings.Index(ident, ">"); start >= 0 && end > start {
+			emails = append(emails, ident[start+1:end])
+		}
+	}
+	return emails, nil
+}
+
+// GetUniqueCommits returns the commits reachable from HEAD but not from base
+func (g *Git) GetUniqueCommits(base string) ([]CommitIdentity, error) {
+	output, err := g.RunWithTimeout("log", "--format=%H%x1f%an%x1f%ae%x1f%cn%x1f%ce", base+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []CommitIdentity
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		commits = append(commits, CommitIdentity{
+			Hash:           fields[0],
+			AuthorName:     fields[1],
+			AuthorEmail:    fields[2],
+			CommitterName:  fields[3],
+			CommitterEmail: fields[4],
+		})
+	}
+	return commits, nil
+}
+
+// GetRefLog returns the reflog of ref, newest first; empty when the ref
+// does not exist or has no reflog
+func (g *Git) GetRefLog(ref string) ([]RefUpdate, error) {
+	if tip, err := g.ResolveRef(ref); err != nil || tip == "" {
+		return nil, err
+	}
+
+	output, err := g.RunWithTimeout("log", "--walk-reflogs", "--format=%H%x1f%gs%x1f%cn%x1f%ce", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []RefUpdate
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		updates = append(updates, RefUpdate{
+			Hash:           fields[0],
+			Message:        fields[1],
+			CommitterName:  fields[2],
+			CommitterEmail: fields[3],
+		})
+	}
+	return updates, nil
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeOwnership(t *testing.T) {
+	self := []string{"me@example.com", ""}
+	commits := []CommitIdentity{
+		{AuthorName: "Me", AuthorEmail: "me@example.com", CommitterName: "Me", CommitterEmail: "ME@example.com"},
+		{AuthorName: "Bob", AuthorEmail: "bob@example.com", CommitterName: "Bob", CommitterEmail: "bob@example.com"},
+		{AuthorName: "Me", AuthorEmail: "me@example.com", CommitterName: "Carol", CommitterEmail: "carol@example.com"},
+	}
+	updates := []RefUpdate{
+		{Message: "update by push", CommitterName: "Me", CommitterEmail: "me@example.com"},
+		{Message: "fetch: fast-forward", CommitterName: "Bob", CommitterEmail: "bob@example.com"},
+		{Message: "fetch origin: forced-update", CommitterName: "Dave", CommitterEmail: "dave@example.com"},
+		{Message: "update by push", CommitterName: "Erin", CommitterEmail: "erin@example.com"},
+	}
+
+	got := AnalyzeOwnership(self, commits, updates)
+	want := []Contributor{
+		{Name: "Bob", Email: "bob@example.com", Commits: 1, Pushes: 1},
+		{Name: "Carol", Email: "carol@example.com", Commits: 1},
+		{Name: "Dave", Email: "dave@example.com", Pushes: 1},
+	}
+	if !got.Shared() || !reflect.DeepEqual(got.Contributors, want) {
+		t.Errorf("unexpected contributors:\n got %+v\nwant %+v", got.Contributors, want)
+	}
+
+	if own := AnalyzeOwnership(self, commits[:1], updates[:1]); own.Shared() {
+		t.Errorf("expected a branch only the user worked on not to be shared, got %+v", own.Contributors)
+	}
+}
+
+func TestGetRefLog(t *testing.T) {
+	newTestRepo(t)
+	g := NewGit(false, false)
+
+	updates, err := g.GetRefLog("refs/remotes/origin/feature/KWS-2-login")
+	if err != nil {
+		t.Fatalf("GetRefLog failed: %v", err)
+	}
+	if len(updates) != 1 || !updates[0].IsPush() {
+		t.Errorf("expected a single push, got %+v", updates)
+	}
+
+	if updates, err := g.GetRefLog("refs/remotes/origin/missing"); err != nil || len(updates) != 0 {
+		t.Errorf("expected no updates for a missing ref, got %+v (%v)", updates, err)
+	}
+}
@@ -0,0 +1,48 @@
+package git
+
+import (
+	"fmt"
+)
+
+// Backends that can answer Repository queries
+const (
+	BackendExec   = "exec"   // shells out to git (default)
+	BackendNative = "native" // reads the repository in-process with go-git
+)
+
+// Repository answers the read-only queries commands make about a repository.
+// Git implements it by running git; Native reads the repository in-process;
+// FakeRepository serves canned answers to tests.
+type Repository interface {
+	GetCurrentBranch() (string, error)
+	IsWorkingTreeClean() (bool, error)
+	GetAheadBehind(remote, branch string) (ahead, behind int, err error)
+	GetMergedBranches(intoBranch string) ([]string, error)
+	GetStagedDiff() (string, error)
+	HasStagedChanges() (bool, error)
+	GetUniqueCommits(base string) ([]CommitIdentity, error)
+	BranchExists(branch string) (bool, error)
+	RemoteBranchExists(remote, branch string) (bool, error)
+	TrackingBranchExists(remote, branch string) (bool, error)
+	ListWorktrees() ([]Worktree, error)
+	ValidateRemote(remote string) error
+	GetInProgressOperation() (string, error)
+}
+
+var (
+	_ Repository = (*Git)(nil)
+	_ Repository = (*Native)(nil)
+	_ Repository = (*FakeRepository)(nil)
+)
+
+// OpenRepository returns the Repository for the configured backend, rooted
+// at dir. g runs the queries the native backend cannot answer itself.
+func OpenRepository(backend, dir string, g *Git) (Repository, error) {
+	switch backend {
+	case "", BackendExec:
+		return g, nil
+	case BackendNative:
+		return OpenNative(dir, g)
+	}
+	return nil, fmt.Errorf("unknown git backend %q", backend)
+}
@@ -0,0 +1,33 @@
+package git
+
+import (
+	"strings"
+)
+
+// Stash stashes uncommitted changes, including untracked files, under
+// message and returns the stash commit. In dry-run mode nothing is stashed
+// and stash@{0} stands in for the commit.
+func (g *Git) Stash(message string) (string, error) {
+	if _, err := g.RunWithTimeout("stash", "push", "--include-untracked", "-m", message); err != nil {
+		return "", err
+	}
+	if g.dryRun {
+		return "stash@{0}", nil
+	}
+	return g.ResolveRef("refs/stash")
+}
+
+// GetStashRef returns the stash@{n} entry holding a stash commit, or an
+// empty string once it has been dropped
+func (g *Git) GetStashRef(commit string) (string, error) {
+	output, err := g.RunWithTimeout("stash", "list", "--format=%gd %H")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if ref, hash, ok := strings.Cut(strings.TrimSpace(line), " "); ok && hash == commit {
+			return ref, nil
+		}
+	}
+	return "", nil
+}
@@ -14,15 +14,24 @@ func (g *Git) ValidateGitRepo() error {
 func (g *Git) ValidateRemote(remote string) error {
 	output, err := g.RunWithTimeout("remote", "get-url", remote)
 	if err != nil {
-		return fmt.Errorf("remote '%s' does not exist → run 'git remote add %s <url>'", remote, remote)
+		return errRemoteNotFound(remote)
 	}
 	if output == "" {
-		return fmt.Errorf("remote '%s' has no URL configured", remote)
+		return errRemoteNoURL(remote)
 	}
 	return nil
 }
 
-// ValidateBranchExists checks if a branch exists (local or remote)
+func errRemoteNotFound(remote string) error {
+	return fmt.Errorf("remote '%s' does not exist → run 'git remote add %s <url>'", remote, remote)
+}
+
+func errRemoteNoURL(remote string) error {
+	return fmt.Errorf("remote '%s' has no URL configured", remote)
+}
+
+// ValidateBranchExists checks if a branch exists locally or as a
+// remote-tracking branch, as of the last fetch
 func (g *Git) ValidateBranchExists(branch, remote string) error {
 	// Check local first
 	exists, err := g.BranchExists(branch)
@@ -33,8 +42,8 @@ func (g *Git) ValidateBranchExists(branch, remote string) error {
 		return nil
 	}
 
-	// Check remote
-	exists, err = g.RemoteBranchExists(remote, branch)
+	// Check the remote-tracking branch
+	exists, err = g.TrackingBranchExists(remote, branch)
 	if err != nil {
 		return err
 	}
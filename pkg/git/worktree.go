@@ -0,0 +1,72 @@
+package git
+
+import (
+	"strings"
+)
+
+// Worktree is a working tree attached to the repository
+type Worktree struct {
+	Path     string `json:"path"`
+	HEAD     string `json:"head"`
+	Branch   string `json:"branch,omitempty"` // empty when detached
+	Main     bool   `json:"main"`             // the main worktree, which cannot be removed
+	Bare     bool   `json:"bare,omitempty"`
+	Locked   bool   `json:"locked,omitempty"`
+	Prunable bool   `json:"prunable,omitempty"` // its directory is gone; git worktree prune removes it
+}
+
+// ListWorktrees returns the worktrees of the repository, the main one first
+func (g *Git) ListWorktrees() ([]Worktree, error) {
+	output, err := g.RunWithTimeout("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	return parseWorktrees(output), nil
+}
+
+// parseWorktrees parses the output of git worktree list --porcelain: one
+// block of attribute lines per worktree, separated by blank lines
+func parseWorktrees(output string) []Worktree {
+	var worktrees []Worktree
+	var current *Worktree
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "worktree":
+			worktrees = append(worktrees, Worktree{Path: value, Main: len(worktrees) == 0})
+			current = &worktrees[len(worktrees)-1]
+		case "HEAD":
+			if current != nil {
+				current.HEAD = value
+			}
+		case "branch":
+			if current != nil {
+				current.Branch = strings.TrimPrefix(value, "refs/heads/")
+			}
+		case "bare":
+			if current != nil {
+				current.Bare = true
+			}
+		case "locked":
+			if current != nil {
+				current.Locked = true
+			}
+		case "prunable":
+			if current != nil {
+				current.Prunable = true
+			}
+		}
+	}
+	return worktrees
+}
+
+// WorktreeForBranch returns the worktree that has branch checked out, or nil
+func WorktreeForBranch(worktrees []Worktree, branch string) *Worktree {
+	for i := range worktrees {
+		if worktrees[i].Branch == branch {
+			return &worktrees[i]
+		}
+	}
+	return nil
+}
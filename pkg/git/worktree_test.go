@@ -0,0 +1,39 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWorktrees(t *testing.T) {
+	output := `worktree /src/app
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/stage
+
+worktree /src/app.worktrees/feature-KWS-1-login
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/feature/KWS-1-login
+locked
+
+worktree /tmp/gone
+HEAD 3333333333333333333333333333333333333333
+detached
+prunable gitdir file points to non-existent location
+`
+	want := []Worktree{
+		{Path: "/src/app", HEAD: "1111111111111111111111111111111111111111", Branch: "stage", Main: true},
+		{Path: "/src/app.worktrees/feature-KWS-1-login", HEAD: "2222222222222222222222222222222222222222", Branch: "feature/KWS-1-login", Locked: true},
+		{Path: "/tmp/gone", HEAD: "3333333333333333333333333333333333333333", Prunable: true},
+	}
+	got := parseWorktrees(output)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWorktrees() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if wt := WorktreeForBranch(got, "feature/KWS-1-login"); wt == nil || wt.Path != "/src/app.worktrees/feature-KWS-1-login" {
+		t.Errorf("WorktreeForBranch() = %+v", wt)
+	}
+	if wt := WorktreeForBranch(got, "feature/KWS-9-none"); wt != nil {
+		t.Errorf("WorktreeForBranch() = %+v, want nil", wt)
+	}
+}
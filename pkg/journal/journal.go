@@ -0,0 +1,181 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when no journal entry matches
+var ErrNotFound = errors.New("journal entry not found")
+
+// Entry records the repository state before a command rewrote refs, so the
+// command can be undone
+type Entry struct {
+	ID      string    `json:"id"`
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+
+	// HEAD is the commit HEAD pointed to, and Branch the branch that was
+	// checked out (empty when HEAD was detached)
+	HEAD   string `json:"head"`
+	Branch string `json:"branch,omitempty"`
+
+	// Refs maps each branch the command may move to its previous tip; an
+	// empty tip means the branch did not exist
+	Refs map[string]string `json:"refs,omitempty"`
+
+	// Deleted maps each branch the command deleted to its last tip
+	Deleted map[string]string `json:"deleted,omitempty"`
+
+	// Stash is the commit of the uncommitted changes the command stashed
+	// with --autostash; it stays in git stash list until reapplied
+	Stash string `json:"stash,omitempty"`
+
+	// Undoes is set on entries recorded by undo itself, and UndoneBy on
+	// entries that have been undone
+	Undoes   string `json:"undoes,omitempty"`
+	UndoneBy string `json:"undoneBy,omitempty"`
+}
+
+// Undone reports whether the entry has been undone
+func (e *Entry) Undone() bool {
+	return e.UndoneBy != ""
+}
+
+// Journal stores entries as JSON files in a directory (e.g., .git/gitext/journal)
+type Journal struct {
+	dir string
+}
+
+// New creates a journal stored in dir
+func New(dir string) *Journal {
+	return &Journal{dir: dir}
+}
+
+// Record assigns the entry an ID and stores it
+func (j *Journal) Record(entry *Entry) error {
+	if err := os.MkdirAll(j.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create journal: %w", err)
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	// IDs sort by time; commands run within the same second get a suffix
+	base := entry.Time.Format("20060102-150405")
+	for i := 0; ; i++ {
+		id := base
+		if i > 0 {
+			id = fmt.Sprintf("%s-%d", base, i)
+		}
+		file, err := os.OpenFile(j.path(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write journal entry: %w", err)
+		}
+		file.Close()
+		entry.ID = id
+		return j.Save(entry)
+	}
+}
+
+// Save overwrites a recorded entry
+func (j *Journal) Save(entry *Entry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	if err := os.WriteFile(j.path(entry.ID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes a recorded entry
+func (j *Journal) Remove(id string) error {
+	if err := os.Remove(j.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove journal entry: %w", err)
+	}
+	return nil
+}
+
+// List returns all entries, newest first
+func (j *Journal) List() ([]*Entry, error) {
+	files, err := os.ReadDir(j.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var entries []*Entry
+	for _, file := range files {
+		id, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok || file.IsDir() {
+			continue
+		}
+		entry, err := j.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(a, b int) bool {
+		if !entries[a].Time.Equal(entries[b].Time) {
+			return entries[a].Time.After(entries[b].Time)
+		}
+		return entries[a].ID > entries[b].ID
+	})
+	return entries, nil
+}
+
+// Get returns the entry with the given ID
+func (j *Journal) Get(id string) (*Entry, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+
+	data, err := os.ReadFile(j.path(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse journal entry %s: %w", id, err)
+	}
+	entry.ID = id
+	return &entry, nil
+}
+
+// Latest returns the newest entry that has not been undone, skipping entries
+// recorded by undo itself
+func (j *Journal) Latest() (*Entry, error) {
+	entries, err := j.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.Undone() && entry.Undoes == "" {
+			return entry, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (j *Journal) path(id string) string {
+	return filepath.Join(j.dir, id+".json")
+}
@@ -0,0 +1,98 @@
+package journal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecordAndList(t *testing.T) {
+	j := New(t.TempDir())
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	first := &Entry{Command: "gitext update feature", Time: now, HEAD: "aaa", Branch: "feature/KWS-1", Refs: map[string]string{"feature/KWS-1": "aaa"}}
+	second := &Entry{Command: "gitext cleanup --hard", Time: now, HEAD: "bbb", Deleted: map[string]string{"old": "ccc"}}
+	for _, entry := range []*Entry{first, second} {
+		if err := j.Record(entry); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	if first.ID != "20240501-120000" || second.ID != "20240501-120000-1" {
+		t.Errorf("unexpected IDs %q, %q", first.ID, second.ID)
+	}
+
+	entries, err := j.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != second.ID || entries[1].ID != first.ID {
+		t.Fatalf("expected newest first, got %+v", entries)
+	}
+	if entries[0].Deleted["old"] != "ccc" || entries[1].Refs["feature/KWS-1"] != "aaa" {
+		t.Errorf("entries not round-tripped: %+v", entries)
+	}
+}
+
+func TestLatestSkipsUndoneAndUndoEntries(t *testing.T) {
+	j := New(t.TempDir())
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	older := &Entry{Command: "gitext sync stage", Time: now}
+	newer := &Entry{Command: "gitext retarget feature", Time: now.Add(time.Minute)}
+	for _, entry := range []*Entry{older, newer} {
+		if err := j.Record(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latest, err := j.Latest()
+	if err != nil || latest.ID != newer.ID {
+		t.Fatalf("Latest() = %v, %v; want %s", latest, err, newer.ID)
+	}
+
+	undo := &Entry{Command: "gitext undo", Time: now.Add(2 * time.Minute), Undoes: newer.ID}
+	if err := j.Record(undo); err != nil {
+		t.Fatal(err)
+	}
+	newer.UndoneBy = undo.ID
+	if err := j.Save(newer); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err = j.Latest()
+	if err != nil || latest.ID != older.ID {
+		t.Fatalf("Latest() = %v, %v; want %s", latest, err, older.ID)
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	j := New(t.TempDir())
+	if _, err := j.Get("20240501-120000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	if _, err := j.Get("../escape"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	if _, err := j.Latest(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Latest() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	j := New(t.TempDir())
+	entry := &Entry{Command: "gitext cleanup --hard", HEAD: "aaa"}
+	if err := j.Record(entry); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	if err := j.Remove(entry.ID); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := j.Get(entry.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	if err := j.Remove(entry.ID); err != nil {
+		t.Errorf("Remove() of a missing entry error = %v", err)
+	}
+}
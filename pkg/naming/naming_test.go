@@ -0,0 +1,104 @@
+package naming
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderAndExtract(t *testing.T) {
+	tests := []struct {
+		template string
+		values   Values
+		expected string
+	}{
+		{"{type}/{ticket}-{slug}", Values{Type: "feature", Ticket: "KWS-123", Slug: "retry-policy"}, "feature/KWS-123-retry-policy"},
+		{"feat/{user}/{ticket}/{slug}", Values{Ticket: "KWS-7", Slug: "login", Author: "jane-doe"}, "feat/jane-doe/KWS-7/login"},
+		{"{type}/{ticket}_{slug}", Values{Type: "hotfix", Ticket: "123", Slug: "crash"}, "hotfix/123_crash"},
+		{"{type}/{date}-{slug}", Values{Type: "feature", Slug: "x", Date: time.Date(2024, 3, 9, 15, 0, 0, 0, time.UTC)}, "feature/20240309-x"},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := Parse(tt.template)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.template, err)
+		}
+
+		name, err := tmpl.Render(tt.values)
+		if err != nil {
+			t.Fatalf("Render(%q) failed: %v", tt.template, err)
+		}
+		if name != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, name)
+		}
+
+		values, ok := tmpl.Extract(name)
+		if !ok {
+			t.Fatalf("Extract(%q) did not match template %q", name, tt.template)
+		}
+		if values.Ticket != tt.values.Ticket || values.Slug != tt.values.Slug || values.Author != tt.values.Author {
+			t.Errorf("Extract(%q) = %+v, expected %+v", name, values, tt.values)
+		}
+	}
+}
+
+func TestRenderRejectsAmbiguousNames(t *testing.T) {
+	tmpl, err := Parse("{type}/{ticket}-{slug}")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// "ABC" + "123-fix" would parse back as ticket "ABC-123"
+	if _, err := tmpl.Render(Values{Type: "feature", Ticket: "ABC", Slug: "123-fix"}); err == nil {
+		t.Error("Expected error for ambiguous branch name")
+	}
+
+	if _, err := tmpl.Render(Values{Type: "feature", Ticket: "KWS 1", Slug: "x"}); err == nil {
+		t.Error("Expected error for invalid ticket")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tmpl := range []string{"", "{type}/{unknown}", "{slug}-{slug}", "{type}/{ticket"} {
+		if _, err := Parse(tmpl); err == nil {
+			t.Errorf("Expected error for template %q", tmpl)
+		}
+	}
+}
+
+func TestGlob(t *testing.T) {
+	tmpl, _ := Parse("feat/{user}/{ticket}/{slug}")
+	if glob := tmpl.Glob("feature"); glob != "feat/*/*/*" {
+		t.Errorf("Expected feat/*/*/*, got %s", glob)
+	}
+
+	tmpl, _ = Parse("{type}/{ticket}{slug}")
+	if glob := tmpl.Glob("hotfix"); glob != "hotfix/*" {
+		t.Errorf("Expected hotfix/*, got %s", glob)
+	}
+}
+
+func TestNormalizeSlug(t *testing.T) {
+	opts := SlugOptions{MaxLength: 20, Lowercase: true, Transliterate: true}
+
+	tests := map[string]string{
+		"Retry Policy":                      "retry-policy",
+		"  --Fix: über  Straße!  ":          "fix-uber-strasse",
+		"Ёлка и Café":                       "elka-i-cafe",
+		"a very long slug that goes on and": "a-very-long-slug-tha",
+		"word-boundary-at-twenty-chars":     "word-boundary-at-twe",
+		"ends-with-dash-at-limit-x":         "ends-with-dash-at-li",
+	}
+
+	for input, expected := range tests {
+		if got := NormalizeSlug(input, opts); got != expected {
+			t.Errorf("NormalizeSlug(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+
+	if got := NormalizeSlug("Café Bar", SlugOptions{}); got != "Café-Bar" {
+		t.Errorf("Expected Café-Bar without transliteration, got %q", got)
+	}
+	if got := NormalizeSlug("abcdefghi-jk", SlugOptions{MaxLength: 10}); got != "abcdefghi" {
+		t.Errorf("Expected trailing dash to be trimmed, got %q", got)
+	}
+}
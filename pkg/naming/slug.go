@@ -0,0 +1,101 @@
+package naming
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SlugOptions controls how free-form text is turned into a branch slug
+type SlugOptions struct {
+	MaxLength     int  // 0 means no limit
+	Lowercase     bool // convert to lowercase
+	Transliterate bool // replace non-ASCII letters with ASCII equivalents
+}
+
+// transliterations maps common non-ASCII letters to ASCII
+var transliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'č': "c", 'ĉ': "c", 'ċ': "c",
+	'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ĝ': "g", 'ġ': "g", 'ģ': "g",
+	'ĥ': "h", 'ħ': "h",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ĵ': "j", 'ķ': "k",
+	'ł': "l", 'ľ': "l", 'ĺ': "l", 'ļ': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n", 'ņ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ŕ': "r", 'ř': "r", 'ŗ': "r",
+	'ś': "s", 'š': "s", 'ş': "s", 'ŝ': "s", 'ș': "s",
+	'ť': "t", 'ţ': "t", 'ț': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// transliterate converts a single rune to ASCII, returning "" if it has no equivalent
+func transliterate(r rune) string {
+	if r < unicode.MaxASCII {
+		return string(r)
+	}
+	lower := unicode.ToLower(r)
+	ascii, ok := transliterations[lower]
+	if !ok {
+		return ""
+	}
+	if lower != r {
+		return strings.ToUpper(ascii)
+	}
+	return ascii
+}
+
+// NormalizeSlug turns free-form text into a branch-safe slug: runs of anything
+// other than letters and digits become a single dash
+func NormalizeSlug(s string, opts SlugOptions) string {
+	var b strings.Builder
+	pendingDash := false
+
+	write := func(text string) {
+		for _, r := range text {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				if pendingDash && b.Len() > 0 {
+					b.WriteRune('-')
+				}
+				pendingDash = false
+				b.WriteRune(r)
+			} else {
+				pendingDash = true
+			}
+		}
+	}
+
+	for _, r := range s {
+		if opts.Transliterate && r >= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if ascii := transliterate(r); ascii != "" {
+				write(ascii)
+			}
+			continue
+		}
+		write(string(r))
+	}
+
+	slug := b.String()
+	if opts.Lowercase {
+		slug = strings.ToLower(slug)
+	}
+
+	if opts.MaxLength > 0 {
+		runes := []rune(slug)
+		if len(runes) > opts.MaxLength {
+			slug = strings.TrimRight(string(runes[:opts.MaxLength]), "-")
+		}
+	}
+
+	return slug
+}
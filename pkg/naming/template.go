@@ -0,0 +1,248 @@
+package naming
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Supported placeholders
+const (
+	PlaceholderType   = "type"
+	PlaceholderTicket = "ticket"
+	PlaceholderSlug   = "slug"
+	PlaceholderAuthor = "author"
+	PlaceholderDate   = "date"
+)
+
+// DateFormat is the layout used to render the {date} placeholder
+const DateFormat = "20060102"
+
+// TicketPattern matches ticket IDs such as KWS-123, ABC123 or 123
+const TicketPattern = `[A-Za-z0-9]+(?:-[0-9]+)?`
+
+// placeholderPatterns are the regular expressions each placeholder must match.
+// They are used both to validate rendered values and to parse branch names back.
+var placeholderPatterns = map[string]string{
+	PlaceholderType:   `[a-z]+`,
+	PlaceholderTicket: TicketPattern,
+	PlaceholderSlug:   `[\p{L}\p{N}]+(?:-[\p{L}\p{N}]+)*`,
+	PlaceholderAuthor: `[\p{L}\p{N}]+(?:-[\p{L}\p{N}]+)*`,
+	PlaceholderDate:   `[0-9]{8}`,
+}
+
+// placeholderAliases maps alternative placeholder names to their canonical name
+var placeholderAliases = map[string]string{
+	"user": PlaceholderAuthor,
+}
+
+var placeholderRegex = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// Values holds the placeholder values of a branch name
+type Values struct {
+	Type   string
+	Ticket string
+	Slug   string
+	Author string
+	Date   time.Time
+}
+
+func (v Values) get(name string) string {
+	switch name {
+	case PlaceholderType:
+		return v.Type
+	case PlaceholderTicket:
+		return v.Ticket
+	case PlaceholderSlug:
+		return v.Slug
+	case PlaceholderAuthor:
+		return v.Author
+	case PlaceholderDate:
+		if v.Date.IsZero() {
+			return ""
+		}
+		return v.Date.Format(DateFormat)
+	}
+	return ""
+}
+
+func (v *Values) set(name, value string) {
+	switch name {
+	case PlaceholderType:
+		v.Type = value
+	case PlaceholderTicket:
+		v.Ticket = value
+	case PlaceholderSlug:
+		v.Slug = value
+	case PlaceholderAuthor:
+		v.Author = value
+	case PlaceholderDate:
+		if t, err := time.Parse(DateFormat, value); err == nil {
+			v.Date = t
+		}
+	}
+}
+
+// segment is either a literal string or a placeholder
+type segment struct {
+	literal     string
+	placeholder string
+}
+
+// Template is a parsed branch-name template such as "{type}/{ticket}-{slug}"
+type Template struct {
+	raw      string
+	segments []segment
+	regex    *regexp.Regexp
+}
+
+// Parse parses a branch-name template
+func Parse(tmpl string) (*Template, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		return nil, fmt.Errorf("branch template cannot be empty")
+	}
+
+	t := &Template{raw: tmpl}
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	seen := make(map[string]bool)
+	last := 0
+	for _, loc := range placeholderRegex.FindAllStringSubmatchIndex(tmpl, -1) {
+		if loc[0] > last {
+			literal := tmpl[last:loc[0]]
+			t.segments = append(t.segments, segment{literal: literal})
+			expr.WriteString(regexp.QuoteMeta(literal))
+		}
+
+		name := tmpl[loc[2]:loc[3]]
+		if alias, ok := placeholderAliases[name]; ok {
+			name = alias
+		}
+		pattern, ok := placeholderPatterns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown placeholder {%s} in branch template '%s'", name, tmpl)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("placeholder {%s} is used more than once in branch template '%s'", name, tmpl)
+		}
+		seen[name] = true
+
+		t.segments = append(t.segments, segment{placeholder: name})
+		expr.WriteString(fmt.Sprintf("(?P<%s>%s)", name, pattern))
+		last = loc[1]
+	}
+	if last < len(tmpl) {
+		literal := tmpl[last:]
+		t.segments = append(t.segments, segment{literal: literal})
+		expr.WriteString(regexp.QuoteMeta(literal))
+	}
+	if strings.Count(tmpl, "{") != len(seen) || strings.Count(tmpl, "}") != len(seen) {
+		return nil, fmt.Errorf("malformed placeholder in branch template '%s'", tmpl)
+	}
+
+	expr.WriteString("$")
+	regex, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid branch template '%s': %w", tmpl, err)
+	}
+	t.regex = regex
+
+	return t, nil
+}
+
+// String returns the template source
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Uses reports whether the template contains the given placeholder
+func (t *Template) Uses(placeholder string) bool {
+	for _, seg := range t.segments {
+		if seg.placeholder == placeholder {
+			return true
+		}
+	}
+	return false
+}
+
+// Render builds a branch name from values. It fails if a value is missing or
+// if the resulting name would not parse back to the same values.
+func (t *Template) Render(v Values) (string, error) {
+	var name strings.Builder
+	for _, seg := range t.segments {
+		if seg.placeholder == "" {
+			name.WriteString(seg.literal)
+			continue
+		}
+
+		value := v.get(seg.placeholder)
+		if value == "" {
+			return "", fmt.Errorf("branch template '%s' requires a value for {%s}", t.raw, seg.placeholder)
+		}
+		pattern := regexp.MustCompile("^(?:" + placeholderPatterns[seg.placeholder] + ")$")
+		if !pattern.MatchString(value) {
+			return "", fmt.Errorf("invalid %s '%s' for branch template '%s'", seg.placeholder, value, t.raw)
+		}
+		name.WriteString(value)
+	}
+
+	// Parsing must be the exact inverse of generation
+	result := name.String()
+	parsed, ok := t.Extract(result)
+	if !ok {
+		return "", fmt.Errorf("branch name '%s' does not match template '%s'", result, t.raw)
+	}
+	for _, seg := range t.segments {
+		if seg.placeholder != "" && parsed.get(seg.placeholder) != v.get(seg.placeholder) {
+			return "", fmt.Errorf("branch name '%s' is ambiguous for template '%s' ({%s} would parse as '%s')",
+				result, t.raw, seg.placeholder, parsed.get(seg.placeholder))
+		}
+	}
+
+	return result, nil
+}
+
+// Extract parses a branch name generated by this template back into values
+func (t *Template) Extract(branch string) (Values, bool) {
+	var v Values
+	match := t.regex.FindStringSubmatch(branch)
+	if match == nil {
+		return v, false
+	}
+	for i, name := range t.regex.SubexpNames() {
+		if name != "" {
+			v.set(name, match[i])
+		}
+	}
+	return v, true
+}
+
+// Glob returns a wildcard pattern matching every branch this template can
+// produce for the given branch type (e.g., "feat/*/*" for "feat/{ticket}/{slug}")
+func (t *Template) Glob(branchType string) string {
+	var glob strings.Builder
+	for _, seg := range t.segments {
+		switch {
+		case seg.placeholder == "":
+			glob.WriteString(seg.literal)
+		case seg.placeholder == PlaceholderType && branchType != "":
+			glob.WriteString(branchType)
+		default:
+			// Collapse adjacent wildcards
+			if !strings.HasSuffix(glob.String(), "*") {
+				glob.WriteString("*")
+			}
+		}
+	}
+	return glob.String()
+}
+
+// ticketKeyRegex finds issue-tracker keys (e.g., KWS-123) in free text
+var ticketKeyRegex = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[0-9]+\b`)
+
+// FindTicket returns the first issue-tracker key in text, or an empty string
+func FindTicket(text string) string {
+	return ticketKeyRegex.FindString(text)
+}
@@ -0,0 +1,87 @@
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State describes a gitext command that stopped on conflicts, so gitext
+// continue can finish it and gitext abort can roll it back
+type State struct {
+	Command   string    `json:"command"`
+	Operation string    `json:"operation"` // rebase, merge or cherry-pick
+	Branch    string    `json:"branch"`
+	Target    string    `json:"target"` // ref rebased onto, merged or picked onto
+	Remote    string    `json:"remote"`
+	StartedAt time.Time `json:"startedAt"`
+
+	// Summary is reported once the operation completes, e.g. "Rebased onto origin/stage"
+	Summary string `json:"summary"`
+
+	// ForcePush is set when the command rewrites a branch that was already pushed
+	ForcePush bool `json:"forcePush,omitempty"`
+
+	// JournalID is the undo journal entry recorded before the command started
+	JournalID string `json:"journalId,omitempty"`
+
+	// Stash is the commit of the changes --autostash stashed, reapplied once
+	// the operation is continued or aborted
+	Stash string `json:"stash,omitempty"`
+
+	// Promotion is the rest of a promote stopped while cherry-picking
+	Promotion *Promotion `json:"promotion,omitempty"`
+}
+
+// Promotion is what gitext continue still runs for a promote once its
+// cherry-picks complete
+type Promotion struct {
+	Environment string `json:"environment"` // whose CI checks to run
+	NoCache     bool   `json:"noCache,omitempty"`
+	PRText      string `json:"prText"`
+}
+
+// Save writes the state to path (e.g., .git/gitext/operation.json)
+func Save(path string, state *State) error {
+	if state.StartedAt.IsZero() {
+		state.StartedAt = time.Now()
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write operation state: %w", err)
+	}
+	return nil
+}
+
+// Load reads the state at path, returning nil if no operation is in progress
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operation state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse operation state: %w", err)
+	}
+	return &state, nil
+}
+
+// Clear removes the state at path
+func Clear(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove operation state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package operation
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitext", "operation.json")
+
+	state, err := Load(path)
+	if err != nil || state != nil {
+		t.Fatalf("Load() before Save = %v, %v; want nil, nil", state, err)
+	}
+
+	saved := &State{
+		Command:   "gitext update feature --with=stage",
+		Operation: "rebase",
+		Branch:    "feature/KWS-1-login",
+		Target:    "origin/stage",
+		Remote:    "origin",
+		Summary:   "Rebased onto origin/stage",
+		ForcePush: true,
+		JournalID: "20240501-120000",
+	}
+	if err := Save(path, saved); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	state, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if state.Branch != saved.Branch || state.Target != saved.Target || !state.ForcePush || state.JournalID != saved.JournalID || state.StartedAt.IsZero() {
+		t.Errorf("state not round-tripped: %+v", state)
+	}
+
+	if err := Clear(path); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if err := Clear(path); err != nil {
+		t.Fatalf("Clear() twice error = %v", err)
+	}
+	if state, _ := Load(path); state != nil {
+		t.Errorf("expected no state after Clear, got %+v", state)
+	}
+}
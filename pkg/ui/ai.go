@@ -10,10 +10,10 @@ type AIOutput struct {
 	*Output
 }
 
-// NewAIOutput creates a new AIOutput instance
-func NewAIOutput(verbose bool) *AIOutput {
+// NewAIOutput creates a new AIOutput instance writing to output
+func NewAIOutput(output *Output) *AIOutput {
 	return &AIOutput{
-		Output: NewOutput(verbose),
+		Output: output,
 	}
 }
 
@@ -25,9 +25,8 @@ func (o *AIOutput) GeneratingCommitMessage() {
 // CommitMessageGenerated displays the generated commit message
 func (o *AIOutput) CommitMessageGenerated(message string) {
 	o.Success("Generated commit message:")
-	fmt.Println()
-	fmt.Println("  " + message)
-	fmt.Println()
+	o.Print("\n  %s\n", strings.ReplaceAll(message, "\n", "\n  "))
+	o.Set("commitMessage", message)
 }
 
 // TestingConnection shows that we're testing the API connection
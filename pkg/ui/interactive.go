@@ -3,15 +3,27 @@ package ui
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"golang.org/x/term"
 )
 
+// promptOutput receives prompts; see SetPromptOutput
+var promptOutput io.Writer = os.Stdout
+
+// SetPromptOutput sets where prompts are printed, e.g. stderr while stdout
+// holds a JSON document, and returns the previous writer
+func SetPromptOutput(w io.Writer) io.Writer {
+	previous := promptOutput
+	promptOutput = w
+	return previous
+}
+
 // PromptInput prompts the user for input and returns the entered string
 func PromptInput(prompt string) (string, error) {
-	fmt.Print(prompt)
+	fmt.Fprint(promptOutput, prompt)
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
 	if err != nil {
@@ -22,7 +34,7 @@ func PromptInput(prompt string) (string, error) {
 
 // PromptPassword prompts the user for a password (hidden input)
 func PromptPassword(prompt string) (string, error) {
-	fmt.Print(prompt)
+	fmt.Fprint(promptOutput, prompt)
 	
 	// Read password with hidden input
 	fd := int(os.Stdin.Fd())
@@ -31,7 +43,7 @@ func PromptPassword(prompt string) (string, error) {
 		return "", err
 	}
 	
-	fmt.Println() // New line after hidden input
+	fmt.Fprintln(promptOutput) // New line after hidden input
 	return string(bytePassword), nil
 }
 
@@ -41,11 +53,11 @@ func PromptSelect(prompt string, options []string) (int, error) {
 		return -1, fmt.Errorf("no options provided")
 	}
 
-	fmt.Println(prompt)
+	fmt.Fprintln(promptOutput, prompt)
 	for i, option := range options {
-		fmt.Printf("  %d) %s\n", i+1, option)
+		fmt.Fprintf(promptOutput, "  %d) %s\n", i+1, option)
 	}
-	fmt.Print("Select (1-" + fmt.Sprintf("%d", len(options)) + "): ")
+	fmt.Fprint(promptOutput, "Select (1-"+fmt.Sprintf("%d", len(options))+"): ")
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -73,7 +85,7 @@ func PromptConfirm(prompt string, defaultValue bool) (bool, error) {
 		defaultText = "Y/n"
 	}
 
-	fmt.Printf("%s [%s]: ", prompt, defaultText)
+	fmt.Fprintf(promptOutput, "%s [%s]: ", prompt, defaultText)
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
 	if err != nil {
@@ -97,14 +109,14 @@ func PromptSelectWithDescriptions(prompt string, options []struct {
 		return -1, fmt.Errorf("no options provided")
 	}
 
-	fmt.Println(prompt)
+	fmt.Fprintln(promptOutput, prompt)
 	for i, option := range options {
-		fmt.Printf("  %d) %s\n", i+1, option.Label)
+		fmt.Fprintf(promptOutput, "  %d) %s\n", i+1, option.Label)
 		if option.Description != "" {
-			fmt.Printf("     %s\n", option.Description)
+			fmt.Fprintf(promptOutput, "     %s\n", option.Description)
 		}
 	}
-	fmt.Print("Select (1-" + fmt.Sprintf("%d", len(options)) + "): ")
+	fmt.Fprint(promptOutput, "Select (1-"+fmt.Sprintf("%d", len(options))+"): ")
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -1,64 +1,217 @@
 package ui
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 )
 
+// Format is the output format selected with --output
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat validates an --output value
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatText, "":
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("invalid output format '%s' (expected text or json)", s)
+}
+
+// Message is a progress message recorded in JSON mode
+type Message struct {
+	Level string `json:"level"`
+	Text  string `json:"text"`
+}
+
+// Document is the result document written in JSON mode
+type Document struct {
+	Command  string                 `json:"command"`
+	OK       bool                   `json:"ok"`
+	Result   map[string]interface{} `json:"result"`
+	Next     []string               `json:"next"`
+	Messages []Message              `json:"messages"`
+	Error    *ErrorDocument         `json:"error,omitempty"`
+}
+
+// ErrorDocument is the structured form of a command error
+type ErrorDocument struct {
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
 // Output provides consistent output formatting
 type Output struct {
 	verbose bool
+	format  Format
+
+	// log receives what commands print besides messages: git, CI steps and
+	// prompts. JSON mode keeps stdout for the document and uses stderr.
+	log io.Writer
+
+	// JSON mode collects everything and writes one document to doc on Flush
+	doc      io.Writer
+	messages []Message
+	next     []string
+	result   map[string]interface{}
 }
 
 // NewOutput creates a new Output instance
 func NewOutput(verbose bool) *Output {
-	return &Output{verbose: verbose}
+	return &Output{verbose: verbose, format: FormatText, log: os.Stdout}
+}
+
+// NewJSONOutput creates an Output that writes a single JSON document to doc
+func NewJSONOutput(verbose bool, doc io.Writer) *Output {
+	return &Output{
+		verbose:  verbose,
+		format:   FormatJSON,
+		log:      os.Stderr,
+		doc:      doc,
+		messages: []Message{},
+		next:     []string{},
+		result:   make(map[string]interface{}),
+	}
+}
+
+// Log returns where git, CI steps and prompts print: stdout in text mode,
+// stderr in JSON mode
+func (o *Output) Log() io.Writer {
+	return o.log
+}
+
+// IsJSON reports whether output is collected into a JSON document
+func (o *Output) IsJSON() bool {
+	return o.format == FormatJSON
+}
+
+// Set records a field of the command result; text output ignores it
+func (o *Output) Set(key string, value interface{}) {
+	if o.IsJSON() {
+		o.result[key] = value
+	}
+}
+
+// Flush writes the JSON document with the outcome of the command. It does
+// nothing in text mode.
+func (o *Output) Flush(command string, err error) error {
+	if !o.IsJSON() {
+		return nil
+	}
+
+	doc := Document{
+		Command:  command,
+		OK:       err == nil,
+		Result:   o.result,
+		Next:     o.next,
+		Messages: o.messages,
+	}
+	if err != nil {
+		doc.Error = &ErrorDocument{Message: err.Error()}
+		var errWithSuggestion *ErrorWithSuggestion
+		if errors.As(err, &errWithSuggestion) {
+			doc.Error.Message = errWithSuggestion.Message
+			doc.Error.Suggestion = errWithSuggestion.Suggestion
+		}
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	_, err = fmt.Fprintln(o.doc, string(data))
+	return err
+}
+
+// record stores a message in JSON mode and reports whether it did
+func (o *Output) record(level, format string, args ...interface{}) bool {
+	if !o.IsJSON() {
+		return false
+	}
+	o.messages = append(o.messages, Message{Level: level, Text: fmt.Sprintf(format, args...)})
+	return true
 }
 
 // Info prints an info message
 func (o *Output) Info(format string, args ...interface{}) {
+	if o.record("info", format, args...) {
+		return
+	}
 	fmt.Printf("ℹ  %s\n", fmt.Sprintf(format, args...))
 }
 
 // Success prints a success message
 func (o *Output) Success(format string, args ...interface{}) {
+	if o.record("success", format, args...) {
+		return
+	}
 	fmt.Printf("✓  %s\n", fmt.Sprintf(format, args...))
 }
 
 // Warning prints a warning message
 func (o *Output) Warning(format string, args ...interface{}) {
+	if o.record("warning", format, args...) {
+		return
+	}
 	fmt.Printf("⚠  %s\n", fmt.Sprintf(format, args...))
 }
 
 // Error prints an error message
 func (o *Output) Error(format string, args ...interface{}) {
+	if o.record("error", format, args...) {
+		return
+	}
 	fmt.Fprintf(os.Stderr, "✗  %s\n", fmt.Sprintf(format, args...))
 }
 
 // Doing prints what is about to be done
 func (o *Output) Doing(format string, args ...interface{}) {
+	if o.record("doing", format, args...) {
+		return
+	}
 	fmt.Printf("→  %s\n", fmt.Sprintf(format, args...))
 }
 
 // Did prints what was done
 func (o *Output) Did(format string, args ...interface{}) {
+	if o.record("did", format, args...) {
+		return
+	}
 	fmt.Printf("✓  %s\n", fmt.Sprintf(format, args...))
 }
 
 // Next prints the next recommended command
 func (o *Output) Next(format string, args ...interface{}) {
+	if o.IsJSON() {
+		o.next = append(o.next, fmt.Sprintf(format, args...))
+		return
+	}
 	fmt.Printf("→  Next: %s\n", fmt.Sprintf(format, args...))
 }
 
 // Verbose prints a message only if verbose mode is enabled
 func (o *Output) Verbose(format string, args ...interface{}) {
 	if o.verbose {
+		if o.record("verbose", format, args...) {
+			return
+		}
 		fmt.Printf("   %s\n", fmt.Sprintf(format, args...))
 	}
 }
 
 // Print prints a plain message
 func (o *Output) Print(format string, args ...interface{}) {
+	if o.record("text", format, args...) {
+		return
+	}
 	fmt.Printf(format+"\n", args...)
 }
-
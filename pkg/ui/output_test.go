@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestJSONOutputDocument(t *testing.T) {
+	var buf bytes.Buffer
+	o := NewJSONOutput(false, &buf)
+
+	o.Info("Current branch: %s", "feature/KWS-1-x")
+	o.Verbose("hidden without --verbose")
+	o.Next("gitext prepare pr --to %s", "stage")
+	o.Set("branch", "feature/KWS-1-x")
+
+	err := fmt.Errorf("prepare failed: %w", NewError("working tree has uncommitted changes", "commit or stash changes first"))
+	if flushErr := o.Flush("gitext prepare pr", err); flushErr != nil {
+		t.Fatalf("Flush() error = %v", flushErr)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if doc.Command != "gitext prepare pr" || doc.OK {
+		t.Errorf("unexpected command/ok: %+v", doc)
+	}
+	if doc.Result["branch"] != "feature/KWS-1-x" {
+		t.Errorf("unexpected result: %v", doc.Result)
+	}
+	if len(doc.Next) != 1 || doc.Next[0] != "gitext prepare pr --to stage" {
+		t.Errorf("unexpected next: %v", doc.Next)
+	}
+	if len(doc.Messages) != 1 || doc.Messages[0].Level != "info" {
+		t.Errorf("unexpected messages: %v", doc.Messages)
+	}
+	if doc.Error == nil || doc.Error.Message != "working tree has uncommitted changes" || doc.Error.Suggestion != "commit or stash changes first" {
+		t.Errorf("unexpected error: %+v", doc.Error)
+	}
+}
+
+func TestTextOutputFlushIsNoop(t *testing.T) {
+	o := NewOutput(false)
+	o.Set("branch", "x")
+	if err := o.Flush("gitext status", nil); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{"": FormatText, "text": FormatText, "json": FormatJSON} {
+		if got, err := ParseFormat(input); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}